@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE companies ADD COLUMN operation_status OPERATION_STATUS NOT NULL DEFAULT 'pending';
+
+UPDATE companies co SET operation_status = cc.operation_status
+FROM (
+  SELECT DISTINCT ON (company_id) company_id, operation_status 
+  FROM company_countries 
+  ORDER BY company_id, id
+) cc
+WHERE cc.company_id = co.id;
+
+CREATE TABLE company_status_transitions
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  from_status       OPERATION_STATUS  NOT NULL,
+  to_status         OPERATION_STATUS  NOT NULL,
+  reason            TEXT              NOT NULL DEFAULT '',
+  actor_id          BIGINT            NULL REFERENCES users(id),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_status_transitions_company_idx ON company_status_transitions(company_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS company_status_transitions_company_idx;
+DROP TABLE IF EXISTS company_status_transitions;
+
+ALTER TABLE companies DROP COLUMN IF EXISTS operation_status;
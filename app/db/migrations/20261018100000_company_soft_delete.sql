@@ -0,0 +1,31 @@
+-- +goose Up
+ALTER TABLE companies ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+
+DROP INDEX IF EXISTS companies_name_uniq_idx;
+DROP INDEX IF EXISTS companies_code_uniq_idx;
+DROP INDEX IF EXISTS companies_website_uniq_idx;
+DROP INDEX IF EXISTS companies_number_uniq_idx;
+
+CREATE UNIQUE INDEX companies_name_uniq_idx ON companies(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX companies_code_uniq_idx ON companies(code) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX companies_website_uniq_idx ON companies(website) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX companies_number_uniq_idx ON companies(country_code, number) WHERE deleted_at IS NULL;
+
+CREATE INDEX companies_deleted_at_idx ON companies(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS companies_deleted_at_idx;
+
+DELETE FROM companies WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS companies_name_uniq_idx;
+DROP INDEX IF EXISTS companies_code_uniq_idx;
+DROP INDEX IF EXISTS companies_website_uniq_idx;
+DROP INDEX IF EXISTS companies_number_uniq_idx;
+
+CREATE UNIQUE INDEX companies_name_uniq_idx ON companies(name);
+CREATE UNIQUE INDEX companies_code_uniq_idx ON companies(code);
+CREATE UNIQUE INDEX companies_website_uniq_idx ON companies(website);
+CREATE UNIQUE INDEX companies_number_uniq_idx ON companies(country_code, number);
+
+ALTER TABLE companies DROP COLUMN IF EXISTS deleted_at;
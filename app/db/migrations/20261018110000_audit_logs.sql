@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE audit_logs
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  action            VARCHAR(20)       NOT NULL,
+  resource_type     VARCHAR(50)       NOT NULL,
+  resource_id       BIGINT            NOT NULL,
+  actor_id          BIGINT            NULL,
+  request_id        VARCHAR(255)      NOT NULL DEFAULT '',
+  ip_address        VARCHAR(255)      NOT NULL DEFAULT '',
+  user_agent        VARCHAR(255)      NOT NULL DEFAULT '',
+  changes           JSONB             NOT NULL DEFAULT '{}',
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX audit_logs_resource_idx ON audit_logs(resource_type, resource_id);
+CREATE INDEX audit_logs_actor_idx ON audit_logs(actor_id) WHERE actor_id IS NOT NULL;
+CREATE INDEX audit_logs_created_at_idx ON audit_logs(created_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_logs_append_only_trg BEFORE UPDATE OR DELETE ON audit_logs
+  FOR EACH ROW EXECUTE PROCEDURE audit_logs_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_logs_append_only_trg ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+
+DROP INDEX IF EXISTS audit_logs_created_at_idx;
+DROP INDEX IF EXISTS audit_logs_actor_idx;
+DROP INDEX IF EXISTS audit_logs_resource_idx;
+DROP TABLE IF EXISTS audit_logs;
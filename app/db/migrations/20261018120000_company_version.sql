@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE companies ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE companies DROP COLUMN IF EXISTS version;
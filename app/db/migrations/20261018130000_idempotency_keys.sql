@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE idempotency_keys
+(
+  id                      BIGSERIAL         PRIMARY KEY,
+  idempotency_key         VARCHAR(255)      NOT NULL,
+  user_id                 BIGINT            NOT NULL DEFAULT 0,
+  method                  VARCHAR(10)       NOT NULL,
+  path                    TEXT              NOT NULL,
+  request_hash            VARCHAR(64)       NOT NULL,
+  response_status         INTEGER           NULL,
+  response_content_type   VARCHAR(255)      NOT NULL DEFAULT '',
+  response_body           BYTEA             NULL,
+  expires_at              TIMESTAMPTZ       NOT NULL,
+  updated_at              TIMESTAMP         NOT NULL DEFAULT clock_timestamp(),
+  created_at              TIMESTAMP         NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE UNIQUE INDEX idempotency_keys_user_key_uniq_idx ON idempotency_keys(user_id, idempotency_key);
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+DROP INDEX IF EXISTS idempotency_keys_user_key_uniq_idx;
+DROP TABLE IF EXISTS idempotency_keys;
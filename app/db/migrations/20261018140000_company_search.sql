@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE companies ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+  setweight(to_tsvector('simple', coalesce(code, '')), 'A') ||
+  setweight(to_tsvector('simple', coalesce(website, '')), 'B')
+) STORED;
+
+CREATE INDEX companies_search_vector_idx ON companies USING GIN (search_vector);
+CREATE INDEX companies_name_trgm_idx ON companies USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX companies_code_trgm_idx ON companies USING GIN (LOWER(code) gin_trgm_ops);
+CREATE INDEX companies_website_trgm_idx ON companies USING GIN (LOWER(website) gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS companies_website_trgm_idx;
+DROP INDEX IF EXISTS companies_code_trgm_idx;
+DROP INDEX IF EXISTS companies_name_trgm_idx;
+DROP INDEX IF EXISTS companies_search_vector_idx;
+
+ALTER TABLE companies DROP COLUMN IF EXISTS search_vector;
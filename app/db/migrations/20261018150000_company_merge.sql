@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION normalize_company_name(name TEXT) RETURNS TEXT AS $$
+  SELECT btrim(regexp_replace(
+    regexp_replace(
+      regexp_replace(lower(name), '[^[:alnum:]]+', ' ', 'g'),
+      '(^| )(the|ltd|limited|llc|inc|incorporated|corp|corporation|co|company|plc|gmbh|sa|ag|bv)(?= |$)', ' ', 'g'
+    ),
+    ' +', ' ', 'g'
+  ))
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION company_domain(website TEXT) RETURNS TEXT AS $$
+  SELECT regexp_replace(
+    regexp_replace(lower(btrim(website)), '^[a-z][a-z0-9+.-]*://', ''),
+    '^(www\.)?([^/:?#]*).*$', '\2'
+  )
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- company_phone reduces a phone number to the digits of its E.164 form, so
+-- that numbers stored with different formatting still match.
+-- +goose StatementBegin
+CREATE FUNCTION company_phone(country_code TEXT, number TEXT) RETURNS TEXT AS $$
+  SELECT regexp_replace(coalesce(country_code, '') || coalesce(number, ''), '[^0-9]', '', 'g')
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+CREATE INDEX companies_normalized_name_idx ON companies(normalize_company_name(name));
+CREATE INDEX companies_domain_idx ON companies(company_domain(website));
+CREATE INDEX companies_phone_idx ON companies(company_phone(country_code, number));
+
+CREATE TABLE company_redirects
+(
+  merged_company_id BIGINT            PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  actor_id          BIGINT            NULL REFERENCES users(id),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_redirects_company_idx ON company_redirects(company_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS company_redirects_company_idx;
+DROP TABLE IF EXISTS company_redirects;
+
+DROP INDEX IF EXISTS companies_phone_idx;
+DROP INDEX IF EXISTS companies_domain_idx;
+DROP INDEX IF EXISTS companies_normalized_name_idx;
+
+DROP FUNCTION IF EXISTS company_phone(TEXT, TEXT);
+DROP FUNCTION IF EXISTS company_domain(TEXT);
+DROP FUNCTION IF EXISTS normalize_company_name(TEXT);
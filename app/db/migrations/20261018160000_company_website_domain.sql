@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE companies ADD COLUMN website_domain VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Existing companies are given their domain by cmd/websitedomains, which
+-- derives it with utils.NormalizeWebsite like new companies. Live companies
+-- that already share a domain keep it on the oldest record only. The others
+-- are recorded here for manual resolution, and show up in duplicate detection
+-- so they can be merged.
+CREATE TABLE company_website_domain_conflicts
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  kept_company_id   BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  website_domain    VARCHAR(255)      NOT NULL,
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+DROP INDEX IF EXISTS companies_website_uniq_idx;
+
+CREATE UNIQUE INDEX companies_website_domain_uniq_idx ON companies(website_domain) WHERE deleted_at IS NULL AND website_domain <> '';
+
+-- +goose Down
+DROP INDEX IF EXISTS companies_website_domain_uniq_idx;
+
+DROP TABLE IF EXISTS company_website_domain_conflicts;
+
+CREATE UNIQUE INDEX companies_website_uniq_idx ON companies(website) WHERE deleted_at IS NULL;
+
+ALTER TABLE companies DROP COLUMN IF EXISTS website_domain;
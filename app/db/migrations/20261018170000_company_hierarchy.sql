@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE companies ADD COLUMN parent_company_id BIGINT NULL REFERENCES companies(id) ON DELETE SET NULL;
+ALTER TABLE companies ADD CONSTRAINT companies_parent_not_self_chk CHECK (parent_company_id <> id);
+
+CREATE INDEX companies_parent_company_idx ON companies(parent_company_id) WHERE parent_company_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS companies_parent_company_idx;
+
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS companies_parent_not_self_chk;
+ALTER TABLE companies DROP COLUMN IF EXISTS parent_company_id;
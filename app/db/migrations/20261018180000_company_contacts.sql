@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE company_contacts
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  name              VARCHAR(100)      NOT NULL,
+  role              VARCHAR(100)      NOT NULL DEFAULT '',
+  email             VARCHAR(255)      NULL,
+  country_code      VARCHAR(5)        NULL,
+  number            VARCHAR(20)       NULL,
+  is_primary        BOOLEAN           NOT NULL DEFAULT FALSE,
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_contacts_company_idx ON company_contacts(company_id);
+CREATE UNIQUE INDEX company_contacts_primary_uniq_idx ON company_contacts(company_id) WHERE is_primary;
+CREATE UNIQUE INDEX company_contacts_email_uniq_idx ON company_contacts(company_id, LOWER(email)) WHERE email IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS company_contacts_email_uniq_idx;
+DROP INDEX IF EXISTS company_contacts_primary_uniq_idx;
+DROP INDEX IF EXISTS company_contacts_company_idx;
+DROP TABLE IF EXISTS company_contacts;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE company_addresses
+(
+  id                   BIGSERIAL         PRIMARY KEY,
+  company_country_id   BIGINT            NOT NULL REFERENCES company_countries(id) ON DELETE CASCADE,
+  address_type         VARCHAR(20)       NOT NULL CHECK (address_type IN ('registered', 'operating', 'billing')),
+  line1                VARCHAR(255)      NOT NULL,
+  line2                VARCHAR(255)      NOT NULL DEFAULT '',
+  city                 VARCHAR(100)      NOT NULL,
+  region               VARCHAR(100)      NOT NULL DEFAULT '',
+  postal_code          VARCHAR(20)       NOT NULL DEFAULT '',
+  updated_at           TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at           TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_addresses_company_country_idx ON company_addresses(company_country_id);
+CREATE UNIQUE INDEX company_addresses_type_uniq_idx ON company_addresses(company_country_id, address_type) WHERE address_type <> 'operating';
+
+-- +goose Down
+DROP INDEX IF EXISTS company_addresses_type_uniq_idx;
+DROP INDEX IF EXISTS company_addresses_company_country_idx;
+DROP TABLE IF EXISTS company_addresses;
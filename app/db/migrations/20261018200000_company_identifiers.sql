@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE company_identifiers
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  identifier_type   VARCHAR(20)       NOT NULL CHECK (identifier_type IN ('lei', 'vat', 'registration')),
+  country_code      VARCHAR(2)        NOT NULL DEFAULT '',
+  value             VARCHAR(50)       NOT NULL,
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_identifiers_company_idx ON company_identifiers(company_id);
+CREATE UNIQUE INDEX company_identifiers_value_uniq_idx ON company_identifiers(identifier_type, country_code, value);
+CREATE UNIQUE INDEX company_identifiers_company_type_uniq_idx ON company_identifiers(company_id, identifier_type, country_code);
+
+-- +goose Down
+DROP INDEX IF EXISTS company_identifiers_company_type_uniq_idx;
+DROP INDEX IF EXISTS company_identifiers_value_uniq_idx;
+DROP INDEX IF EXISTS company_identifiers_company_idx;
+DROP TABLE IF EXISTS company_identifiers;
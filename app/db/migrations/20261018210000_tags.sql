@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE tags
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  name              VARCHAR(50)       NOT NULL,
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE UNIQUE INDEX tags_name_uniq_idx ON tags(LOWER(name));
+
+CREATE TABLE company_tags
+(
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  tag_id            BIGINT            NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  PRIMARY KEY (company_id, tag_id)
+);
+
+CREATE INDEX company_tags_tag_idx ON company_tags(tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS company_tags_tag_idx;
+DROP TABLE IF EXISTS company_tags;
+DROP INDEX IF EXISTS tags_name_uniq_idx;
+DROP TABLE IF EXISTS tags;
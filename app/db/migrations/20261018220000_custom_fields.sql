@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE custom_field_definitions
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  key               VARCHAR(50)       NOT NULL UNIQUE,
+  label             VARCHAR(100)      NOT NULL DEFAULT '',
+  field_type        VARCHAR(20)       NOT NULL CHECK (field_type IN ('string', 'number', 'boolean', 'date', 'enum')),
+  required          BOOLEAN           NOT NULL DEFAULT FALSE,
+  enum_values       TEXT[]            NOT NULL DEFAULT '{}',
+  pattern           VARCHAR(255)      NOT NULL DEFAULT '',
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+ALTER TABLE companies ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE companies DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS custom_field_definitions;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE company_documents
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  company_id        BIGINT            NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  file_name         VARCHAR(255)      NOT NULL,
+  content_type      VARCHAR(100)      NOT NULL,
+  size_bytes        BIGINT            NOT NULL CHECK (size_bytes >= 0),
+  sha256            CHAR(64)          NOT NULL,
+  storage_key       VARCHAR(255)      NOT NULL UNIQUE,
+  uploaded_by       BIGINT            NULL REFERENCES users(id) ON DELETE SET NULL,
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX company_documents_company_idx ON company_documents(company_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS company_documents_company_idx;
+DROP TABLE IF EXISTS company_documents;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TYPE USER_ROLE AS ENUM ('admin', 'editor', 'viewer');
+
+-- Existing users keep the unrestricted access they had before roles were
+-- introduced, users created from now on start with the least privileges.
+ALTER TABLE users ADD COLUMN role USER_ROLE NOT NULL DEFAULT 'admin';
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'viewer';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS USER_ROLE;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE access_policy_rules
+(
+  id                BIGSERIAL         PRIMARY KEY,
+  name              VARCHAR(100)      NOT NULL UNIQUE,
+  rule_type         VARCHAR(20)       NOT NULL CHECK (rule_type IN ('allow_only', 'deny', 'restrict', 'skip_authentication')),
+  countries         TEXT[]            NOT NULL DEFAULT '{}',
+  methods           TEXT[]            NOT NULL DEFAULT '{}',
+  routes            TEXT[]            NOT NULL DEFAULT '{}',
+  enabled           BOOLEAN           NOT NULL DEFAULT TRUE,
+  updated_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at        TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+-- Companies may be created from Cyprus without authenticating, as previously
+-- hardcoded in the authentication middleware. Its check for deletes never
+-- matched the delete route, so deletes still require authentication.
+INSERT INTO access_policy_rules (name, rule_type, countries, methods, routes)
+VALUES ('cyprus_unauthenticated_company_creation', 'skip_authentication', '{CY}', '{POST}', '{/v1/companies}');
+
+-- +goose Down
+DROP TABLE IF EXISTS access_policy_rules;
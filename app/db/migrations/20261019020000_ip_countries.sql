@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE ip_countries
+(
+  id                    BIGSERIAL         PRIMARY KEY,
+  ip_address            VARCHAR(45)       NOT NULL UNIQUE,
+  city                  VARCHAR(100)      NOT NULL DEFAULT '',
+  country_code          VARCHAR(5)        NOT NULL DEFAULT '',
+  country_name          VARCHAR(100)      NOT NULL DEFAULT '',
+  currency              VARCHAR(5)        NOT NULL DEFAULT '',
+  country_calling_code  VARCHAR(10)       NOT NULL DEFAULT '',
+  expires_at            TIMESTAMPTZ       NOT NULL,
+  updated_at            TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp(),
+  created_at            TIMESTAMPTZ       NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX ip_countries_expires_at_idx ON ip_countries(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS ip_countries_expires_at_idx;
+DROP TABLE IF EXISTS ip_countries;
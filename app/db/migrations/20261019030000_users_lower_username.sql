@@ -0,0 +1,23 @@
+-- +goose Up
+-- Usernames are unique regardless of case. Users whose usernames differ only
+-- by case must be renamed before migrating.
+-- +goose StatementBegin
+DO $$
+DECLARE
+  duplicates TEXT;
+BEGIN
+  SELECT string_agg(DISTINCT lower(username), ', ') INTO duplicates
+  FROM users
+  WHERE lower(username) IN (SELECT lower(username) FROM users GROUP BY lower(username) HAVING count(*) > 1);
+
+  IF duplicates IS NOT NULL THEN
+    RAISE EXCEPTION 'usernames differing only by case must be renamed first: %', duplicates;
+  END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX users_lower_username_uniq_idx ON users(lower(username));
+
+-- +goose Down
+DROP INDEX IF EXISTS users_lower_username_uniq_idx;
@@ -0,0 +1,166 @@
+package entities
+
+import (
+	"strings"
+
+	"syreclabs.com/go/faker"
+)
+
+type (
+	AccessPolicyMode     string
+	AccessPolicyRuleType string
+)
+
+const (
+	AccessPolicyModeDryRun  AccessPolicyMode = "dry_run"
+	AccessPolicyModeEnforce AccessPolicyMode = "enforce"
+)
+
+const (
+	// AccessPolicyRuleTypeAllowOnly limits requests from Countries to the
+	// matching methods and routes.
+	AccessPolicyRuleTypeAllowOnly AccessPolicyRuleType = "allow_only"
+	// AccessPolicyRuleTypeDeny rejects matching requests from Countries.
+	AccessPolicyRuleTypeDeny AccessPolicyRuleType = "deny"
+	// AccessPolicyRuleTypeRestrict only accepts matching requests from
+	// Countries.
+	AccessPolicyRuleTypeRestrict AccessPolicyRuleType = "restrict"
+	// AccessPolicyRuleTypeSkipAuthentication lets matching requests from
+	// Countries through without a session token.
+	AccessPolicyRuleTypeSkipAuthentication AccessPolicyRuleType = "skip_authentication"
+)
+
+// AccessPolicyRule decides which requests are accepted based on the country
+// they come from. Countries are ISO 3166-1 alpha-2 codes and Routes are gin
+// route templates such as /v1/companies/:id. Empty Methods or Routes match
+// every method or route.
+type AccessPolicyRule struct {
+	SequentialIdentifier
+	Name      string               `json:"name"`
+	RuleType  AccessPolicyRuleType `json:"rule_type"`
+	Countries []string             `json:"countries"`
+	Methods   []string             `json:"methods"`
+	Routes    []string             `json:"routes"`
+	Enabled   bool                 `json:"enabled"`
+	Timestamps
+}
+
+// AccessRequest is what access policy rules are evaluated against. Country
+// is empty when the location of the request is unknown.
+type AccessRequest struct {
+	Country string
+	Method  string
+	Route   string
+}
+
+type AccessDecision struct {
+	Allowed            bool
+	Country            string
+	Rule               *AccessPolicyRule
+	SkipAuthentication bool
+}
+
+func BuildAccessPolicyRule() *AccessPolicyRule {
+	return &AccessPolicyRule{
+		Name:      "rule_" + strings.ToLower(faker.RandomString(10)),
+		RuleType:  AccessPolicyRuleTypeDeny,
+		Countries: []string{faker.Address().CountryCode()},
+		Methods:   []string{},
+		Routes:    []string{},
+		Enabled:   true,
+	}
+}
+
+// Denies reports whether the rule rejects request.
+func (r *AccessPolicyRule) Denies(request *AccessRequest) bool {
+
+	switch r.RuleType {
+	case AccessPolicyRuleTypeAllowOnly:
+		return r.MatchesCountry(request.Country) && !r.MatchesRoute(request.Method, request.Route)
+	case AccessPolicyRuleTypeDeny:
+		return r.MatchesCountry(request.Country) && r.MatchesRoute(request.Method, request.Route)
+	case AccessPolicyRuleTypeRestrict:
+		return r.MatchesRoute(request.Method, request.Route) && !r.MatchesCountry(request.Country)
+	}
+
+	return false
+}
+
+// SkipsAuthentication reports whether the rule lets request through without
+// a session token.
+func (r *AccessPolicyRule) SkipsAuthentication(request *AccessRequest) bool {
+	return r.RuleType == AccessPolicyRuleTypeSkipAuthentication &&
+		r.MatchesCountry(request.Country) &&
+		r.MatchesRoute(request.Method, request.Route)
+}
+
+func (r *AccessPolicyRule) MatchesCountry(country string) bool {
+
+	if country == "" {
+		return false
+	}
+
+	for _, ruleCountry := range r.Countries {
+		if strings.EqualFold(ruleCountry, country) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *AccessPolicyRule) MatchesRoute(method, route string) bool {
+	return r.matchesMethod(method) && r.matchesRoute(route)
+}
+
+// RequiresCountry reports whether the country of a request to method and
+// route is needed to evaluate the rule.
+func (r *AccessPolicyRule) RequiresCountry(method, route string) bool {
+	return r.RuleType == AccessPolicyRuleTypeAllowOnly || r.MatchesRoute(method, route)
+}
+
+func (r *AccessPolicyRule) matchesMethod(method string) bool {
+
+	if len(r.Methods) == 0 {
+		return true
+	}
+
+	for _, ruleMethod := range r.Methods {
+		if strings.EqualFold(ruleMethod, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *AccessPolicyRule) matchesRoute(route string) bool {
+
+	if len(r.Routes) == 0 {
+		return true
+	}
+
+	for _, ruleRoute := range r.Routes {
+		if ruleRoute == route {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m AccessPolicyMode) IsValid() bool {
+	switch m {
+	case AccessPolicyModeDryRun, AccessPolicyModeEnforce:
+		return true
+	}
+	return false
+}
+
+func (t AccessPolicyRuleType) IsValid() bool {
+	switch t {
+	case AccessPolicyRuleTypeAllowOnly, AccessPolicyRuleTypeDeny, AccessPolicyRuleTypeRestrict, AccessPolicyRuleTypeSkipAuthentication:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package entities
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAccessPolicyRule(t *testing.T) {
+
+	Convey("AccessPolicyRule", t, func() {
+
+		Convey("deny rules reject matching requests from their countries", func() {
+
+			rule := &AccessPolicyRule{
+				Name:      "deny_kenya_deletes",
+				RuleType:  AccessPolicyRuleTypeDeny,
+				Countries: []string{"KE"},
+				Methods:   []string{http.MethodDelete},
+			}
+
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodDelete, Route: "/v1/companies/:id"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "ke", Method: http.MethodDelete, Route: "/v1/tags/:id"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodGet, Route: "/v1/companies/:id"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Country: "CY", Method: http.MethodDelete, Route: "/v1/companies/:id"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Method: http.MethodDelete, Route: "/v1/companies/:id"}), ShouldBeFalse)
+		})
+
+		Convey("allow only rules limit their countries to the matching routes", func() {
+
+			rule := &AccessPolicyRule{
+				Name:      "kenya_reads_companies",
+				RuleType:  AccessPolicyRuleTypeAllowOnly,
+				Countries: []string{"KE"},
+				Methods:   []string{http.MethodGet},
+				Routes:    []string{"/v1/companies", "/v1/companies/:id"},
+			}
+
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodGet, Route: "/v1/companies/:id"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodGet, Route: "/v1/tags"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "CY", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+		})
+
+		Convey("restrict rules only accept matching requests from their countries", func() {
+
+			rule := &AccessPolicyRule{
+				Name:      "cyprus_company_writes",
+				RuleType:  AccessPolicyRuleTypeRestrict,
+				Countries: []string{"CY"},
+				Methods:   []string{http.MethodPost, http.MethodDelete},
+				Routes:    []string{"/v1/companies", "/v1/companies/:id"},
+			}
+
+			So(rule.Denies(&AccessRequest{Country: "CY", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodDelete, Route: "/v1/companies/:id"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodPut, Route: "/v1/companies/:id"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodPost, Route: "/v1/companies/:id/tags"}), ShouldBeFalse)
+		})
+
+		Convey("skip authentication rules never deny requests", func() {
+
+			rule := &AccessPolicyRule{
+				Name:      "cyprus_unauthenticated_company_creation",
+				RuleType:  AccessPolicyRuleTypeSkipAuthentication,
+				Countries: []string{"CY"},
+				Methods:   []string{http.MethodPost},
+				Routes:    []string{"/v1/companies"},
+			}
+
+			So(rule.SkipsAuthentication(&AccessRequest{Country: "CY", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeTrue)
+			So(rule.SkipsAuthentication(&AccessRequest{Country: "KE", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+			So(rule.SkipsAuthentication(&AccessRequest{Country: "CY", Method: http.MethodDelete, Route: "/v1/companies/:id"}), ShouldBeFalse)
+			So(rule.SkipsAuthentication(&AccessRequest{Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Country: "KE", Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+			So(rule.Denies(&AccessRequest{Method: http.MethodPost, Route: "/v1/companies"}), ShouldBeFalse)
+		})
+
+		Convey("routes are matched as templates rather than paths", func() {
+
+			rule := &AccessPolicyRule{
+				Name:     "deny_company_reads",
+				RuleType: AccessPolicyRuleTypeRestrict,
+				Routes:   []string{"/v1/companies/:id"},
+			}
+
+			So(rule.Denies(&AccessRequest{Country: "CY", Method: http.MethodGet, Route: "/v1/companies/:id"}), ShouldBeTrue)
+			So(rule.Denies(&AccessRequest{Country: "CY", Method: http.MethodGet, Route: "/v1/companies/42"}), ShouldBeFalse)
+		})
+
+		Convey("only rules that depend on the location require the country", func() {
+
+			restrict := &AccessPolicyRule{
+				RuleType: AccessPolicyRuleTypeRestrict,
+				Routes:   []string{"/v1/companies"},
+			}
+
+			So(restrict.RequiresCountry(http.MethodPost, "/v1/companies"), ShouldBeTrue)
+			So(restrict.RequiresCountry(http.MethodGet, "/v1/tags"), ShouldBeFalse)
+
+			deny := &AccessPolicyRule{
+				RuleType: AccessPolicyRuleTypeDeny,
+				Routes:   []string{"/v1/companies"},
+			}
+
+			So(deny.RequiresCountry(http.MethodGet, "/v1/companies"), ShouldBeTrue)
+			So(deny.RequiresCountry(http.MethodGet, "/v1/tags"), ShouldBeFalse)
+
+			allowOnly := &AccessPolicyRule{
+				RuleType: AccessPolicyRuleTypeAllowOnly,
+				Routes:   []string{"/v1/companies"},
+			}
+
+			So(allowOnly.RequiresCountry(http.MethodGet, "/v1/tags"), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,21 @@
+package entities
+
+type AddressType string
+
+const (
+	AddressTypeRegistered AddressType = "registered"
+	AddressTypeOperating  AddressType = "operating"
+	AddressTypeBilling    AddressType = "billing"
+)
+
+func (s AddressType) IsValid() bool {
+	switch s {
+	case AddressTypeRegistered, AddressTypeOperating, AddressTypeBilling:
+		return true
+	}
+	return false
+}
+
+func (s AddressType) String() string {
+	return string(s)
+}
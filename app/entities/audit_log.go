@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionMerge        AuditAction = "merge"
+	AuditActionRestore      AuditAction = "restore"
+	AuditActionStatusChange AuditAction = "status_change"
+	AuditActionUpdate       AuditAction = "update"
+)
+
+const AuditResourceCompany = "company"
+
+type AuditLog struct {
+	SequentialIdentifier
+	Action       AuditAction             `json:"action"`
+	ActorID      null.Int                `json:"actor_id"`
+	Changes      map[string]*FieldChange `json:"changes"`
+	IPAddress    string                  `json:"ip_address"`
+	RequestID    string                  `json:"request_id"`
+	ResourceID   int64                   `json:"resource_id"`
+	ResourceType string                  `json:"resource_type"`
+	UserAgent    string                  `json:"user_agent"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+type AuditLogList struct {
+	AuditLogs  []*AuditLog `json:"audit_logs"`
+	Pagination *Pagination `json:"pagination"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionDelete, AuditActionMerge, AuditActionRestore, AuditActionStatusChange, AuditActionUpdate:
+		return true
+	}
+	return false
+}
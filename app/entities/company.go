@@ -1,16 +1,28 @@
 package entities
 
-import "syreclabs.com/go/faker"
+import (
+	"gopkg.in/guregu/null.v3"
+	"syreclabs.com/go/faker"
+)
 
 type Company struct {
 	SequentialIdentifier
-	Name            string              `json:"name"`
-	Code            string              `json:"code"`
-	Country         string              `json:"country"`
-	Website         string              `json:"website"`
-	Phone           string              `json:"phone"`
-	PhoneNumber     PhoneNumber         `json:"phone_number"`
-	OperationStatus OperationStatusType `json:"operation_status"`
+	Name            string                     `json:"name"`
+	Code            string                     `json:"code"`
+	Country         string                     `json:"country"`
+	Website         string                     `json:"website"`
+	WebsiteDomain   string                     `json:"website_domain"`
+	Phone           string                     `json:"phone"`
+	PhoneNumber     PhoneNumber                `json:"phone_number"`
+	OperationStatus OperationStatusType        `json:"operation_status"`
+	ParentCompanyID null.Int                   `json:"parent_company_id"`
+	CustomFields    map[string]interface{}     `json:"custom_fields"`
+	Countries       []*CompanyCountry          `json:"countries"`
+	Addresses       []*CompanyAddress          `json:"addresses,omitempty"`
+	Tags            []*Tag                     `json:"tags,omitempty"`
+	StatusHistory   []*CompanyStatusTransition `json:"status_history,omitempty"`
+	DeletedAt       null.Time                  `json:"deleted_at"`
+	Version         int64                      `json:"version"`
 	Timestamps
 }
 
@@ -24,11 +36,12 @@ func BuildCompany(companyName string, country *Country) *Company {
 	phoneNumber := fakePhoneNumber()
 
 	return &Company{
-		Name:        companyName,
-		Code:        faker.RandomString(10),
-		Country:     country.Name,
-		Website:     faker.Internet().Url(),
-		Phone:       phoneNumber.Phone(),
-		PhoneNumber: phoneNumber,
+		Name:            companyName,
+		Code:            faker.RandomString(10),
+		Country:         country.Name,
+		Website:         faker.Internet().Url(),
+		Phone:           phoneNumber.Phone(),
+		PhoneNumber:     phoneNumber,
+		OperationStatus: OperationStatusTypeActive,
 	}
 }
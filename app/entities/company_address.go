@@ -0,0 +1,28 @@
+package entities
+
+import "syreclabs.com/go/faker"
+
+type CompanyAddress struct {
+	SequentialIdentifier
+	CompanyID        int64       `json:"company_id"`
+	CompanyCountryID int64       `json:"company_country_id"`
+	CountryCode      string      `json:"country_code"`
+	CountryName      string      `json:"country_name"`
+	AddressType      AddressType `json:"address_type"`
+	Line1            string      `json:"line1"`
+	Line2            string      `json:"line2"`
+	City             string      `json:"city"`
+	Region           string      `json:"region"`
+	PostalCode       string      `json:"postal_code"`
+	Timestamps
+}
+
+func BuildCompanyAddress(companyCountryID int64, addressType AddressType) *CompanyAddress {
+	return &CompanyAddress{
+		CompanyCountryID: companyCountryID,
+		AddressType:      addressType,
+		Line1:            faker.Address().StreetAddress(),
+		City:             faker.Address().City(),
+		PostalCode:       faker.Numerify("####"),
+	}
+}
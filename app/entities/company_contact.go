@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"gopkg.in/guregu/null.v3"
+	"syreclabs.com/go/faker"
+)
+
+type CompanyContact struct {
+	SequentialIdentifier
+	CompanyID   int64       `json:"company_id"`
+	Name        string      `json:"name"`
+	Role        string      `json:"role"`
+	Email       null.String `json:"email"`
+	Phone       string      `json:"phone"`
+	PhoneNumber PhoneNumber `json:"phone_number"`
+	IsPrimary   bool        `json:"is_primary"`
+	Timestamps
+}
+
+func BuildCompanyContact(companyID int64) *CompanyContact {
+
+	phoneNumber := fakePhoneNumber()
+
+	return &CompanyContact{
+		CompanyID:   companyID,
+		Name:        faker.Name().Name(),
+		Role:        faker.Name().Title(),
+		Email:       null.StringFrom(faker.Internet().Email()),
+		Phone:       phoneNumber.Phone(),
+		PhoneNumber: phoneNumber,
+	}
+}
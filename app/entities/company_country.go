@@ -4,6 +4,8 @@ type CompanyCountry struct {
 	SequentialIdentifier
 	CompanyID       int64               `json:"company_id"`
 	CountryID       int64               `json:"country_id"`
+	CountryCode     string              `json:"country_code"`
+	CountryName     string              `json:"country_name"`
 	OperationStatus OperationStatusType `json:"operation_status"`
 	Timestamps
 }
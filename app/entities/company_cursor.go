@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// CompanyCursor marks the position of the last company returned in a keyset
+// paginated listing: the values of its sort fields followed by its id, and the
+// sort the cursor was issued for.
+type CompanyCursor struct {
+	ID     int64    `json:"i"`
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func DecodeCompanyCursor(encoded string) (*CompanyCursor, error) {
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return &CompanyCursor{}, err
+	}
+
+	var cursor CompanyCursor
+
+	err = json.Unmarshal(data, &cursor)
+	if err != nil {
+		return &CompanyCursor{}, err
+	}
+
+	if cursor.ID < 1 {
+		return &CompanyCursor{}, errors.New("cursor id must be positive")
+	}
+
+	return &cursor, nil
+}
+
+func (c *CompanyCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
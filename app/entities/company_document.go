@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CompanyDocument describes a file attached to a company. The contents live
+// in the blob store under StorageKey; SHA256 is the hex encoded checksum of
+// the contents as uploaded.
+type CompanyDocument struct {
+	SequentialIdentifier
+	CompanyID   int64    `json:"company_id"`
+	FileName    string   `json:"file_name"`
+	ContentType string   `json:"content_type"`
+	SizeBytes   int64    `json:"size_bytes"`
+	SHA256      string   `json:"sha256"`
+	StorageKey  string   `json:"-"`
+	UploadedBy  null.Int `json:"uploaded_by"`
+	Timestamps
+}
+
+func BuildCompanyDocument(companyID int64) *CompanyDocument {
+	return &CompanyDocument{
+		CompanyID:   companyID,
+		FileName:    "certificate.pdf",
+		ContentType: "application/pdf",
+		SizeBytes:   0,
+		SHA256:      "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		StorageKey:  "documents/" + uuid.NewString(),
+	}
+}
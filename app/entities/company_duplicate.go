@@ -0,0 +1,12 @@
+package entities
+
+const (
+	DuplicateMatchDomain = "domain"
+	DuplicateMatchName   = "name"
+	DuplicateMatchPhone  = "phone"
+)
+
+type CompanyDuplicate struct {
+	Company   *Company `json:"company"`
+	MatchedOn []string `json:"matched_on"`
+}
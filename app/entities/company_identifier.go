@@ -0,0 +1,23 @@
+package entities
+
+import "syreclabs.com/go/faker"
+
+// CompanyIdentifier is a legal identifier issued to a company. CountryCode is
+// the issuing country and is empty for global identifiers such as an LEI.
+type CompanyIdentifier struct {
+	SequentialIdentifier
+	CompanyID      int64          `json:"company_id"`
+	IdentifierType IdentifierType `json:"identifier_type"`
+	CountryCode    string         `json:"country_code"`
+	Value          string         `json:"value"`
+	Timestamps
+}
+
+func BuildCompanyIdentifier(companyID int64) *CompanyIdentifier {
+	return &CompanyIdentifier{
+		CompanyID:      companyID,
+		IdentifierType: IdentifierTypeRegistration,
+		CountryCode:    "CY",
+		Value:          faker.Numerify("HE######"),
+	}
+}
@@ -0,0 +1,47 @@
+package entities
+
+type CompanyImportMode string
+
+const (
+	CompanyImportModeAllOrNothing CompanyImportMode = "all_or_nothing"
+	CompanyImportModeBestEffort   CompanyImportMode = "best_effort"
+)
+
+func (m CompanyImportMode) IsValid() bool {
+	switch m {
+	case CompanyImportModeAllOrNothing, CompanyImportModeBestEffort:
+		return true
+	}
+	return false
+}
+
+func (m CompanyImportMode) String() string {
+	return string(m)
+}
+
+type CompanyImportRowStatus string
+
+const (
+	CompanyImportRowStatusCreated CompanyImportRowStatus = "created"
+	CompanyImportRowStatusFailed  CompanyImportRowStatus = "failed"
+	CompanyImportRowStatusSkipped CompanyImportRowStatus = "skipped"
+	CompanyImportRowStatusValid   CompanyImportRowStatus = "valid"
+)
+
+type CompanyImportRow struct {
+	Row          int                    `json:"row"`
+	Status       CompanyImportRowStatus `json:"status"`
+	Code         string                 `json:"code"`
+	Company      *Company               `json:"company,omitempty"`
+	ErrorCode    string                 `json:"error_code,omitempty"`
+	ErrorMessage string                 `json:"error_message,omitempty"`
+}
+
+type CompanyImportReport struct {
+	DryRun  bool                `json:"dry_run"`
+	Mode    CompanyImportMode   `json:"mode"`
+	Total   int                 `json:"total"`
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Rows    []*CompanyImportRow `json:"rows"`
+}
@@ -0,0 +1,9 @@
+package entities
+
+// CompanyNode is a company in a group hierarchy together with its
+// subsidiaries. Depth counts the levels below the top of the group.
+type CompanyNode struct {
+	*Company
+	Depth        int            `json:"depth"`
+	Subsidiaries []*CompanyNode `json:"subsidiaries"`
+}
@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// CompanyRedirect points the id of a company that was merged away at the
+// company it was merged into.
+type CompanyRedirect struct {
+	ActorID         null.Int  `json:"actor_id"`
+	CompanyID       int64     `json:"company_id"`
+	MergedCompanyID int64     `json:"merged_company_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
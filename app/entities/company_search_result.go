@@ -0,0 +1,12 @@
+package entities
+
+type CompanySearchResult struct {
+	Company    *Company          `json:"company"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type CompanySearchResultList struct {
+	Results    []*CompanySearchResult `json:"results"`
+	Pagination *Pagination            `json:"pagination"`
+}
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+type CompanyStatusTransition struct {
+	SequentialIdentifier
+	ActorID    null.Int            `json:"actor_id"`
+	CompanyID  int64               `json:"company_id"`
+	FromStatus OperationStatusType `json:"from_status"`
+	Reason     string              `json:"reason"`
+	ToStatus   OperationStatusType `json:"to_status"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
@@ -3,8 +3,9 @@ package entities
 type ContextKey string
 
 const (
-	ContextKeyIpAddress ContextKey = "ipAddress"
-	ContextKeyRequestID ContextKey = "requestId"
-	ContextKeyTokenInfo ContextKey = "tokenInfo"
-	ContextKeyUserAgent ContextKey = "userAgent"
+	ContextKeyIpAddress          ContextKey = "ipAddress"
+	ContextKeyRequestID          ContextKey = "requestId"
+	ContextKeySkipAuthentication ContextKey = "skipAuthentication"
+	ContextKeyTokenInfo          ContextKey = "tokenInfo"
+	ContextKeyUserAgent          ContextKey = "userAgent"
 )
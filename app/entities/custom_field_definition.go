@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"strings"
+
+	"syreclabs.com/go/faker"
+)
+
+// CustomFieldDefinition describes an attribute companies can carry in their
+// custom fields without a schema change. EnumValues only applies to enum
+// fields and Pattern, a regular expression, only to string fields.
+type CustomFieldDefinition struct {
+	SequentialIdentifier
+	Key        string          `json:"key"`
+	Label      string          `json:"label"`
+	FieldType  CustomFieldType `json:"field_type"`
+	Required   bool            `json:"required"`
+	EnumValues []string        `json:"enum_values"`
+	Pattern    string          `json:"pattern"`
+	Timestamps
+}
+
+// JSONSchema describes the values accepted for the field as a JSON Schema.
+func (d *CustomFieldDefinition) JSONSchema() map[string]interface{} {
+
+	schema := map[string]interface{}{
+		"title": d.Label,
+	}
+
+	switch d.FieldType {
+	case CustomFieldTypeNumber:
+		schema["type"] = "number"
+	case CustomFieldTypeBoolean:
+		schema["type"] = "boolean"
+	case CustomFieldTypeDate:
+		schema["type"] = "string"
+		schema["format"] = "date"
+	case CustomFieldTypeEnum:
+		schema["type"] = "string"
+		schema["enum"] = d.EnumValues
+	default:
+		schema["type"] = "string"
+		if d.Pattern != "" {
+			schema["pattern"] = d.Pattern
+		}
+	}
+
+	return schema
+}
+
+func BuildCustomFieldDefinition() *CustomFieldDefinition {
+
+	key := strings.ToLower(faker.Lorem().Characters(12))
+
+	return &CustomFieldDefinition{
+		Key:        "cf_" + key,
+		Label:      key,
+		FieldType:  CustomFieldTypeString,
+		EnumValues: []string{},
+	}
+}
@@ -0,0 +1,23 @@
+package entities
+
+type CustomFieldType string
+
+const (
+	CustomFieldTypeString  CustomFieldType = "string"
+	CustomFieldTypeNumber  CustomFieldType = "number"
+	CustomFieldTypeBoolean CustomFieldType = "boolean"
+	CustomFieldTypeDate    CustomFieldType = "date"
+	CustomFieldTypeEnum    CustomFieldType = "enum"
+)
+
+func (s CustomFieldType) IsValid() bool {
+	switch s {
+	case CustomFieldTypeString, CustomFieldTypeNumber, CustomFieldTypeBoolean, CustomFieldTypeDate, CustomFieldTypeEnum:
+		return true
+	}
+	return false
+}
+
+func (s CustomFieldType) String() string {
+	return string(s)
+}
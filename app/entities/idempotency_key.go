@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+type IdempotencyKey struct {
+	SequentialIdentifier
+	ExpiresAt           time.Time `json:"expires_at"`
+	Key                 string    `json:"key"`
+	Method              string    `json:"method"`
+	Path                string    `json:"path"`
+	RequestHash         string    `json:"request_hash"`
+	ResponseBody        []byte    `json:"-"`
+	ResponseContentType string    `json:"response_content_type"`
+	ResponseStatus      null.Int  `json:"response_status"`
+	UserID              int64     `json:"user_id"`
+	Timestamps
+}
+
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.ResponseStatus.Valid
+}
@@ -0,0 +1,21 @@
+package entities
+
+type IdentifierType string
+
+const (
+	IdentifierTypeLEI          IdentifierType = "lei"
+	IdentifierTypeVAT          IdentifierType = "vat"
+	IdentifierTypeRegistration IdentifierType = "registration"
+)
+
+func (s IdentifierType) IsValid() bool {
+	switch s {
+	case IdentifierTypeLEI, IdentifierTypeVAT, IdentifierTypeRegistration:
+		return true
+	}
+	return false
+}
+
+func (s IdentifierType) String() string {
+	return string(s)
+}
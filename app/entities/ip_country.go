@@ -0,0 +1,16 @@
+package entities
+
+import "time"
+
+// IPCountry is a cached geolocation of an IP address.
+type IPCountry struct {
+	SequentialIdentifier
+	City               string    `json:"city"`
+	CountryCallingCode string    `json:"country_calling_code"`
+	CountryCode        string    `json:"country_code"`
+	CountryName        string    `json:"country_name"`
+	Currency           string    `json:"currency"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	IPAddress          string    `json:"ip_address"`
+	Timestamps
+}
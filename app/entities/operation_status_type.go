@@ -7,3 +7,30 @@ const (
 	OperationStatusTypeActive  OperationStatusType = "active"
 	OperationStatusTypeClosed  OperationStatusType = "closed"
 )
+
+var operationStatusTransitions = map[OperationStatusType][]OperationStatusType{
+	OperationStatusTypePending: {OperationStatusTypeActive},
+	OperationStatusTypeActive:  {OperationStatusTypeClosed},
+	OperationStatusTypeClosed:  {OperationStatusTypeActive},
+}
+
+func (s OperationStatusType) CanTransitionTo(to OperationStatusType) bool {
+	for _, allowed := range operationStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OperationStatusType) IsValid() bool {
+	switch s {
+	case OperationStatusTypePending, OperationStatusTypeActive, OperationStatusTypeClosed:
+		return true
+	}
+	return false
+}
+
+func (s OperationStatusType) String() string {
+	return string(s)
+}
@@ -5,12 +5,13 @@ import (
 )
 
 type Pagination struct {
-	Count    int      `json:"count"`
-	NextPage null.Int `json:"next_page"`
-	NumPages int      `json:"num_pages"`
-	Page     int      `json:"page"`
-	Per      int      `json:"per"`
-	PrevPage null.Int `json:"prev_page"`
+	Count      int         `json:"count"`
+	NextCursor null.String `json:"next_cursor"`
+	NextPage   null.Int    `json:"next_page"`
+	NumPages   int         `json:"num_pages"`
+	Page       int         `json:"page"`
+	Per        int         `json:"per"`
+	PrevPage   null.Int    `json:"prev_page"`
 }
 
 func NewPagination(
@@ -49,3 +50,41 @@ func NewPagination(
 		PrevPage: prevPage,
 	}
 }
+
+// NewCursorPagination describes a keyset paginated page. Count is only set
+// when the caller asked for it, as it requires a separate query.
+func NewCursorPagination(
+	count,
+	per int,
+	nextCursor null.String,
+) *Pagination {
+	return &Pagination{
+		Count:      count,
+		NextCursor: nextCursor,
+		Per:        per,
+	}
+}
+
+// NewUncountedPagination describes an offset paginated page whose total count
+// was not queried; hasMore tells whether a following page exists.
+func NewUncountedPagination(
+	page,
+	per int,
+	hasMore bool,
+) *Pagination {
+
+	pagination := &Pagination{
+		Page: page,
+		Per:  per,
+	}
+
+	if page > 1 {
+		pagination.PrevPage = null.IntFrom(int64(page - 1))
+	}
+
+	if hasMore {
+		pagination.NextPage = null.IntFrom(int64(page + 1))
+	}
+
+	return pagination
+}
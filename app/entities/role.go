@@ -0,0 +1,83 @@
+package entities
+
+type (
+	Role       string
+	Permission string
+)
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+const (
+	PermissionAuditRead          Permission = "audit:read"
+	PermissionCompaniesDelete    Permission = "companies:delete"
+	PermissionCompaniesWrite     Permission = "companies:write"
+	PermissionCustomFieldsManage Permission = "custom_fields:manage"
+	PermissionMetricsRead        Permission = "metrics:read"
+	PermissionUsersManage        Permission = "users:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionAuditRead,
+		PermissionCompaniesDelete,
+		PermissionCompaniesWrite,
+		PermissionCustomFieldsManage,
+		PermissionMetricsRead,
+		PermissionUsersManage,
+	},
+	RoleEditor: {
+		PermissionCompaniesWrite,
+	},
+	RoleViewer: {},
+}
+
+// RoleWithPermissions describes what a role grants.
+type RoleWithPermissions struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Roles lists every role along with its permissions, from the most to the
+// least privileged.
+func Roles() []*RoleWithPermissions {
+
+	roles := make([]*RoleWithPermissions, 0, len(rolePermissions))
+
+	for _, role := range []Role{RoleAdmin, RoleEditor, RoleViewer} {
+		roles = append(roles, &RoleWithPermissions{
+			Role:        role,
+			Permissions: role.Permissions(),
+		})
+	}
+
+	return roles
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+func (r Role) Permissions() []Permission {
+	return append([]Permission{}, rolePermissions[r]...)
+}
+
+func (r Role) String() string {
+	return string(r)
+}
@@ -0,0 +1,19 @@
+package entities
+
+import "syreclabs.com/go/faker"
+
+// Tag labels companies, e.g. by industry or risk tier. UsageCount is the
+// number of live companies carrying the tag and is only set when tags are
+// listed.
+type Tag struct {
+	SequentialIdentifier
+	Name       string `json:"name"`
+	UsageCount int64  `json:"usage_count"`
+	Timestamps
+}
+
+func BuildTag() *Tag {
+	return &Tag{
+		Name: faker.Lorem().Characters(12),
+	}
+}
@@ -7,11 +7,16 @@ import (
 type TokenInfo struct {
 	Exp       time.Time
 	Refresh   time.Time
+	Role      Role
 	SessionID int64
 	Status    string
 	UserID    int64
 }
 
+func (ti *TokenInfo) HasPermission(permission Permission) bool {
+	return ti.Role.HasPermission(permission)
+}
+
 func (ti *TokenInfo) RequiresRefresh() bool {
 	return time.Now().After(ti.Refresh)
 }
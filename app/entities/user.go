@@ -8,15 +8,22 @@ type User struct {
 	LastName     string     `json:"last_name"`
 	Username     string     `json:"useranme"`
 	PasswordHash string     `json:"-"`
+	Role         Role       `json:"role"`
 	Status       UserStatus `json:"status"`
 	Timestamps
 }
 
+type UserList struct {
+	Users      []*User     `json:"users"`
+	Pagination *Pagination `json:"pagination"`
+}
+
 func BuildUser() *User {
 	return &User{
 		FirstName: faker.Name().FirstName(),
 		LastName:  faker.Name().LastName(),
 		Username:  faker.RandomString(10),
+		Role:      RoleAdmin,
 		Status:    UserStatusActive,
 	}
 }
@@ -8,6 +8,28 @@ const (
 	UserStatusUnverified  UserStatus = "unverified"
 )
 
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	UserStatusUnverified: {UserStatusActive, UserStatusDeactivated},
+	UserStatusActive:     {UserStatusDeactivated},
+}
+
+func (s UserStatus) CanTransitionTo(to UserStatus) bool {
+	for _, allowed := range userStatusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s UserStatus) IsValid() bool {
+	switch s {
+	case UserStatusActive, UserStatusDeactivated, UserStatusUnverified:
+		return true
+	}
+	return false
+}
+
 func (s UserStatus) String() string {
 	return string(s)
 }
@@ -1,18 +1,44 @@
 package forms
 
-import "gopkg.in/guregu/null.v3"
+import (
+	"github.com/vonmutinda/organono/app/entities"
+	"gopkg.in/guregu/null.v3"
+)
 
 type CreateCompanyForm struct {
-	Name    string `json:"name" binding:"required"`
-	Code    string `json:"code" binding:"required"`
-	Country string `json:"country" binding:"required"`
-	Website string `json:"website" bidning:"required"`
-	Phone   string `json:"phone" binding:"required"`
+	Name            string                 `json:"name" binding:"required"`
+	Code            string                 `json:"code" binding:"required"`
+	Country         string                 `json:"country" binding:"required"`
+	Website         string                 `json:"website" binding:"required"`
+	Phone           string                 `json:"phone" binding:"required"`
+	ParentCompanyID null.Int               `json:"parent_company_id"`
+	CustomFields    map[string]interface{} `json:"custom_fields"`
 }
 
+// UpdateCompanyForm merges CustomFields into the company's existing custom
+// fields, a null value removing the field.
 type UpdateCompanyForm struct {
-	Name    null.String `json:"name" binding:"required"`
-	Code    null.String `json:"code" binding:"required"`
-	Website null.String `json:"website" bidning:"required"`
-	Phone   null.String `json:"phone" binding:"required"`
+	Name         null.String            `json:"name" binding:"required"`
+	Code         null.String            `json:"code" binding:"required"`
+	Website      null.String            `json:"website" binding:"required"`
+	Phone        null.String            `json:"phone" binding:"required"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+	Version      null.Int               `json:"version"`
+}
+
+type CompanyStatusForm struct {
+	Reason  string `json:"reason"`
+	Cascade bool   `json:"cascade"`
+}
+
+// CompanyParentForm moves a company under a new parent, or makes it a top
+// level company when ParentCompanyID is null.
+type CompanyParentForm struct {
+	ParentCompanyID null.Int `json:"parent_company_id"`
+}
+
+type CompanyImportForm struct {
+	Companies []*CreateCompanyForm
+	DryRun    bool
+	Mode      entities.CompanyImportMode
 }
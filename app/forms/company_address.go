@@ -0,0 +1,21 @@
+package forms
+
+import "gopkg.in/guregu/null.v3"
+
+type CreateCompanyAddressForm struct {
+	AddressType string `json:"address_type" binding:"required"`
+	Line1       string `json:"line1" binding:"required"`
+	Line2       string `json:"line2"`
+	City        string `json:"city" binding:"required"`
+	Region      string `json:"region"`
+	PostalCode  string `json:"postal_code"`
+}
+
+type UpdateCompanyAddressForm struct {
+	AddressType null.String `json:"address_type"`
+	Line1       null.String `json:"line1"`
+	Line2       null.String `json:"line2"`
+	City        null.String `json:"city"`
+	Region      null.String `json:"region"`
+	PostalCode  null.String `json:"postal_code"`
+}
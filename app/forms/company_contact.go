@@ -0,0 +1,21 @@
+package forms
+
+import "gopkg.in/guregu/null.v3"
+
+type CreateCompanyContactForm struct {
+	Name      string `json:"name" binding:"required"`
+	Role      string `json:"role"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+	IsPrimary bool   `json:"is_primary"`
+}
+
+// UpdateCompanyContactForm changes the provided fields only. An empty email
+// or phone removes it from the contact.
+type UpdateCompanyContactForm struct {
+	Name      null.String `json:"name"`
+	Role      null.String `json:"role"`
+	Email     null.String `json:"email"`
+	Phone     null.String `json:"phone"`
+	IsPrimary null.Bool   `json:"is_primary"`
+}
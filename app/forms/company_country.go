@@ -0,0 +1,12 @@
+package forms
+
+import "gopkg.in/guregu/null.v3"
+
+type CreateCompanyCountryForm struct {
+	Country         string      `json:"country" binding:"required"`
+	OperationStatus null.String `json:"operation_status"`
+}
+
+type UpdateCompanyCountryForm struct {
+	OperationStatus string `json:"operation_status" binding:"required"`
+}
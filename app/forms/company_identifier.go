@@ -0,0 +1,10 @@
+package forms
+
+// CreateCompanyIdentifierForm adds a legal identifier to a company. Country
+// is the 2 letter code of the issuing registry and is only required for
+// registration numbers.
+type CreateCompanyIdentifierForm struct {
+	IdentifierType string `json:"identifier_type" binding:"required"`
+	Value          string `json:"value" binding:"required"`
+	Country        string `json:"country"`
+}
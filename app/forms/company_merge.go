@@ -0,0 +1,5 @@
+package forms
+
+type MergeCompanyForm struct {
+	MergedCompanyID int64 `json:"merged_company_id" binding:"required"`
+}
@@ -0,0 +1,25 @@
+package forms
+
+import (
+	"github.com/vonmutinda/organono/app/entities"
+	"gopkg.in/guregu/null.v3"
+)
+
+type CreateCustomFieldDefinitionForm struct {
+	Key        string                   `json:"key" binding:"required"`
+	Label      string                   `json:"label"`
+	FieldType  entities.CustomFieldType `json:"field_type" binding:"required"`
+	Required   bool                     `json:"required"`
+	EnumValues []string                 `json:"enum_values"`
+	Pattern    string                   `json:"pattern"`
+}
+
+// UpdateCustomFieldDefinitionForm leaves the key and type of a definition
+// alone since stored values depend on them. EnumValues is only replaced when
+// provided.
+type UpdateCustomFieldDefinitionForm struct {
+	Label      null.String `json:"label"`
+	Required   null.Bool   `json:"required"`
+	EnumValues []string    `json:"enum_values"`
+	Pattern    null.String `json:"pattern"`
+}
@@ -1,14 +1,148 @@
 package forms
 
+import (
+	"strings"
+
+	"github.com/vonmutinda/organono/app/entities"
+	"gopkg.in/guregu/null.v3"
+)
+
+// CompanySortFields whitelists the fields companies can be sorted by.
+var CompanySortFields = map[string]bool{
+	"code":       true,
+	"country":    true,
+	"created_at": true,
+	"name":       true,
+	"updated_at": true,
+}
+
+var defaultCompanySort = []SortField{{Field: "name"}}
+
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
 type Filter struct {
+	Page             int
+	Per              int
+	Term             string
+	Status           string
+	IncludeDeleted   bool
+	Code             string
+	Country          string
+	CreatedAfter     null.Time
+	CreatedBefore    null.Time
+	UpdatedAfter     null.Time
+	PhoneCountryCode string
+	ParentID         int64
+	IdentifierType   entities.IdentifierType
+	Identifier       string
+	// Tags holds lower cased tag names. Companies match when they carry any
+	// of them, or all of them when MatchAllTags is set.
+	Tags         []string
+	MatchAllTags bool
+	// CustomFields matches companies whose custom field, keyed by definition
+	// key, holds the value in its text form.
+	CustomFields map[string]string
+	Sort         []SortField
+	// Cursor switches listing to keyset pagination, returning at most Per
+	// rows after After.
+	Cursor    bool
+	After     *entities.CompanyCursor
+	SkipCount bool
+}
+
+func (f *Filter) NoPagination() *Filter {
+	return &Filter{
+		Term:             f.Term,
+		Status:           f.Status,
+		IncludeDeleted:   f.IncludeDeleted,
+		Code:             f.Code,
+		Country:          f.Country,
+		CreatedAfter:     f.CreatedAfter,
+		CreatedBefore:    f.CreatedBefore,
+		UpdatedAfter:     f.UpdatedAfter,
+		PhoneCountryCode: f.PhoneCountryCode,
+		ParentID:         f.ParentID,
+		IdentifierType:   f.IdentifierType,
+		Identifier:       f.Identifier,
+		Tags:             f.Tags,
+		MatchAllTags:     f.MatchAllTags,
+		CustomFields:     f.CustomFields,
+		Sort:             f.Sort,
+	}
+}
+
+// HasLookahead reports whether listings fetch one row past the page to tell
+// if another page follows, instead of relying on a count.
+func (f *Filter) HasLookahead() bool {
+	return f.Cursor || f.SkipCount
+}
+
+// SortFields returns the requested sort, defaulting to name ascending.
+func (f *Filter) SortFields() []SortField {
+	if len(f.Sort) == 0 {
+		return defaultCompanySort
+	}
+	return f.Sort
+}
+
+// SortKey renders the sort in its query string form, e.g. name,-created_at.
+func (f *Filter) SortKey() string {
+
+	keys := make([]string, 0, len(f.SortFields()))
+
+	for _, sortField := range f.SortFields() {
+		if sortField.Descending {
+			keys = append(keys, "-"+sortField.Field)
+		} else {
+			keys = append(keys, sortField.Field)
+		}
+	}
+
+	return strings.Join(keys, ",")
+}
+
+type SearchFilter struct {
+	Page  int
+	Per   int
+	Query string
+}
+
+type AuditFilter struct {
+	Page         int
+	Per          int
+	Action       string
+	ActorID      int64
+	ResourceID   int64
+	ResourceType string
+	From         null.Time
+	To           null.Time
+}
+
+func (f *AuditFilter) NoPagination() *AuditFilter {
+	return &AuditFilter{
+		Action:       f.Action,
+		ActorID:      f.ActorID,
+		ResourceID:   f.ResourceID,
+		ResourceType: f.ResourceType,
+		From:         f.From,
+		To:           f.To,
+	}
+}
+
+type UserFilter struct {
 	Page   int
 	Per    int
+	Role   entities.Role
 	Term   string
-	Status string
+	Status entities.UserStatus
 }
 
-func (f *Filter) NoPagination() *Filter {
-	return &Filter{
+func (f *UserFilter) NoPagination() *UserFilter {
+	return &UserFilter{
+		Role:   f.Role,
 		Term:   f.Term,
 		Status: f.Status,
 	}
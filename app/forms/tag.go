@@ -0,0 +1,9 @@
+package forms
+
+type CreateTagForm struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type CompanyTagForm struct {
+	TagID int64 `json:"tag_id" binding:"required"`
+}
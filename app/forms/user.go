@@ -0,0 +1,21 @@
+package forms
+
+import "gopkg.in/guregu/null.v3"
+
+type AssignRoleForm struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type CreateUserForm struct {
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+	Role      string `json:"role"`
+}
+
+type UpdateUserForm struct {
+	FirstName null.String `json:"first_name"`
+	LastName  null.String `json:"last_name"`
+	Status    null.String `json:"status"`
+}
@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultBlobStorePath = "storage/blobs"
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+type (
+	// BlobStore keeps opaque file contents addressed by key. Keys are
+	// slash separated paths chosen by the caller.
+	BlobStore interface {
+		Delete(ctx context.Context, key string) error
+		Get(ctx context.Context, key string) (io.ReadCloser, error)
+		Put(ctx context.Context, key string, reader io.Reader) error
+	}
+
+	AppLocalBlobStore struct {
+		root string
+	}
+)
+
+// NewBlobStore returns the blob store configured through BLOB_STORE_PATH,
+// storing files on the local filesystem.
+func NewBlobStore() *AppLocalBlobStore {
+
+	root := os.Getenv("BLOB_STORE_PATH")
+	if root == "" {
+		root = defaultBlobStorePath
+	}
+
+	return NewLocalBlobStore(root)
+}
+
+func NewLocalBlobStore(root string) *AppLocalBlobStore {
+	return &AppLocalBlobStore{
+		root: root,
+	}
+}
+
+func (p *AppLocalBlobStore) Delete(ctx context.Context, key string) error {
+
+	path, err := p.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove blob key = %v err = %v", key, err)
+	}
+
+	return nil
+}
+
+func (p *AppLocalBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	path, err := p.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to open blob key = %v err = %v", key, err)
+	}
+
+	return file, nil
+}
+
+// Put writes to a temporary file next to the destination and renames it into
+// place, so readers never see a partially written blob.
+func (p *AppLocalBlobStore) Put(ctx context.Context, key string, reader io.Reader) error {
+
+	path, err := p.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("failed to create blob directory key = %v err = %v", key, err)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file key = %v err = %v", key, err)
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write blob key = %v err = %v", key, err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close blob key = %v err = %v", key, err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to store blob key = %v err = %v", key, err)
+	}
+
+	return nil
+}
+
+// path maps key below the store root, rejecting keys that would escape it.
+func (p *AppLocalBlobStore) path(key string) (string, error) {
+
+	cleanKey := filepath.Clean("/" + filepath.FromSlash(key))
+	if key == "" || cleanKey == string(filepath.Separator) || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid blob key = [%v]", key)
+	}
+
+	return filepath.Join(p.root, cleanKey), nil
+}
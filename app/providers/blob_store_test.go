@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+
+	ctx := context.Background()
+
+	Convey("Local Blob Store", t, func() {
+
+		blobStore := NewLocalBlobStore(t.TempDir())
+
+		Convey("can put, get and delete a blob", func() {
+
+			err := blobStore.Put(ctx, "companies/1/documents/a", strings.NewReader("certificate"))
+			So(err, ShouldBeNil)
+
+			contents, err := blobStore.Get(ctx, "companies/1/documents/a")
+			So(err, ShouldBeNil)
+
+			data, err := ioutil.ReadAll(contents)
+			So(err, ShouldBeNil)
+			So(contents.Close(), ShouldBeNil)
+			So(string(data), ShouldEqual, "certificate")
+
+			err = blobStore.Delete(ctx, "companies/1/documents/a")
+			So(err, ShouldBeNil)
+
+			_, err = blobStore.Get(ctx, "companies/1/documents/a")
+			So(err, ShouldEqual, ErrBlobNotFound)
+		})
+
+		Convey("deleting a missing blob is a no-op", func() {
+
+			err := blobStore.Delete(ctx, "companies/1/documents/missing")
+			So(err, ShouldBeNil)
+		})
+
+		Convey("rejects keys escaping the store root", func() {
+
+			err := blobStore.Put(ctx, "../outside", strings.NewReader("certificate"))
+			So(err, ShouldNotBeNil)
+
+			_, err = blobStore.Get(ctx, "")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
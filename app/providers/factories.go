@@ -0,0 +1,193 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+const (
+	geoIPDataSectionSeparatorSize = 16
+	geoIPMetadataStartMarker      = "\xAB\xCD\xEFMaxMind.com"
+)
+
+const (
+	geoIPTypeString = 2
+	geoIPTypeUint16 = 5
+	geoIPTypeUint32 = 6
+	geoIPTypeMap    = 7
+	geoIPTypeUint64 = 9
+	geoIPTypeArray  = 11
+)
+
+type geoIPTreeRecord struct {
+	isNode bool
+	isData bool
+	value  int
+}
+
+// CreateGeoIPDatabase writes an IPv4 MaxMind DB file to path with a record
+// for each of the networks, given in CIDR notation, that must not overlap.
+// It lets geoip lookups be tested without a downloaded database.
+func CreateGeoIPDatabase(path string, networks map[string]*CountryWithIP) error {
+
+	nodes := [][2]geoIPTreeRecord{{}}
+	data := &bytes.Buffer{}
+	dataOffsets := make([]int, 0, len(networks))
+
+	for cidr, country := range networks {
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+
+		ip := network.IP.To4()
+		if ip == nil {
+			return fmt.Errorf("network = %v is not an ipv4 network", cidr)
+		}
+
+		prefixLength, _ := network.Mask.Size()
+		if prefixLength == 0 {
+			return fmt.Errorf("network = %v must have a prefix", cidr)
+		}
+
+		dataOffsets = append(dataOffsets, data.Len())
+		writeGeoIPValue(data, map[string]interface{}{
+			"city": map[string]interface{}{
+				"names": map[string]interface{}{"en": country.City},
+			},
+			"country": map[string]interface{}{
+				"iso_code": country.CountryCode,
+				"names":    map[string]interface{}{"en": country.CountryName},
+			},
+		})
+
+		node := 0
+
+		for bit := 0; bit < prefixLength; bit++ {
+
+			side := int(ip[bit/8]>>(7-uint(bit%8))) & 1
+
+			if bit == prefixLength-1 {
+				nodes[node][side] = geoIPTreeRecord{isData: true, value: len(dataOffsets) - 1}
+				break
+			}
+
+			if !nodes[node][side].isNode {
+				nodes = append(nodes, [2]geoIPTreeRecord{})
+				nodes[node][side] = geoIPTreeRecord{isNode: true, value: len(nodes) - 1}
+			}
+
+			node = nodes[node][side].value
+		}
+	}
+
+	database := &bytes.Buffer{}
+	nodeCount := len(nodes)
+
+	for _, node := range nodes {
+		for _, record := range node {
+
+			value := nodeCount
+
+			if record.isNode {
+				value = record.value
+			}
+
+			if record.isData {
+				value = nodeCount + geoIPDataSectionSeparatorSize + dataOffsets[record.value]
+			}
+
+			database.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+
+	database.Write(make([]byte, geoIPDataSectionSeparatorSize))
+	database.Write(data.Bytes())
+	database.WriteString(geoIPMetadataStartMarker)
+
+	writeGeoIPValue(database, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(time.Now().Unix()),
+		"database_type":               "GeoLite2-Country",
+		"description":                 map[string]interface{}{"en": "Test database"},
+		"ip_version":                  uint16(4),
+		"languages":                   []string{"en"},
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(24),
+	})
+
+	return ioutil.WriteFile(path, database.Bytes(), 0o600)
+}
+
+func writeGeoIPValue(buffer *bytes.Buffer, value interface{}) {
+
+	switch value := value.(type) {
+	case string:
+		writeGeoIPControl(buffer, geoIPTypeString, len(value))
+		buffer.WriteString(value)
+	case uint16:
+		writeGeoIPUint(buffer, geoIPTypeUint16, uint64(value))
+	case uint32:
+		writeGeoIPUint(buffer, geoIPTypeUint32, uint64(value))
+	case uint64:
+		writeGeoIPUint(buffer, geoIPTypeUint64, value)
+	case []string:
+		writeGeoIPControl(buffer, geoIPTypeArray, len(value))
+		for _, item := range value {
+			writeGeoIPValue(buffer, item)
+		}
+	case map[string]interface{}:
+		writeGeoIPControl(buffer, geoIPTypeMap, len(value))
+		for key, item := range value {
+			writeGeoIPValue(buffer, key)
+			writeGeoIPValue(buffer, item)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported geoip value type %T", value))
+	}
+}
+
+func writeGeoIPUint(buffer *bytes.Buffer, dataType int, value uint64) {
+
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, value)
+	encoded = bytes.TrimLeft(encoded, "\x00")
+
+	writeGeoIPControl(buffer, dataType, len(encoded))
+	buffer.Write(encoded)
+}
+
+// writeGeoIPControl writes the control byte of a value of dataType holding
+// size bytes or items. Sizes above 284 are not needed for test databases.
+func writeGeoIPControl(buffer *bytes.Buffer, dataType, size int) {
+
+	if size > 284 {
+		panic(fmt.Sprintf("unsupported geoip value size %v", size))
+	}
+
+	extended := dataType > geoIPTypeMap
+	control := byte(dataType << 5)
+	if extended {
+		control = 0
+	}
+
+	if size < 29 {
+		buffer.WriteByte(control | byte(size))
+	} else {
+		buffer.WriteByte(control | 29)
+	}
+
+	if extended {
+		buffer.WriteByte(byte(dataType - geoIPTypeMap))
+	}
+
+	if size >= 29 {
+		buffer.WriteByte(byte(size - 29))
+	}
+}
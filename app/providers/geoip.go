@@ -0,0 +1,178 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/vonmutinda/organono/app/logger"
+)
+
+// defaultGeoIPReloadInterval is how often the database file is checked for
+// changes.
+const defaultGeoIPReloadInterval = 30 * time.Second
+
+type (
+	// AppGeoIPDatabase looks up countries in a local MaxMind DB file, such as
+	// GeoLite2-Country.mmdb, so lookups need no network access. The file is
+	// reloaded when it changes.
+	AppGeoIPDatabase struct {
+		path           string
+		reloadInterval time.Duration
+
+		mu            sync.RWMutex
+		reader        *maxminddb.Reader
+		modTime       time.Time
+		size          int64
+		lastCheckedAt time.Time
+	}
+
+	geoIPNames struct {
+		Names map[string]string `maxminddb:"names"`
+	}
+
+	geoIPCountry struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	}
+
+	geoIPRecord struct {
+		City              geoIPNames   `maxminddb:"city"`
+		Country           geoIPCountry `maxminddb:"country"`
+		RegisteredCountry geoIPCountry `maxminddb:"registered_country"`
+	}
+)
+
+func NewGeoIPDatabase(path string) (*AppGeoIPDatabase, error) {
+	return NewGeoIPDatabaseWithReloadInterval(path, defaultGeoIPReloadInterval)
+}
+
+func NewGeoIPDatabaseWithReloadInterval(
+	path string,
+	reloadInterval time.Duration,
+) (*AppGeoIPDatabase, error) {
+
+	database := &AppGeoIPDatabase{
+		path:           path,
+		reloadInterval: reloadInterval,
+	}
+
+	err := database.load()
+	if err != nil {
+		return &AppGeoIPDatabase{}, err
+	}
+
+	return database, nil
+}
+
+// CountryForIP returns an empty country, like ipapi.co does for reserved
+// addresses, when the database has no record for ipAddress.
+func (p *AppGeoIPDatabase) CountryForIP(ctx context.Context, ipAddress string) (*CountryWithIP, error) {
+
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return &CountryWithIP{}, fmt.Errorf("invalid ip address = [%v]", ipAddress)
+	}
+
+	p.reloadIfChanged()
+
+	var record geoIPRecord
+
+	p.mu.RLock()
+	err := p.reader.Lookup(ip, &record)
+	p.mu.RUnlock()
+	if err != nil {
+		return &CountryWithIP{}, fmt.Errorf("failed to look up ip address = %v err = %v", ipAddress, err)
+	}
+
+	country := record.Country
+	if country.ISOCode == "" {
+		country = record.RegisteredCountry
+	}
+
+	countryWithIP := &CountryWithIP{
+		IP:          ipAddress,
+		City:        record.City.Names["en"],
+		CountryCode: country.ISOCode,
+		CountryName: country.Names["en"],
+	}
+
+	return countryWithIP, nil
+}
+
+// load reads the whole file into memory rather than mapping it, so that the
+// file can be overwritten in place while it is being read.
+func (p *AppGeoIPDatabase) load() error {
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat geoip database path = %v err = %v", p.path, err)
+	}
+
+	data, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read geoip database path = %v err = %v", p.path, err)
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to open geoip database path = %v err = %v", p.path, err)
+	}
+
+	p.mu.Lock()
+	p.reader = reader
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	p.mu.Unlock()
+
+	return nil
+}
+
+// reloadIfChanged reloads the database at most once per reload interval when
+// the file's modification time or size has changed. A file that fails to load,
+// for example while it is still being written, is retried on the next check
+// and the previous database is kept in the meantime. Lookups only take the
+// write lock when a check is due.
+func (p *AppGeoIPDatabase) reloadIfChanged() {
+
+	p.mu.RLock()
+	checkDue := time.Since(p.lastCheckedAt) >= p.reloadInterval
+	p.mu.RUnlock()
+	if !checkDue {
+		return
+	}
+
+	// Another lookup may have taken the check since the read lock was
+	// released.
+	p.mu.Lock()
+	if time.Since(p.lastCheckedAt) < p.reloadInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastCheckedAt = time.Now()
+	modTime, size := p.modTime, p.size
+	p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		logger.Warnf("Failed to check geoip database path = %v err = %v", p.path, err)
+		return
+	}
+
+	if info.ModTime().Equal(modTime) && info.Size() == size {
+		return
+	}
+
+	err = p.load()
+	if err != nil {
+		logger.Errorf("Failed to reload geoip database, keeping the loaded one err = %v", err)
+		return
+	}
+
+	logger.Infof("Reloaded geoip database path = %v", p.path)
+}
@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGeoIPDatabase(t *testing.T) {
+
+	ctx := context.Background()
+
+	Convey("GeoIP Database", t, func() {
+
+		path := filepath.Join(t.TempDir(), "countries.mmdb")
+
+		err := CreateGeoIPDatabase(path, map[string]*CountryWithIP{
+			"176.56.168.0/21":  {City: "Nicosia", CountryCode: "CY", CountryName: "Cyprus"},
+			"197.156.112.0/20": {City: "Nairobi", CountryCode: "KE", CountryName: "Kenya"},
+		})
+		So(err, ShouldBeNil)
+
+		geoIPDatabase, err := NewGeoIPDatabaseWithReloadInterval(path, 0)
+		So(err, ShouldBeNil)
+
+		Convey("finds the country of an ip address", func() {
+
+			countryWithIP, err := geoIPDatabase.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(countryWithIP.IP, ShouldEqual, "176.56.168.100")
+			So(countryWithIP.City, ShouldEqual, "Nicosia")
+			So(countryWithIP.CountryCode, ShouldEqual, "CY")
+			So(countryWithIP.CountryName, ShouldEqual, "Cyprus")
+
+			countryWithIP, err = geoIPDatabase.CountryForIP(ctx, "197.156.118.251")
+			So(err, ShouldBeNil)
+			So(countryWithIP.CountryCode, ShouldEqual, "KE")
+		})
+
+		Convey("returns no country for an unknown ip address", func() {
+
+			countryWithIP, err := geoIPDatabase.CountryForIP(ctx, "10.0.0.1")
+			So(err, ShouldBeNil)
+			So(countryWithIP.CountryCode, ShouldEqual, "")
+		})
+
+		Convey("rejects an invalid ip address", func() {
+
+			_, err := geoIPDatabase.CountryForIP(ctx, "not-an-ip")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("reloads the database when the file changes", func() {
+
+			err := CreateGeoIPDatabase(path, map[string]*CountryWithIP{
+				"176.56.168.0/21": {CountryCode: "GR", CountryName: "Greece"},
+			})
+			So(err, ShouldBeNil)
+
+			modTime := time.Now().Add(time.Minute)
+			So(os.Chtimes(path, modTime, modTime), ShouldBeNil)
+
+			countryWithIP, err := geoIPDatabase.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(countryWithIP.CountryCode, ShouldEqual, "GR")
+		})
+
+		Convey("keeps the loaded database when the file becomes invalid", func() {
+
+			err := ioutil.WriteFile(path, []byte("not a database"), 0o600)
+			So(err, ShouldBeNil)
+
+			countryWithIP, err := geoIPDatabase.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(countryWithIP.CountryCode, ShouldEqual, "CY")
+		})
+
+		Convey("cannot open a missing database", func() {
+
+			_, err := NewGeoIPDatabase(filepath.Join(t.TempDir(), "missing.mmdb"))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,18 +1,22 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"time"
+
+	"github.com/vonmutinda/organono/app/logger"
 )
 
 const ipAPIURL = "https://ipapi.co/%v/json/"
 
 type (
 	IPAPI interface {
-		CountryForIP(ipAddress string) (*CountryWithIP, error)
+		CountryForIP(ctx context.Context, ipAddress string) (*CountryWithIP, error)
 	}
 
 	AppIPAPI struct {
@@ -26,10 +30,30 @@ type (
 		CountryName        string `json:"country_name"`
 		Currency           string `json:"currency"`
 		CountryCallingCode string `json:"country_calling_code"`
+		// Unavailable is set when failing open because the country could not
+		// be looked up, as opposed to the address having no known country.
+		Unavailable bool `json:"-"`
 	}
 )
 
-func NewIPAPI() *AppIPAPI {
+// NewIPAPI returns the offline database at GEOIP_DATABASE_PATH when it is
+// set and the ipapi.co client otherwise.
+func NewIPAPI() IPAPI {
+
+	path := os.Getenv("GEOIP_DATABASE_PATH")
+	if path == "" {
+		return NewIPAPIClient()
+	}
+
+	geoIPDatabase, err := NewGeoIPDatabase(path)
+	if err != nil {
+		logger.Fatalf("failed to open geoip database err = %v", err)
+	}
+
+	return geoIPDatabase
+}
+
+func NewIPAPIClient() *AppIPAPI {
 	return &AppIPAPI{
 		client: &http.Client{
 			Timeout: time.Second * 5,
@@ -37,15 +61,24 @@ func NewIPAPI() *AppIPAPI {
 	}
 }
 
-func (p *AppIPAPI) CountryForIP(ipAddress string) (*CountryWithIP, error) {
+func (p *AppIPAPI) CountryForIP(ctx context.Context, ipAddress string) (*CountryWithIP, error) {
 
-	response, err := http.Get(fmt.Sprintf(ipAPIURL, ipAddress))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(ipAPIURL, ipAddress), nil)
+	if err != nil {
+		return &CountryWithIP{}, fmt.Errorf("failed to create request err = %v", err)
+	}
+
+	response, err := p.client.Do(request)
 	if err != nil {
 		return &CountryWithIP{}, fmt.Errorf("failed to get response err = %v", err)
 	}
 
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		return &CountryWithIP{}, fmt.Errorf("unexpected response status = %v", response.StatusCode)
+	}
+
 	data, err := ioutil.ReadAll(response.Body)
 	if err != nil {
 		return &CountryWithIP{}, fmt.Errorf("failed to read response body = %v", err)
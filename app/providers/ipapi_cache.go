@@ -0,0 +1,456 @@
+package providers
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/logger"
+)
+
+const (
+	IPAPIFailureModeClosed IPAPIFailureMode = "closed"
+	IPAPIFailureModeOpen   IPAPIFailureMode = "open"
+
+	defaultIPAPICacheSize        = 10000
+	defaultIPAPICacheTTL         = 24 * time.Hour
+	defaultIPAPIFailureThreshold = 5
+	defaultIPAPIOpenDuration     = 30 * time.Second
+	defaultIPAPITimeout          = 2 * time.Second
+
+	ipAPICacheStorePostgres = "postgres"
+)
+
+// ErrIPAPIUnavailable is returned in fail closed mode when the provider is
+// failing or its circuit is open.
+var ErrIPAPIUnavailable = errors.New("ip geolocation unavailable")
+
+type (
+	// IPAPIFailureMode decides what lookups return while the provider is
+	// unavailable. Fail open returns an unknown country, fail closed returns
+	// ErrIPAPIUnavailable.
+	IPAPIFailureMode string
+
+	// IPAPICacheStore keeps looked up countries beyond the lifetime of the
+	// in-memory cache, shared between instances. Get returns a nil country
+	// when ipAddress is not stored or has expired, and otherwise when the
+	// stored country expires.
+	IPAPICacheStore interface {
+		Get(ctx context.Context, ipAddress string) (*CountryWithIP, time.Time, error)
+		Put(ctx context.Context, countryWithIP *CountryWithIP, expiresAt time.Time) error
+	}
+
+	CachedIPAPIOptions struct {
+		CacheSize int
+		CacheTTL  time.Duration
+		// FailureThreshold is the number of consecutive provider failures
+		// that open the circuit, rejecting lookups for OpenDuration before a
+		// single lookup is let through to probe the provider again.
+		FailureThreshold int
+		FailureMode      IPAPIFailureMode
+		OpenDuration     time.Duration
+		Timeout          time.Duration
+	}
+
+	// IPAPIStats is a snapshot of the lookups served by AppCachedIPAPI.
+	IPAPIStats struct {
+		Lookups           int64   `json:"lookups"`
+		CacheHits         int64   `json:"cache_hits"`
+		StoreHits         int64   `json:"store_hits"`
+		HitRate           float64 `json:"hit_rate"`
+		ProviderCalls     int64   `json:"provider_calls"`
+		ProviderFailures  int64   `json:"provider_failures"`
+		Rejected          int64   `json:"rejected"`
+		AverageLatencyMs  float64 `json:"average_latency_ms"`
+		MaxLatencyMs      float64 `json:"max_latency_ms"`
+		CircuitOpen       bool    `json:"circuit_open"`
+		CachedIPAddresses int     `json:"cached_ip_addresses"`
+	}
+
+	// AppCachedIPAPI decorates an IPAPI with an in-memory LRU cache, an
+	// optional shared cache store, a timeout on each provider call and a
+	// circuit breaker.
+	AppCachedIPAPI struct {
+		options  CachedIPAPIOptions
+		provider IPAPI
+		store    IPAPICacheStore
+
+		mu      sync.Mutex
+		entries map[string]*list.Element
+		lru     *list.List
+
+		consecutiveFailures int
+		openUntil           time.Time
+		probing             bool
+
+		lookups          int64
+		cacheHits        int64
+		storeHits        int64
+		providerCalls    int64
+		providerFailures int64
+		rejected         int64
+		latencyTotal     int64
+		latencyMax       int64
+	}
+
+	ipAPICacheEntry struct {
+		countryWithIP *CountryWithIP
+		expiresAt     time.Time
+	}
+)
+
+// NewCachedIPAPI configures the decorator from the environment. Sizes and
+// thresholds are integers and durations use time.ParseDuration syntax.
+// IPAPI_CACHE_STORE=postgres also caches lookups in the database.
+func NewCachedIPAPI(
+	dB db.DB,
+	provider IPAPI,
+) *AppCachedIPAPI {
+
+	options := CachedIPAPIOptions{
+		CacheSize:        intFromEnv("IPAPI_CACHE_SIZE", defaultIPAPICacheSize),
+		CacheTTL:         durationFromEnv("IPAPI_CACHE_TTL", defaultIPAPICacheTTL),
+		FailureThreshold: intFromEnv("IPAPI_FAILURE_THRESHOLD", defaultIPAPIFailureThreshold),
+		FailureMode:      IPAPIFailureMode(os.Getenv("IPAPI_FAILURE_MODE")),
+		OpenDuration:     durationFromEnv("IPAPI_OPEN_DURATION", defaultIPAPIOpenDuration),
+		Timeout:          durationFromEnv("IPAPI_TIMEOUT", defaultIPAPITimeout),
+	}
+
+	if options.FailureMode == "" {
+		options.FailureMode = IPAPIFailureModeOpen
+	}
+
+	if options.FailureMode != IPAPIFailureModeOpen && options.FailureMode != IPAPIFailureModeClosed {
+		logger.Fatalf("invalid ipapi failure mode = [%v]", options.FailureMode)
+	}
+
+	var store IPAPICacheStore
+
+	switch cacheStore := os.Getenv("IPAPI_CACHE_STORE"); cacheStore {
+	case "":
+	case ipAPICacheStorePostgres:
+		store = NewPostgresIPAPICacheStore(dB)
+	default:
+		logger.Fatalf("invalid ipapi cache store = [%v]", cacheStore)
+	}
+
+	return NewCachedIPAPIWithOptions(provider, store, options)
+}
+
+// NewCachedIPAPIWithOptions decorates provider, store may be nil to only
+// cache in memory.
+func NewCachedIPAPIWithOptions(
+	provider IPAPI,
+	store IPAPICacheStore,
+	options CachedIPAPIOptions,
+) *AppCachedIPAPI {
+	return &AppCachedIPAPI{
+		options:  options,
+		provider: provider,
+		store:    store,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (p *AppCachedIPAPI) CountryForIP(
+	ctx context.Context,
+	ipAddress string,
+) (*CountryWithIP, error) {
+
+	atomic.AddInt64(&p.lookups, 1)
+
+	if countryWithIP, ok := p.cached(ipAddress); ok {
+		atomic.AddInt64(&p.cacheHits, 1)
+		return countryWithIP, nil
+	}
+
+	if p.store != nil {
+		countryWithIP, expiresAt, err := p.store.Get(ctx, ipAddress)
+		if err != nil {
+			logger.Warnf("Failed to get cached country for ip address = [%v] err = %v", ipAddress, err)
+		} else if countryWithIP != nil {
+			atomic.AddInt64(&p.storeHits, 1)
+			p.cache(countryWithIP, expiresAt)
+			return countryWithIP, nil
+		}
+	}
+
+	if !p.allowProviderCall() {
+		atomic.AddInt64(&p.rejected, 1)
+		return p.unavailable(ipAddress, ErrIPAPIUnavailable)
+	}
+
+	countryWithIP, err := p.callProvider(ctx, ipAddress)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		// The caller gave up on the lookup, which says nothing about the
+		// provider.
+		p.releaseProbe()
+		return &CountryWithIP{}, ctx.Err()
+	}
+
+	p.recordResult(err)
+	if err != nil {
+		return p.unavailable(ipAddress, err)
+	}
+
+	expiresAt := time.Now().Add(p.options.CacheTTL)
+
+	p.cache(countryWithIP, expiresAt)
+
+	if p.store != nil {
+		err = p.store.Put(ctx, countryWithIP, expiresAt)
+		if err != nil {
+			logger.Warnf("Failed to store cached country for ip address = [%v] err = %v", ipAddress, err)
+		}
+	}
+
+	return countryWithIP, nil
+}
+
+func (p *AppCachedIPAPI) Stats() *IPAPIStats {
+
+	p.mu.Lock()
+	circuitOpen := time.Now().Before(p.openUntil)
+	cachedIPAddresses := p.lru.Len()
+	p.mu.Unlock()
+
+	stats := &IPAPIStats{
+		Lookups:           atomic.LoadInt64(&p.lookups),
+		CacheHits:         atomic.LoadInt64(&p.cacheHits),
+		StoreHits:         atomic.LoadInt64(&p.storeHits),
+		ProviderCalls:     atomic.LoadInt64(&p.providerCalls),
+		ProviderFailures:  atomic.LoadInt64(&p.providerFailures),
+		Rejected:          atomic.LoadInt64(&p.rejected),
+		MaxLatencyMs:      float64(atomic.LoadInt64(&p.latencyMax)) / float64(time.Millisecond),
+		CircuitOpen:       circuitOpen,
+		CachedIPAddresses: cachedIPAddresses,
+	}
+
+	if stats.Lookups > 0 {
+		stats.HitRate = float64(stats.CacheHits+stats.StoreHits) / float64(stats.Lookups)
+	}
+
+	if stats.ProviderCalls > 0 {
+		stats.AverageLatencyMs = float64(atomic.LoadInt64(&p.latencyTotal)) / float64(stats.ProviderCalls) / float64(time.Millisecond)
+	}
+
+	return stats
+}
+
+// allowProviderCall reports whether the circuit lets a provider call through.
+// Once the circuit has been open for OpenDuration a single call is let
+// through, closing the circuit when it succeeds.
+func (p *AppCachedIPAPI) allowProviderCall() bool {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.options.FailureThreshold <= 0 || p.consecutiveFailures < p.options.FailureThreshold {
+		return true
+	}
+
+	if time.Now().Before(p.openUntil) || p.probing {
+		return false
+	}
+
+	p.probing = true
+
+	return true
+}
+
+func (p *AppCachedIPAPI) cache(
+	countryWithIP *CountryWithIP,
+	expiresAt time.Time,
+) {
+
+	if p.options.CacheSize <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := &ipAPICacheEntry{
+		countryWithIP: countryWithIP,
+		expiresAt:     expiresAt,
+	}
+
+	if element, ok := p.entries[countryWithIP.IP]; ok {
+		element.Value = entry
+		p.lru.MoveToFront(element)
+		return
+	}
+
+	p.entries[countryWithIP.IP] = p.lru.PushFront(entry)
+
+	for p.lru.Len() > p.options.CacheSize {
+		oldest := p.lru.Back()
+		p.lru.Remove(oldest)
+		delete(p.entries, oldest.Value.(*ipAPICacheEntry).countryWithIP.IP)
+	}
+}
+
+func (p *AppCachedIPAPI) cached(
+	ipAddress string,
+) (*CountryWithIP, bool) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	element, ok := p.entries[ipAddress]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*ipAPICacheEntry)
+
+	if time.Now().After(entry.expiresAt) {
+		p.lru.Remove(element)
+		delete(p.entries, ipAddress)
+		return nil, false
+	}
+
+	p.lru.MoveToFront(element)
+
+	return entry.countryWithIP, true
+}
+
+// callProvider looks up ipAddress within the timeout. The provider is left to
+// finish in the background when it ignores the cancelled context.
+func (p *AppCachedIPAPI) callProvider(
+	ctx context.Context,
+	ipAddress string,
+) (*CountryWithIP, error) {
+
+	atomic.AddInt64(&p.providerCalls, 1)
+
+	if p.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.options.Timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		countryWithIP *CountryWithIP
+		err           error
+	}
+
+	results := make(chan result, 1)
+	start := time.Now()
+
+	go func() {
+		countryWithIP, err := p.provider.CountryForIP(ctx, ipAddress)
+		results <- result{countryWithIP, err}
+	}()
+
+	var lookup result
+
+	select {
+	case lookup = <-results:
+	case <-ctx.Done():
+		lookup = result{&CountryWithIP{}, ctx.Err()}
+	}
+
+	latency := int64(time.Since(start))
+	atomic.AddInt64(&p.latencyTotal, latency)
+
+	for {
+		latencyMax := atomic.LoadInt64(&p.latencyMax)
+		if latency <= latencyMax || atomic.CompareAndSwapInt64(&p.latencyMax, latencyMax, latency) {
+			break
+		}
+	}
+
+	if lookup.err != nil {
+		return &CountryWithIP{}, lookup.err
+	}
+
+	// Providers may leave the address out of their response.
+	countryWithIP := *lookup.countryWithIP
+	countryWithIP.IP = ipAddress
+
+	return &countryWithIP, nil
+}
+
+// releaseProbe lets another call probe the provider without recording the
+// result of this one.
+func (p *AppCachedIPAPI) releaseProbe() {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probing = false
+}
+
+func (p *AppCachedIPAPI) recordResult(err error) {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probing = false
+
+	if err == nil {
+		p.consecutiveFailures = 0
+		p.openUntil = time.Time{}
+		return
+	}
+
+	atomic.AddInt64(&p.providerFailures, 1)
+
+	p.consecutiveFailures++
+
+	if p.options.FailureThreshold > 0 && p.consecutiveFailures >= p.options.FailureThreshold {
+		p.openUntil = time.Now().Add(p.options.OpenDuration)
+	}
+}
+
+func (p *AppCachedIPAPI) unavailable(
+	ipAddress string,
+	err error,
+) (*CountryWithIP, error) {
+
+	if p.options.FailureMode == IPAPIFailureModeClosed {
+		return &CountryWithIP{}, err
+	}
+
+	logger.Warnf("Failed to find country for ip address = [%v], treating it as unknown err = %v", ipAddress, err)
+
+	return &CountryWithIP{IP: ipAddress, Unavailable: true}, nil
+}
+
+func durationFromEnv(key string, defaultValue time.Duration) time.Duration {
+
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Fatalf("invalid %v = [%v] err = %v", key, value, err)
+	}
+
+	return duration
+}
+
+func intFromEnv(key string, defaultValue int) int {
+
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Fatalf("invalid %v = [%v] err = %v", key, value, err)
+	}
+
+	return number
+}
@@ -0,0 +1,70 @@
+package providers
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+// AppPostgresIPAPICacheStore caches looked up countries in the ip_countries
+// table.
+type AppPostgresIPAPICacheStore struct {
+	dB                  db.DB
+	ipCountryRepository repos.IPCountryRepository
+}
+
+func NewPostgresIPAPICacheStore(dB db.DB) *AppPostgresIPAPICacheStore {
+	return &AppPostgresIPAPICacheStore{
+		dB:                  dB,
+		ipCountryRepository: repos.NewIPCountryRepository(),
+	}
+}
+
+func (s *AppPostgresIPAPICacheStore) Get(
+	ctx context.Context,
+	ipAddress string,
+) (*CountryWithIP, time.Time, error) {
+
+	ipCountry, err := s.ipCountryRepository.IPCountryByIPAddress(ctx, s.dB, ipAddress)
+	if err != nil {
+		if utils.IsErrNoRows(err) {
+			return nil, time.Time{}, nil
+		}
+
+		return nil, time.Time{}, err
+	}
+
+	countryWithIP := &CountryWithIP{
+		IP:                 ipCountry.IPAddress,
+		City:               ipCountry.City,
+		CountryCode:        ipCountry.CountryCode,
+		CountryName:        ipCountry.CountryName,
+		Currency:           ipCountry.Currency,
+		CountryCallingCode: ipCountry.CountryCallingCode,
+	}
+
+	return countryWithIP, ipCountry.ExpiresAt, nil
+}
+
+func (s *AppPostgresIPAPICacheStore) Put(
+	ctx context.Context,
+	countryWithIP *CountryWithIP,
+	expiresAt time.Time,
+) error {
+
+	ipCountry := &entities.IPCountry{
+		City:               countryWithIP.City,
+		CountryCallingCode: countryWithIP.CountryCallingCode,
+		CountryCode:        countryWithIP.CountryCode,
+		CountryName:        countryWithIP.CountryName,
+		Currency:           countryWithIP.Currency,
+		ExpiresAt:          expiresAt,
+		IPAddress:          countryWithIP.IP,
+	}
+
+	return s.ipCountryRepository.Save(ctx, s.dB, ipCountry)
+}
@@ -0,0 +1,273 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testIPAPI struct {
+	countryCodes map[string]string
+	delay        time.Duration
+	err          error
+	lookups      int
+}
+
+func (p *testIPAPI) CountryForIP(ctx context.Context, ipAddress string) (*CountryWithIP, error) {
+
+	p.lookups++
+
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return &CountryWithIP{}, ctx.Err()
+		}
+	}
+
+	if p.err != nil {
+		return &CountryWithIP{}, p.err
+	}
+
+	return &CountryWithIP{CountryCode: p.countryCodes[ipAddress]}, nil
+}
+
+type testIPAPICacheStore struct {
+	mu      sync.Mutex
+	entries map[string]*ipAPICacheEntry
+}
+
+func (s *testIPAPICacheStore) Get(ctx context.Context, ipAddress string) (*CountryWithIP, time.Time, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[ipAddress]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, time.Time{}, nil
+	}
+
+	return entry.countryWithIP, entry.expiresAt, nil
+}
+
+func (s *testIPAPICacheStore) Put(ctx context.Context, countryWithIP *CountryWithIP, expiresAt time.Time) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[countryWithIP.IP] = &ipAPICacheEntry{
+		countryWithIP: countryWithIP,
+		expiresAt:     expiresAt,
+	}
+
+	return nil
+}
+
+func TestCachedIPAPI(t *testing.T) {
+
+	ctx := context.Background()
+
+	Convey("Cached IPAPI", t, func() {
+
+		provider := &testIPAPI{
+			countryCodes: map[string]string{
+				"176.56.168.100":  "CY",
+				"197.156.118.251": "KE",
+			},
+		}
+
+		options := CachedIPAPIOptions{
+			CacheSize:        1,
+			CacheTTL:         time.Hour,
+			FailureThreshold: 2,
+			FailureMode:      IPAPIFailureModeClosed,
+			OpenDuration:     time.Hour,
+			Timeout:          time.Second,
+		}
+
+		Convey("caches looked up countries", func() {
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			for i := 0; i < 3; i++ {
+				countryWithIP, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+				So(err, ShouldBeNil)
+				So(countryWithIP.IP, ShouldEqual, "176.56.168.100")
+				So(countryWithIP.CountryCode, ShouldEqual, "CY")
+			}
+
+			So(provider.lookups, ShouldEqual, 1)
+
+			stats := cachedIPAPI.Stats()
+			So(stats.Lookups, ShouldEqual, 3)
+			So(stats.CacheHits, ShouldEqual, 2)
+			So(stats.ProviderCalls, ShouldEqual, 1)
+			So(stats.HitRate, ShouldAlmostEqual, 2.0/3.0)
+		})
+
+		Convey("evicts the least recently used country", func() {
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			_, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+
+			_, err = cachedIPAPI.CountryForIP(ctx, "197.156.118.251")
+			So(err, ShouldBeNil)
+
+			_, err = cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+
+			So(provider.lookups, ShouldEqual, 3)
+			So(cachedIPAPI.Stats().CachedIPAddresses, ShouldEqual, 1)
+		})
+
+		Convey("looks up countries again once they expire", func() {
+
+			options.CacheTTL = time.Millisecond
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			_, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+
+			time.Sleep(5 * time.Millisecond)
+
+			_, err = cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+
+			So(provider.lookups, ShouldEqual, 2)
+		})
+
+		Convey("serves countries from the cache store", func() {
+
+			store := &testIPAPICacheStore{
+				entries: map[string]*ipAPICacheEntry{
+					"197.156.118.251": {
+						countryWithIP: &CountryWithIP{IP: "197.156.118.251", CountryCode: "KE"},
+						expiresAt:     time.Now().Add(time.Hour),
+					},
+				},
+			}
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, store, options)
+
+			countryWithIP, err := cachedIPAPI.CountryForIP(ctx, "197.156.118.251")
+			So(err, ShouldBeNil)
+			So(countryWithIP.CountryCode, ShouldEqual, "KE")
+			So(provider.lookups, ShouldEqual, 0)
+			So(cachedIPAPI.Stats().StoreHits, ShouldEqual, 1)
+
+			_, err = cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(store.entries["176.56.168.100"].countryWithIP.CountryCode, ShouldEqual, "CY")
+		})
+
+		Convey("keeps the expiry of countries from the cache store", func() {
+
+			store := &testIPAPICacheStore{
+				entries: map[string]*ipAPICacheEntry{
+					"197.156.118.251": {
+						countryWithIP: &CountryWithIP{IP: "197.156.118.251", CountryCode: "KE"},
+						expiresAt:     time.Now().Add(time.Millisecond),
+					},
+				},
+			}
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, store, options)
+
+			_, err := cachedIPAPI.CountryForIP(ctx, "197.156.118.251")
+			So(err, ShouldBeNil)
+			So(provider.lookups, ShouldEqual, 0)
+
+			time.Sleep(5 * time.Millisecond)
+
+			_, err = cachedIPAPI.CountryForIP(ctx, "197.156.118.251")
+			So(err, ShouldBeNil)
+			So(provider.lookups, ShouldEqual, 1)
+		})
+
+		Convey("times out slow lookups", func() {
+
+			provider.delay = time.Second
+			options.Timeout = 10 * time.Millisecond
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			_, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			So(cachedIPAPI.Stats().ProviderFailures, ShouldEqual, 1)
+		})
+
+		Convey("does not count lookups cancelled by the caller as failures", func() {
+
+			provider.delay = time.Second
+			options.FailureThreshold = 1
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(10*time.Millisecond, cancel)
+
+			_, err := cachedIPAPI.CountryForIP(cancelledCtx, "176.56.168.100")
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+
+			stats := cachedIPAPI.Stats()
+			So(stats.ProviderFailures, ShouldEqual, 0)
+			So(stats.CircuitOpen, ShouldBeFalse)
+		})
+
+		Convey("opens the circuit after consecutive failures", func() {
+
+			provider.err = errors.New("rate limited")
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			for i := 0; i < 2; i++ {
+				_, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+				So(err, ShouldEqual, provider.err)
+			}
+
+			_, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldEqual, ErrIPAPIUnavailable)
+			So(provider.lookups, ShouldEqual, 2)
+
+			stats := cachedIPAPI.Stats()
+			So(stats.CircuitOpen, ShouldBeTrue)
+			So(stats.Rejected, ShouldEqual, 1)
+
+			Convey("and closes it once a probe succeeds", func() {
+
+				cachedIPAPI.openUntil = time.Now()
+				provider.err = nil
+
+				countryWithIP, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+				So(err, ShouldBeNil)
+				So(countryWithIP.CountryCode, ShouldEqual, "CY")
+
+				_, err = cachedIPAPI.CountryForIP(ctx, "197.156.118.251")
+				So(err, ShouldBeNil)
+				So(cachedIPAPI.Stats().CircuitOpen, ShouldBeFalse)
+			})
+		})
+
+		Convey("treats the country as unknown when failing open", func() {
+
+			provider.err = errors.New("rate limited")
+			options.FailureMode = IPAPIFailureModeOpen
+
+			cachedIPAPI := NewCachedIPAPIWithOptions(provider, nil, options)
+
+			countryWithIP, err := cachedIPAPI.CountryForIP(ctx, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(countryWithIP.IP, ShouldEqual, "176.56.168.100")
+			So(countryWithIP.CountryCode, ShouldEqual, "")
+			So(countryWithIP.Unavailable, ShouldBeTrue)
+			So(cachedIPAPI.Stats().CachedIPAddresses, ShouldEqual, 0)
+		})
+	})
+}
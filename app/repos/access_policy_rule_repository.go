@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	listEnabledAccessPolicyRulesSQL = "SELECT id, name, rule_type, countries, methods, routes, enabled, created_at, updated_at FROM access_policy_rules WHERE enabled ORDER BY id ASC"
+	saveAccessPolicyRuleSQL         = "INSERT INTO access_policy_rules (name, rule_type, countries, methods, routes, enabled, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	updateAccessPolicyRuleSQL       = "UPDATE access_policy_rules SET name = $1, rule_type = $2, countries = $3, methods = $4, routes = $5, enabled = $6, updated_at = $7 WHERE id = $8"
+)
+
+type (
+	AccessPolicyRuleRepository interface {
+		ListEnabledAccessPolicyRules(ctx context.Context, operations db.SQLOperations) ([]*entities.AccessPolicyRule, error)
+		Save(ctx context.Context, operations db.SQLOperations, rule *entities.AccessPolicyRule) error
+	}
+
+	AppAccessPolicyRuleRepository struct{}
+)
+
+func NewAccessPolicyRuleRepository() *AppAccessPolicyRuleRepository {
+	return &AppAccessPolicyRuleRepository{}
+}
+
+func (r *AppAccessPolicyRuleRepository) ListEnabledAccessPolicyRules(
+	ctx context.Context,
+	operations db.SQLOperations,
+) ([]*entities.AccessPolicyRule, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		listEnabledAccessPolicyRulesSQL,
+	)
+	if err != nil {
+		return []*entities.AccessPolicyRule{}, utils.NewError(
+			err,
+			"list enabled access policy rules query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	rules := make([]*entities.AccessPolicyRule, 0)
+
+	for rows.Next() {
+
+		rule, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.AccessPolicyRule{}, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.AccessPolicyRule{}, utils.NewError(
+			rows.Err(),
+			"list enabled access policy rules rows error",
+		)
+	}
+
+	return rules, nil
+}
+
+func (r *AppAccessPolicyRuleRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	rule *entities.AccessPolicyRule,
+) error {
+
+	rule.Touch()
+
+	if rule.IsNew() {
+
+		err := operations.QueryRowContext(
+			ctx,
+			saveAccessPolicyRuleSQL,
+			rule.Name,
+			rule.RuleType,
+			pq.Array(rule.Countries),
+			pq.Array(rule.Methods),
+			pq.Array(rule.Routes),
+			rule.Enabled,
+			rule.CreatedAt,
+			rule.UpdatedAt,
+		).Scan(
+			&rule.ID,
+		)
+		if err != nil {
+			return utils.NewError(
+				err,
+				"save access policy rule query row error",
+			)
+		}
+
+		return nil
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		updateAccessPolicyRuleSQL,
+		rule.Name,
+		rule.RuleType,
+		pq.Array(rule.Countries),
+		pq.Array(rule.Methods),
+		pq.Array(rule.Routes),
+		rule.Enabled,
+		rule.UpdatedAt,
+		rule.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update access policy rule exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppAccessPolicyRuleRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.AccessPolicyRule, error) {
+
+	var rule entities.AccessPolicyRule
+
+	err := rowScanner.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.RuleType,
+		pq.Array(&rule.Countries),
+		pq.Array(&rule.Methods),
+		pq.Array(&rule.Routes),
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.AccessPolicyRule{}, utils.NewError(
+			err,
+			"scan access policy rule row error",
+		)
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,237 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	getAuditLogCountSQL = "SELECT COUNT(id) FROM audit_logs"
+	getAuditLogsSQL     = "SELECT id, action, actor_id, changes, ip_address, request_id, resource_id, resource_type, user_agent, created_at FROM audit_logs"
+	saveAuditLogSQL     = "INSERT INTO audit_logs (action, actor_id, changes, ip_address, request_id, resource_id, resource_type, user_agent, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+)
+
+type (
+	AuditLogRepository interface {
+		AuditLogCount(ctx context.Context, operations db.SQLOperations, filter *forms.AuditFilter) (int, error)
+		ListAuditLogs(ctx context.Context, operations db.SQLOperations, filter *forms.AuditFilter) ([]*entities.AuditLog, error)
+		Save(ctx context.Context, operations db.SQLOperations, auditLog *entities.AuditLog) error
+	}
+
+	AppAuditLogRepository struct{}
+)
+
+func NewAuditLogRepository() *AppAuditLogRepository {
+	return &AppAuditLogRepository{}
+}
+
+func (r *AppAuditLogRepository) AuditLogCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.AuditFilter,
+) (int, error) {
+
+	var count int
+
+	query, args := r.buildQuery(getAuditLogCountSQL, filter.NoPagination())
+
+	err := operations.QueryRowContext(
+		ctx,
+		query,
+		args...,
+	).Scan(&count)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"audit log count query row error",
+		)
+	}
+
+	return count, nil
+}
+
+func (r *AppAuditLogRepository) ListAuditLogs(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.AuditFilter,
+) ([]*entities.AuditLog, error) {
+
+	query, args := r.buildQuery(getAuditLogsSQL, filter)
+
+	rows, err := operations.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []*entities.AuditLog{}, utils.NewError(
+			err,
+			"list audit logs query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	auditLogs := make([]*entities.AuditLog, 0)
+
+	for rows.Next() {
+
+		auditLog, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.AuditLog{}, err
+		}
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.AuditLog{}, utils.NewError(
+			rows.Err(),
+			"list audit logs rows error",
+		)
+	}
+
+	return auditLogs, nil
+}
+
+func (r *AppAuditLogRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	auditLog *entities.AuditLog,
+) error {
+
+	if !auditLog.IsNew() {
+		return utils.NewError(
+			fmt.Errorf("audit log id = %v already saved", auditLog.ID),
+			"audit logs are append only",
+		)
+	}
+
+	if auditLog.CreatedAt.IsZero() {
+		auditLog.CreatedAt = time.Now()
+	}
+
+	changes, err := json.Marshal(auditLog.Changes)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"marshal audit log changes error",
+		)
+	}
+
+	err = operations.QueryRowContext(
+		ctx,
+		saveAuditLogSQL,
+		auditLog.Action,
+		auditLog.ActorID,
+		changes,
+		auditLog.IPAddress,
+		auditLog.RequestID,
+		auditLog.ResourceID,
+		auditLog.ResourceType,
+		auditLog.UserAgent,
+		auditLog.CreatedAt,
+	).Scan(
+		&auditLog.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save audit log query row error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppAuditLogRepository) buildQuery(
+	query string,
+	filter *forms.AuditFilter,
+) (string, []interface{}) {
+
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf(" action = $%d", counter.Touch()))
+		args = append(args, filter.Action)
+	}
+
+	if filter.ActorID > 0 {
+		conditions = append(conditions, fmt.Sprintf(" actor_id = $%d", counter.Touch()))
+		args = append(args, filter.ActorID)
+	}
+
+	if filter.ResourceType != "" {
+		conditions = append(conditions, fmt.Sprintf(" resource_type = $%d", counter.Touch()))
+		args = append(args, filter.ResourceType)
+	}
+
+	if filter.ResourceID > 0 {
+		conditions = append(conditions, fmt.Sprintf(" resource_id = $%d", counter.Touch()))
+		args = append(args, filter.ResourceID)
+	}
+
+	if filter.From.Valid {
+		conditions = append(conditions, fmt.Sprintf(" created_at >= $%d", counter.Touch()))
+		args = append(args, filter.From.Time)
+	}
+
+	if filter.To.Valid {
+		conditions = append(conditions, fmt.Sprintf(" created_at < $%d", counter.Touch()))
+		args = append(args, filter.To.Time)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE" + strings.Join(conditions, " AND ")
+	}
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
+func (r *AppAuditLogRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.AuditLog, error) {
+
+	var auditLog entities.AuditLog
+	var changes []byte
+
+	err := rowScanner.Scan(
+		&auditLog.ID,
+		&auditLog.Action,
+		&auditLog.ActorID,
+		&changes,
+		&auditLog.IPAddress,
+		&auditLog.RequestID,
+		&auditLog.ResourceID,
+		&auditLog.ResourceType,
+		&auditLog.UserAgent,
+		&auditLog.CreatedAt,
+	)
+	if err != nil {
+		return &entities.AuditLog{}, utils.NewError(
+			err,
+			"scan audit log row error",
+		)
+	}
+
+	err = json.Unmarshal(changes, &auditLog.Changes)
+	if err != nil {
+		return &entities.AuditLog{}, utils.NewError(
+			err,
+			"unmarshal audit log changes error",
+		)
+	}
+
+	return &auditLog, nil
+}
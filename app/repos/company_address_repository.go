@@ -0,0 +1,246 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteCompanyAddressSQL               = "DELETE FROM company_addresses WHERE id = $1"
+	deleteConflictingRegisteredAddressSQL = "DELETE FROM company_addresses ca USING company_countries cc WHERE cc.id = ca.company_country_id AND cc.company_id = $1 AND ca.address_type = 'registered' AND EXISTS (SELECT 1 FROM company_addresses sa JOIN company_countries sc ON sc.id = sa.company_country_id WHERE sc.company_id = $2 AND sa.address_type = 'registered')"
+	getCompanyAddressesSQL                = "SELECT ca.id, cc.company_id, ca.company_country_id, c.code, c.name, ca.address_type, ca.line1, ca.line2, ca.city, ca.region, ca.postal_code, ca.created_at, ca.updated_at FROM company_addresses ca JOIN company_countries cc ON cc.id = ca.company_country_id JOIN countries c ON c.id = cc.country_id"
+	getCompanyAddressByCompanyAndIDSQL    = getCompanyAddressesSQL + " WHERE cc.company_id = $1 AND ca.id = $2"
+	getCompanyAddressesByCompanyIDSQL     = getCompanyAddressesSQL + " WHERE cc.company_id = $1 ORDER BY ca.company_country_id ASC, ca.id ASC"
+	reassignCompanyAddressesSQL           = "UPDATE company_addresses ca SET company_country_id = sc.id, updated_at = $1 FROM company_countries mc, company_countries sc WHERE mc.id = ca.company_country_id AND mc.company_id = $2 AND sc.company_id = $3 AND sc.country_id = mc.country_id AND (ca.address_type = 'operating' OR NOT EXISTS (SELECT 1 FROM company_addresses sa WHERE sa.company_country_id = sc.id AND sa.address_type = ca.address_type))"
+	saveCompanyAddressSQL                 = "INSERT INTO company_addresses (company_country_id, address_type, line1, line2, city, region, postal_code, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	updateCompanyAddressSQL               = "UPDATE company_addresses SET address_type = $1, line1 = $2, line2 = $3, city = $4, region = $5, postal_code = $6, updated_at = $7 WHERE id = $8"
+)
+
+type (
+	CompanyAddressRepository interface {
+		CompanyAddressByCompanyAndID(ctx context.Context, operations db.SQLOperations, companyID, addressID int64) (*entities.CompanyAddress, error)
+		CompanyAddressesByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyAddress, error)
+		DeleteCompanyAddress(ctx context.Context, operations db.SQLOperations, addressID int64) error
+		ReassignCompanyAddresses(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, address *entities.CompanyAddress) error
+	}
+
+	AppCompanyAddressRepository struct{}
+)
+
+func NewCompanyAddressRepository() *AppCompanyAddressRepository {
+	return &AppCompanyAddressRepository{}
+}
+
+func (r *AppCompanyAddressRepository) CompanyAddressByCompanyAndID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	addressID int64,
+) (*entities.CompanyAddress, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyAddressByCompanyAndIDSQL,
+		companyID,
+		addressID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyAddressRepository) CompanyAddressesByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyAddress, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyAddressesByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyAddress{}, utils.NewError(
+			err,
+			"company addresses by company id query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	addresses := make([]*entities.CompanyAddress, 0)
+
+	for rows.Next() {
+
+		address, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyAddress{}, err
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyAddress{}, utils.NewError(
+			rows.Err(),
+			"company addresses by company id rows error",
+		)
+	}
+
+	return addresses, nil
+}
+
+func (r *AppCompanyAddressRepository) DeleteCompanyAddress(
+	ctx context.Context,
+	operations db.SQLOperations,
+	addressID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCompanyAddressSQL,
+		addressID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company address exec context error",
+		)
+	}
+
+	return nil
+}
+
+// ReassignCompanyAddresses moves addresses held under countries that both
+// companies operate in over to the target's company country. Countries only
+// the source operates in carry their addresses along when the countries are
+// reassigned. The target keeps its own registered and billing addresses.
+func (r *AppCompanyAddressRepository) ReassignCompanyAddresses(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteConflictingRegisteredAddressSQL,
+		fromCompanyID,
+		toCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete conflicting registered address exec context error",
+		)
+	}
+
+	_, err = operations.ExecContext(
+		ctx,
+		reassignCompanyAddressesSQL,
+		time.Now(),
+		fromCompanyID,
+		toCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company addresses exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyAddressRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	address *entities.CompanyAddress,
+) error {
+
+	address.Touch()
+
+	if address.IsNew() {
+
+		err := operations.QueryRowContext(
+			ctx,
+			saveCompanyAddressSQL,
+			address.CompanyCountryID,
+			address.AddressType,
+			address.Line1,
+			address.Line2,
+			address.City,
+			address.Region,
+			address.PostalCode,
+			address.CreatedAt,
+			address.UpdatedAt,
+		).Scan(
+			&address.ID,
+		)
+		if err != nil {
+			return utils.NewError(
+				err,
+				"save company address query row error",
+			)
+		}
+
+		return nil
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		updateCompanyAddressSQL,
+		address.AddressType,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.UpdatedAt,
+		address.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update company address exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyAddressRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CompanyAddress, error) {
+
+	var address entities.CompanyAddress
+
+	err := rowScanner.Scan(
+		&address.ID,
+		&address.CompanyID,
+		&address.CompanyCountryID,
+		&address.CountryCode,
+		&address.CountryName,
+		&address.AddressType,
+		&address.Line1,
+		&address.Line2,
+		&address.City,
+		&address.Region,
+		&address.PostalCode,
+		&address.CreatedAt,
+		&address.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyAddress{}, utils.NewError(
+			err,
+			"scan company address row error",
+		)
+	}
+
+	return &address, nil
+}
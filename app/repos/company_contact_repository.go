@@ -0,0 +1,275 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteCompanyContactSQL               = "DELETE FROM company_contacts WHERE id = $1"
+	getCompanyContactsSQL                 = "SELECT id, company_id, name, role, email, country_code, number, is_primary, created_at, updated_at FROM company_contacts"
+	getCompanyContactByCompanyAndEmailSQL = getCompanyContactsSQL + " WHERE company_id = $1 AND LOWER(email) = LOWER($2)"
+	getCompanyContactByCompanyAndIDSQL    = getCompanyContactsSQL + " WHERE company_id = $1 AND id = $2"
+	getCompanyContactsByCompanyIDSQL      = getCompanyContactsSQL + " WHERE company_id = $1 ORDER BY is_primary DESC, id ASC"
+	reassignCompanyContactsSQL            = "UPDATE company_contacts SET company_id = $1, is_primary = is_primary AND NOT EXISTS (SELECT 1 FROM company_contacts WHERE company_id = $1 AND is_primary), updated_at = $2 WHERE company_id = $3 AND (email IS NULL OR LOWER(email) NOT IN (SELECT LOWER(email) FROM company_contacts WHERE company_id = $1 AND email IS NOT NULL))"
+	saveCompanyContactSQL                 = "INSERT INTO company_contacts (company_id, name, role, email, country_code, number, is_primary, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	unsetPrimaryCompanyContactSQL         = "UPDATE company_contacts SET is_primary = FALSE, updated_at = $1 WHERE company_id = $2 AND is_primary"
+	updateCompanyContactSQL               = "UPDATE company_contacts SET name = $1, role = $2, email = $3, country_code = $4, number = $5, is_primary = $6, updated_at = $7 WHERE id = $8"
+)
+
+type (
+	CompanyContactRepository interface {
+		CompanyContactByCompanyAndEmail(ctx context.Context, operations db.SQLOperations, companyID int64, email string) (*entities.CompanyContact, error)
+		CompanyContactByCompanyAndID(ctx context.Context, operations db.SQLOperations, companyID, contactID int64) (*entities.CompanyContact, error)
+		CompanyContactsByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyContact, error)
+		DeleteCompanyContact(ctx context.Context, operations db.SQLOperations, contactID int64) error
+		ReassignCompanyContacts(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, contact *entities.CompanyContact) error
+		UnsetPrimaryCompanyContact(ctx context.Context, operations db.SQLOperations, companyID int64) error
+	}
+
+	AppCompanyContactRepository struct{}
+)
+
+func NewCompanyContactRepository() *AppCompanyContactRepository {
+	return &AppCompanyContactRepository{}
+}
+
+func (r *AppCompanyContactRepository) CompanyContactByCompanyAndEmail(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+	email string,
+) (*entities.CompanyContact, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyContactByCompanyAndEmailSQL,
+		companyID,
+		email,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyContactRepository) CompanyContactByCompanyAndID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	contactID int64,
+) (*entities.CompanyContact, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyContactByCompanyAndIDSQL,
+		companyID,
+		contactID,
+	)
+
+	return r.scanRow(row)
+}
+
+// CompanyContactsByCompanyID lists the contacts of a company with the
+// primary contact first.
+func (r *AppCompanyContactRepository) CompanyContactsByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyContact, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyContactsByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyContact{}, utils.NewError(
+			err,
+			"company contacts by company id query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	contacts := make([]*entities.CompanyContact, 0)
+
+	for rows.Next() {
+
+		contact, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyContact{}, err
+		}
+
+		contacts = append(contacts, contact)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyContact{}, utils.NewError(
+			rows.Err(),
+			"company contacts by company id rows error",
+		)
+	}
+
+	return contacts, nil
+}
+
+func (r *AppCompanyContactRepository) DeleteCompanyContact(
+	ctx context.Context,
+	operations db.SQLOperations,
+	contactID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCompanyContactSQL,
+		contactID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company contact exec context error",
+		)
+	}
+
+	return nil
+}
+
+// ReassignCompanyContacts moves the contacts of one company to another. The
+// target keeps its own primary contact and contacts whose email it already
+// has are left behind.
+func (r *AppCompanyContactRepository) ReassignCompanyContacts(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyContactsSQL,
+		toCompanyID,
+		time.Now(),
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company contacts exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyContactRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	contact *entities.CompanyContact,
+) error {
+
+	contact.Touch()
+
+	if contact.IsNew() {
+
+		err := operations.QueryRowContext(
+			ctx,
+			saveCompanyContactSQL,
+			contact.CompanyID,
+			contact.Name,
+			contact.Role,
+			contact.Email,
+			contact.PhoneNumber.CountryCode,
+			contact.PhoneNumber.Number,
+			contact.IsPrimary,
+			contact.CreatedAt,
+			contact.UpdatedAt,
+		).Scan(
+			&contact.ID,
+		)
+		if err != nil {
+			return utils.NewError(
+				err,
+				"save company contact query row error",
+			)
+		}
+
+		return nil
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		updateCompanyContactSQL,
+		contact.Name,
+		contact.Role,
+		contact.Email,
+		contact.PhoneNumber.CountryCode,
+		contact.PhoneNumber.Number,
+		contact.IsPrimary,
+		contact.UpdatedAt,
+		contact.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update company contact exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyContactRepository) UnsetPrimaryCompanyContact(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		unsetPrimaryCompanyContactSQL,
+		time.Now(),
+		companyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"unset primary company contact exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyContactRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CompanyContact, error) {
+
+	var contact entities.CompanyContact
+
+	err := rowScanner.Scan(
+		&contact.ID,
+		&contact.CompanyID,
+		&contact.Name,
+		&contact.Role,
+		&contact.Email,
+		&contact.PhoneNumber.CountryCode,
+		&contact.PhoneNumber.Number,
+		&contact.IsPrimary,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyContact{}, utils.NewError(
+			err,
+			"scan company contact row error",
+		)
+	}
+
+	contact.Phone = contact.PhoneNumber.Phone()
+
+	return &contact, nil
+}
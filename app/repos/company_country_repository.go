@@ -2,19 +2,34 @@ package repos
 
 import (
 	"context"
-	"errors"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/utils"
 )
 
 const (
-	saveCompanyCountrySQL = "INSERT INTO company_countries (company_id, country_id, operation_status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	countCompanyCountriesSQL                = "SELECT COUNT(id) FROM company_countries WHERE company_id = $1"
+	deleteCompanyCountrySQL                 = "DELETE FROM company_countries WHERE id = $1"
+	getCompanyCountriesSQL                  = "SELECT cc.id, cc.company_id, cc.country_id, c.code, c.name, cc.operation_status, cc.created_at, cc.updated_at FROM company_countries cc JOIN countries c ON c.id = cc.country_id"
+	getCompanyCountriesByCompanyIDSQL       = getCompanyCountriesSQL + " WHERE cc.company_id = $1 ORDER BY cc.id ASC"
+	getCompanyCountriesByCompanyIDsSQL      = getCompanyCountriesSQL + " WHERE cc.company_id = ANY($1) ORDER BY cc.id ASC"
+	getCompanyCountryByCompanyAndCountrySQL = getCompanyCountriesSQL + " WHERE cc.company_id = $1 AND cc.country_id = $2"
+	reassignCompanyCountriesSQL             = "UPDATE company_countries SET company_id = $1, updated_at = $2 WHERE company_id = $3 AND country_id NOT IN (SELECT country_id FROM company_countries WHERE company_id = $1)"
+	saveCompanyCountrySQL                   = "INSERT INTO company_countries (company_id, country_id, operation_status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	updateCompanyCountrySQL                 = "UPDATE company_countries SET operation_status = $1, updated_at = $2 WHERE id = $3"
 )
 
 type (
 	CompanyCountryRepository interface {
+		CompanyCountriesByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyCountry, error)
+		CompanyCountriesByCompanyIDs(ctx context.Context, operations db.SQLOperations, companyIDs []int64) (map[int64][]*entities.CompanyCountry, error)
+		CompanyCountryByCompanyAndCountry(ctx context.Context, operations db.SQLOperations, companyID, countryID int64) (*entities.CompanyCountry, error)
+		CompanyCountryCount(ctx context.Context, operations db.SQLOperations, companyID int64) (int, error)
+		DeleteCompanyCountry(ctx context.Context, operations db.SQLOperations, companyCountryID int64) error
+		ReassignCompanyCountries(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
 		Save(ctx context.Context, operations db.SQLOperations, companyCountry *entities.CompanyCountry) error
 	}
 
@@ -25,6 +40,181 @@ func NewCompanyCountryRepository() *AppCompanyCountryRepository {
 	return &AppCompanyCountryRepository{}
 }
 
+func (r *AppCompanyCountryRepository) CompanyCountriesByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyCountry, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyCountriesByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyCountry{}, utils.NewError(
+			err,
+			"company countries by company id query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	companyCountries := make([]*entities.CompanyCountry, 0)
+
+	for rows.Next() {
+
+		companyCountry, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyCountry{}, err
+		}
+
+		companyCountries = append(companyCountries, companyCountry)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyCountry{}, utils.NewError(
+			rows.Err(),
+			"company countries by company id rows error",
+		)
+	}
+
+	return companyCountries, nil
+}
+
+func (r *AppCompanyCountryRepository) CompanyCountriesByCompanyIDs(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyIDs []int64,
+) (map[int64][]*entities.CompanyCountry, error) {
+
+	companyCountries := make(map[int64][]*entities.CompanyCountry)
+
+	if len(companyIDs) == 0 {
+		return companyCountries, nil
+	}
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyCountriesByCompanyIDsSQL,
+		pq.Array(companyIDs),
+	)
+	if err != nil {
+		return companyCountries, utils.NewError(
+			err,
+			"company countries by company ids query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		companyCountry, err := r.scanRow(rows)
+		if err != nil {
+			return companyCountries, err
+		}
+
+		companyCountries[companyCountry.CompanyID] = append(companyCountries[companyCountry.CompanyID], companyCountry)
+	}
+
+	if rows.Err() != nil {
+		return companyCountries, utils.NewError(
+			rows.Err(),
+			"company countries by company ids rows error",
+		)
+	}
+
+	return companyCountries, nil
+}
+
+func (r *AppCompanyCountryRepository) CompanyCountryByCompanyAndCountry(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	countryID int64,
+) (*entities.CompanyCountry, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyCountryByCompanyAndCountrySQL,
+		companyID,
+		countryID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyCountryRepository) CompanyCountryCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) (int, error) {
+
+	var count int
+
+	err := operations.QueryRowContext(
+		ctx,
+		countCompanyCountriesSQL,
+		companyID,
+	).Scan(&count)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"company country count query row error",
+		)
+	}
+
+	return count, nil
+}
+
+func (r *AppCompanyCountryRepository) DeleteCompanyCountry(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyCountryID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCompanyCountrySQL,
+		companyCountryID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company country exec context error",
+		)
+	}
+
+	return nil
+}
+
+// ReassignCompanyCountries moves the countries of one company to another,
+// leaving behind those the target company already operates in.
+func (r *AppCompanyCountryRepository) ReassignCompanyCountries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyCountriesSQL,
+		toCompanyID,
+		time.Now(),
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company countries exec context error",
+		)
+	}
+
+	return nil
+}
+
 func (r *AppCompanyCountryRepository) Save(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -56,5 +246,45 @@ func (r *AppCompanyCountryRepository) Save(
 		return nil
 	}
 
-	return errors.New("cannot update company country")
+	_, err := operations.ExecContext(
+		ctx,
+		updateCompanyCountrySQL,
+		companyCountry.OperationStatus,
+		companyCountry.UpdatedAt,
+		companyCountry.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update company country exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyCountryRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CompanyCountry, error) {
+
+	var companyCountry entities.CompanyCountry
+
+	err := rowScanner.Scan(
+		&companyCountry.ID,
+		&companyCountry.CompanyID,
+		&companyCountry.CountryID,
+		&companyCountry.CountryCode,
+		&companyCountry.CountryName,
+		&companyCountry.OperationStatus,
+		&companyCountry.CreatedAt,
+		&companyCountry.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyCountry{}, utils.NewError(
+			err,
+			"scan company country row error",
+		)
+	}
+
+	return &companyCountry, nil
 }
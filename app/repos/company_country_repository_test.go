@@ -6,6 +6,7 @@ import (
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/utils"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -42,5 +43,73 @@ func TestCompanyCountryRepository(t *testing.T) {
 
 			So(companyCountry.ID, ShouldNotBeZeroValue)
 		})
+
+		Convey("can update a company country", func() {
+
+			company, companyCountry, err := CreateCompany(ctx, dB, "Microsoft", country)
+			So(err, ShouldBeNil)
+
+			companyCountry.OperationStatus = entities.OperationStatusTypeClosed
+
+			err = companyCountryRepository.Save(ctx, dB, companyCountry)
+			So(err, ShouldBeNil)
+
+			foundCompanyCountry, err := companyCountryRepository.CompanyCountryByCompanyAndCountry(ctx, dB, company.ID, country.ID)
+			So(err, ShouldBeNil)
+
+			So(foundCompanyCountry.ID, ShouldEqual, companyCountry.ID)
+			So(foundCompanyCountry.CountryName, ShouldEqual, country.Name)
+			So(foundCompanyCountry.OperationStatus, ShouldEqual, entities.OperationStatusTypeClosed)
+		})
+
+		Convey("can list company countries", func() {
+
+			greece := &entities.Country{
+				CountryCode:  "GR",
+				Currency:     "EUR",
+				Name:         "Greece",
+				DiallingCode: "+30",
+			}
+
+			err := NewCountryRepository().Save(ctx, dB, greece)
+			So(err, ShouldBeNil)
+
+			company, _, err := CreateCompany(ctx, dB, "Microsoft", country)
+			So(err, ShouldBeNil)
+
+			_, err = CreateCompanyCountry(ctx, dB, company.ID, greece.ID)
+			So(err, ShouldBeNil)
+
+			companyCountries, err := companyCountryRepository.CompanyCountriesByCompanyID(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(companyCountries), ShouldEqual, 2)
+			So(companyCountries[0].CountryID, ShouldEqual, country.ID)
+			So(companyCountries[1].CountryID, ShouldEqual, greece.ID)
+
+			companyCountriesMap, err := companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, []int64{company.ID})
+			So(err, ShouldBeNil)
+			So(len(companyCountriesMap[company.ID]), ShouldEqual, 2)
+
+			count, err := companyCountryRepository.CompanyCountryCount(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 2)
+
+			companies, err := companyRepository.ListCompanies(ctx, dB, &forms.Filter{})
+			So(err, ShouldBeNil)
+			So(len(companies), ShouldEqual, 1)
+		})
+
+		Convey("can delete a company country", func() {
+
+			_, companyCountry, err := CreateCompany(ctx, dB, "Microsoft", country)
+			So(err, ShouldBeNil)
+
+			err = companyCountryRepository.DeleteCompanyCountry(ctx, dB, companyCountry.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyCountryRepository.CompanyCountryByCompanyAndCountry(ctx, dB, companyCountry.CompanyID, country.ID)
+			So(err, ShouldNotBeNil)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
 	}))
 }
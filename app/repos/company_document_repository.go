@@ -0,0 +1,249 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteCompanyDocumentSQL            = "DELETE FROM company_documents WHERE id = $1"
+	getCompanyDocumentsSQL              = "SELECT id, company_id, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at, updated_at FROM company_documents"
+	getCompanyDocumentByCompanyAndIDSQL = getCompanyDocumentsSQL + " WHERE company_id = $1 AND id = $2"
+	getCompanyDocumentsByCompanyIDSQL   = getCompanyDocumentsSQL + " WHERE company_id = $1 ORDER BY created_at DESC, id DESC"
+	getDeletedCompanyDocumentsSQL       = getCompanyDocumentsSQL + " WHERE company_id IN (SELECT id FROM companies WHERE deleted_at IS NOT NULL AND deleted_at < $1 FOR UPDATE) ORDER BY id ASC"
+	reassignCompanyDocumentsSQL         = "UPDATE company_documents SET company_id = $1, updated_at = $2 WHERE company_id = $3"
+	saveCompanyDocumentSQL              = "INSERT INTO company_documents (company_id, file_name, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+)
+
+type (
+	CompanyDocumentRepository interface {
+		CompanyDocumentByCompanyAndID(ctx context.Context, operations db.SQLOperations, companyID, documentID int64) (*entities.CompanyDocument, error)
+		CompanyDocumentsByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyDocument, error)
+		DeleteCompanyDocument(ctx context.Context, operations db.SQLOperations, documentID int64) error
+		DeletedCompanyDocuments(ctx context.Context, operations db.SQLOperations, deletedBefore time.Time) ([]*entities.CompanyDocument, error)
+		ReassignCompanyDocuments(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, document *entities.CompanyDocument) error
+	}
+
+	AppCompanyDocumentRepository struct{}
+)
+
+func NewCompanyDocumentRepository() *AppCompanyDocumentRepository {
+	return &AppCompanyDocumentRepository{}
+}
+
+func (r *AppCompanyDocumentRepository) CompanyDocumentByCompanyAndID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	documentID int64,
+) (*entities.CompanyDocument, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyDocumentByCompanyAndIDSQL,
+		companyID,
+		documentID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyDocumentRepository) CompanyDocumentsByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyDocument, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyDocumentsByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyDocument{}, utils.NewError(
+			err,
+			"company documents by company id query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	documents := make([]*entities.CompanyDocument, 0)
+
+	for rows.Next() {
+
+		document, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyDocument{}, err
+		}
+
+		documents = append(documents, document)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyDocument{}, utils.NewError(
+			rows.Err(),
+			"company documents by company id rows error",
+		)
+	}
+
+	return documents, nil
+}
+
+func (r *AppCompanyDocumentRepository) DeleteCompanyDocument(
+	ctx context.Context,
+	operations db.SQLOperations,
+	documentID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCompanyDocumentSQL,
+		documentID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company document exec context error",
+		)
+	}
+
+	return nil
+}
+
+// DeletedCompanyDocuments returns the documents of companies soft deleted
+// before deletedBefore. The companies are locked until the end of the
+// transaction so that they cannot be restored before being purged.
+func (r *AppCompanyDocumentRepository) DeletedCompanyDocuments(
+	ctx context.Context,
+	operations db.SQLOperations,
+	deletedBefore time.Time,
+) ([]*entities.CompanyDocument, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getDeletedCompanyDocumentsSQL,
+		deletedBefore,
+	)
+	if err != nil {
+		return []*entities.CompanyDocument{}, utils.NewError(
+			err,
+			"deleted company documents query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	documents := make([]*entities.CompanyDocument, 0)
+
+	for rows.Next() {
+
+		document, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyDocument{}, err
+		}
+
+		documents = append(documents, document)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyDocument{}, utils.NewError(
+			rows.Err(),
+			"deleted company documents rows error",
+		)
+	}
+
+	return documents, nil
+}
+
+func (r *AppCompanyDocumentRepository) ReassignCompanyDocuments(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyDocumentsSQL,
+		toCompanyID,
+		time.Now(),
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company documents exec context error",
+		)
+	}
+
+	return nil
+}
+
+// Save inserts a new document. Uploaded documents are immutable; a new
+// version of a file is uploaded as a separate document.
+func (r *AppCompanyDocumentRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	document *entities.CompanyDocument,
+) error {
+
+	document.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		saveCompanyDocumentSQL,
+		document.CompanyID,
+		document.FileName,
+		document.ContentType,
+		document.SizeBytes,
+		document.SHA256,
+		document.StorageKey,
+		document.UploadedBy,
+		document.CreatedAt,
+		document.UpdatedAt,
+	).Scan(
+		&document.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save company document query row error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyDocumentRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CompanyDocument, error) {
+
+	var document entities.CompanyDocument
+
+	err := rowScanner.Scan(
+		&document.ID,
+		&document.CompanyID,
+		&document.FileName,
+		&document.ContentType,
+		&document.SizeBytes,
+		&document.SHA256,
+		&document.StorageKey,
+		&document.UploadedBy,
+		&document.CreatedAt,
+		&document.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyDocument{}, utils.NewError(
+			err,
+			"scan company document row error",
+		)
+	}
+
+	return &document, nil
+}
@@ -0,0 +1,240 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteCompanyIdentifierSQL              = "DELETE FROM company_identifiers WHERE id = $1"
+	getCompanyIdentifiersSQL                = "SELECT id, company_id, identifier_type, country_code, value, created_at, updated_at FROM company_identifiers"
+	getCompanyIdentifierByCompanyAndIDSQL   = getCompanyIdentifiersSQL + " WHERE company_id = $1 AND id = $2"
+	getCompanyIdentifierByCompanyAndTypeSQL = getCompanyIdentifiersSQL + " WHERE company_id = $1 AND identifier_type = $2 AND country_code = $3"
+	getCompanyIdentifierByValueSQL          = getCompanyIdentifiersSQL + " WHERE identifier_type = $1 AND country_code = $2 AND value = $3"
+	getCompanyIdentifiersByCompanyIDSQL     = getCompanyIdentifiersSQL + " WHERE company_id = $1 ORDER BY identifier_type ASC, country_code ASC"
+	reassignCompanyIdentifiersSQL           = "UPDATE company_identifiers ci SET company_id = $1, updated_at = $2 WHERE company_id = $3 AND NOT EXISTS (SELECT 1 FROM company_identifiers si WHERE si.company_id = $1 AND si.identifier_type = ci.identifier_type AND si.country_code = ci.country_code)"
+	saveCompanyIdentifierSQL                = "INSERT INTO company_identifiers (company_id, identifier_type, country_code, value, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+)
+
+type (
+	CompanyIdentifierRepository interface {
+		CompanyIdentifierByCompanyAndID(ctx context.Context, operations db.SQLOperations, companyID, identifierID int64) (*entities.CompanyIdentifier, error)
+		CompanyIdentifierByCompanyAndType(ctx context.Context, operations db.SQLOperations, companyID int64, identifierType entities.IdentifierType, countryCode string) (*entities.CompanyIdentifier, error)
+		CompanyIdentifierByValue(ctx context.Context, operations db.SQLOperations, identifierType entities.IdentifierType, countryCode, value string) (*entities.CompanyIdentifier, error)
+		CompanyIdentifiersByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyIdentifier, error)
+		DeleteCompanyIdentifier(ctx context.Context, operations db.SQLOperations, identifierID int64) error
+		ReassignCompanyIdentifiers(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, identifier *entities.CompanyIdentifier) error
+	}
+
+	AppCompanyIdentifierRepository struct{}
+)
+
+func NewCompanyIdentifierRepository() *AppCompanyIdentifierRepository {
+	return &AppCompanyIdentifierRepository{}
+}
+
+func (r *AppCompanyIdentifierRepository) CompanyIdentifierByCompanyAndID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	identifierID int64,
+) (*entities.CompanyIdentifier, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyIdentifierByCompanyAndIDSQL,
+		companyID,
+		identifierID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyIdentifierRepository) CompanyIdentifierByCompanyAndType(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+	identifierType entities.IdentifierType,
+	countryCode string,
+) (*entities.CompanyIdentifier, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyIdentifierByCompanyAndTypeSQL,
+		companyID,
+		identifierType,
+		countryCode,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyIdentifierRepository) CompanyIdentifierByValue(
+	ctx context.Context,
+	operations db.SQLOperations,
+	identifierType entities.IdentifierType,
+	countryCode,
+	value string,
+) (*entities.CompanyIdentifier, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCompanyIdentifierByValueSQL,
+		identifierType,
+		countryCode,
+		value,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCompanyIdentifierRepository) CompanyIdentifiersByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyIdentifier, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyIdentifiersByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyIdentifier{}, utils.NewError(
+			err,
+			"company identifiers by company id query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	identifiers := make([]*entities.CompanyIdentifier, 0)
+
+	for rows.Next() {
+
+		identifier, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CompanyIdentifier{}, err
+		}
+
+		identifiers = append(identifiers, identifier)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyIdentifier{}, utils.NewError(
+			rows.Err(),
+			"company identifiers by company id rows error",
+		)
+	}
+
+	return identifiers, nil
+}
+
+func (r *AppCompanyIdentifierRepository) DeleteCompanyIdentifier(
+	ctx context.Context,
+	operations db.SQLOperations,
+	identifierID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCompanyIdentifierSQL,
+		identifierID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company identifier exec context error",
+		)
+	}
+
+	return nil
+}
+
+// ReassignCompanyIdentifiers moves identifiers to another company unless it
+// already holds one of the same type for the same country.
+func (r *AppCompanyIdentifierRepository) ReassignCompanyIdentifiers(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyIdentifiersSQL,
+		toCompanyID,
+		time.Now(),
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company identifiers exec context error",
+		)
+	}
+
+	return nil
+}
+
+// Save inserts a new identifier. Identifiers are not edited in place; a
+// corrected identifier replaces the old one.
+func (r *AppCompanyIdentifierRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	identifier *entities.CompanyIdentifier,
+) error {
+
+	identifier.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		saveCompanyIdentifierSQL,
+		identifier.CompanyID,
+		identifier.IdentifierType,
+		identifier.CountryCode,
+		identifier.Value,
+		identifier.CreatedAt,
+		identifier.UpdatedAt,
+	).Scan(
+		&identifier.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save company identifier query row error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyIdentifierRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CompanyIdentifier, error) {
+
+	var identifier entities.CompanyIdentifier
+
+	err := rowScanner.Scan(
+		&identifier.ID,
+		&identifier.CompanyID,
+		&identifier.IdentifierType,
+		&identifier.CountryCode,
+		&identifier.Value,
+		&identifier.CreatedAt,
+		&identifier.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, utils.NewError(
+			err,
+			"scan company identifier row error",
+		)
+	}
+
+	return &identifier, nil
+}
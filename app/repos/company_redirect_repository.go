@@ -0,0 +1,112 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	getCompanyRedirectByMergedCompanyIDSQL = "SELECT merged_company_id, company_id, actor_id, created_at FROM company_redirects WHERE merged_company_id = $1"
+	reassignCompanyRedirectsSQL            = "UPDATE company_redirects SET company_id = $1 WHERE company_id = $2"
+	saveCompanyRedirectSQL                 = "INSERT INTO company_redirects (merged_company_id, company_id, actor_id, created_at) VALUES ($1, $2, $3, $4)"
+)
+
+type (
+	CompanyRedirectRepository interface {
+		CompanyRedirectByMergedCompanyID(ctx context.Context, operations db.SQLOperations, mergedCompanyID int64) (*entities.CompanyRedirect, error)
+		ReassignCompanyRedirects(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, redirect *entities.CompanyRedirect) error
+	}
+
+	AppCompanyRedirectRepository struct{}
+)
+
+func NewCompanyRedirectRepository() *AppCompanyRedirectRepository {
+	return &AppCompanyRedirectRepository{}
+}
+
+func (r *AppCompanyRedirectRepository) CompanyRedirectByMergedCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	mergedCompanyID int64,
+) (*entities.CompanyRedirect, error) {
+
+	var redirect entities.CompanyRedirect
+
+	err := operations.QueryRowContext(
+		ctx,
+		getCompanyRedirectByMergedCompanyIDSQL,
+		mergedCompanyID,
+	).Scan(
+		&redirect.MergedCompanyID,
+		&redirect.CompanyID,
+		&redirect.ActorID,
+		&redirect.CreatedAt,
+	)
+	if err != nil {
+		return &entities.CompanyRedirect{}, utils.NewError(
+			err,
+			"company redirect by merged company id = [%v] query row error",
+			mergedCompanyID,
+		)
+	}
+
+	return &redirect, nil
+}
+
+// ReassignCompanyRedirects points existing redirects at a new company so
+// that chains of merges always resolve in a single hop.
+func (r *AppCompanyRedirectRepository) ReassignCompanyRedirects(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyRedirectsSQL,
+		toCompanyID,
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company redirects exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRedirectRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	redirect *entities.CompanyRedirect,
+) error {
+
+	if redirect.CreatedAt.IsZero() {
+		redirect.CreatedAt = time.Now()
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		saveCompanyRedirectSQL,
+		redirect.MergedCompanyID,
+		redirect.CompanyID,
+		redirect.ActorID,
+		redirect.CreatedAt,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save company redirect exec context error",
+		)
+	}
+
+	return nil
+}
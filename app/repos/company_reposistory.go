@@ -2,39 +2,96 @@ package repos
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
 const (
-	deleteCompanySQL           = "DELETE FROM companies WHERE id = $1"
-	getCompaniesSQL            = "SELECT co.id, co.name, co.code, c.name, co.website, co.country_code, co.number, cc.operation_status, co.created_at, co.updated_at FROM companies co JOIN company_countries cc ON cc.company_id = co.id JOIN countries c ON c.id = cc.country_id"
-	getCompanyByCodeSQL        = getCompaniesSQL + " WHERE co.code = $1"
-	getCompanyByIDSQL          = getCompaniesSQL + " WHERE co.id = $1"
-	getCompanyByNameSQL        = getCompaniesSQL + " WHERE co.name = $1"
-	getCompanyByPhoneNumberSQL = getCompaniesSQL + " WHERE co.country_code = $1 AND co.number = $2"
-	getCompanyByWebsiteSQL     = getCompaniesSQL + " WHERE co.website = $1"
-	getCompanyCountSQL         = "SELECT COUNT(co.id) FROM companies co JOIN company_countries cc ON cc.company_id = co.id JOIN countries c ON c.id = cc.country_id"
-	saveCompanySQL             = "INSERT INTO companies (name, code, website, country_code, number, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
-	updateCompanySQL           = "UPDATE companies SET name = $1, code = $2, website = $3, country_code = $4, number = $5, updated_at = $6 WHERE id = $7"
+	deleteCompanySQL            = "UPDATE companies SET deleted_at = $1, updated_at = $2, version = version + 1 WHERE id = $3 AND version = $4 AND deleted_at IS NULL"
+	companyColumnsSQL           = "co.id, co.name, co.code, c.name, co.website, co.website_domain, co.country_code, co.number, co.operation_status, co.parent_company_id, co.custom_fields, co.deleted_at, co.version, co.created_at, co.updated_at"
+	getCompaniesSQL             = "SELECT " + companyColumnsSQL + " FROM companies co " + primaryCountryJoinSQL
+	getCompaniesToBackfillSQL   = getCompaniesSQL + " WHERE co.id > $1 AND co.website <> '' AND co.website_domain = '' AND NOT EXISTS (SELECT 1 FROM company_website_domain_conflicts wdc WHERE wdc.company_id = co.id) ORDER BY co.id ASC LIMIT $2"
+	getCompanyByCodeSQL         = getCompaniesSQL + " WHERE co.code = $1 AND co.deleted_at IS NULL"
+	getCompanyByIDSQL           = getCompaniesSQL + " WHERE co.id = $1 AND co.deleted_at IS NULL"
+	getCompanyByNameSQL         = getCompaniesSQL + " WHERE co.name = $1 AND co.deleted_at IS NULL"
+	getCompanyByPhoneNumberSQL  = getCompaniesSQL + " WHERE co.country_code = $1 AND co.number = $2 AND co.deleted_at IS NULL"
+	getCompanyByWebsiteSQL      = getCompaniesSQL + " WHERE co.website_domain = $1 AND co.deleted_at IS NULL"
+	getCompanyCountSQL          = "SELECT COUNT(co.id) FROM companies co " + primaryCountryJoinSQL
+	getDuplicateCompaniesSQL    = "SELECT " + companyColumnsSQL + ", " + duplicateNameMatchSQL + ", " + duplicateDomainMatchSQL + ", " + duplicatePhoneMatchSQL + " FROM companies co CROSS JOIN (SELECT name, website, country_code, number FROM companies WHERE id = $1) src " + primaryCountryJoinSQL + " WHERE co.id <> $1 AND co.deleted_at IS NULL AND (" + duplicateNameMatchSQL + " OR " + duplicateDomainMatchSQL + " OR " + duplicatePhoneMatchSQL + ") ORDER BY co.id ASC"
+	getCompanyTreeSQL           = "WITH RECURSIVE ancestors AS (SELECT id, parent_company_id FROM companies WHERE id = $1 AND deleted_at IS NULL UNION SELECT p.id, p.parent_company_id FROM companies p JOIN ancestors a ON p.id = a.parent_company_id WHERE p.deleted_at IS NULL), tree AS (SELECT a.id, 0 AS depth, ARRAY[a.id] AS path FROM ancestors a WHERE NOT EXISTS (SELECT 1 FROM ancestors x WHERE x.id = a.parent_company_id) UNION ALL SELECT s.id, t.depth + 1, t.path || s.id FROM companies s JOIN tree t ON s.parent_company_id = t.id WHERE s.deleted_at IS NULL AND NOT s.id = ANY(t.path)) SELECT " + companyColumnsSQL + ", t.depth FROM tree t JOIN companies co ON co.id = t.id " + primaryCountryJoinSQL + " ORDER BY t.path"
+	getDescendantCompaniesSQL   = "WITH RECURSIVE descendants AS (SELECT id, ARRAY[id] AS path FROM companies WHERE parent_company_id = $1 AND deleted_at IS NULL UNION ALL SELECT s.id, d.path || s.id FROM companies s JOIN descendants d ON s.parent_company_id = d.id WHERE s.deleted_at IS NULL AND NOT s.id = ANY(d.path)) SELECT " + companyColumnsSQL + " FROM descendants d JOIN companies co ON co.id = d.id " + primaryCountryJoinSQL + " ORDER BY d.path"
+	getDeletedCompanyByIDSQL    = getCompaniesSQL + " WHERE co.id = $1 AND co.deleted_at IS NOT NULL"
+	isCompanyAncestorSQL        = "WITH RECURSIVE ancestors AS (SELECT id, parent_company_id FROM companies WHERE id = $2 UNION SELECT p.id, p.parent_company_id FROM companies p JOIN ancestors a ON p.id = a.parent_company_id) SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $1)"
+	lockCompanySQL              = "SELECT id FROM companies WHERE id = $1 FOR UPDATE"
+	lockCompanyHierarchySQL     = "SELECT pg_advisory_xact_lock(hashtext('companies.parent_company_id'))"
+	duplicateDomainMatchSQL     = "(company_domain(co.website) <> '' AND company_domain(co.website) = company_domain(src.website))"
+	duplicateNameMatchSQL       = "(normalize_company_name(co.name) <> '' AND normalize_company_name(co.name) = normalize_company_name(src.name))"
+	duplicatePhoneMatchSQL      = "(company_phone(co.country_code, co.number) <> '' AND company_phone(co.country_code, co.number) = company_phone(src.country_code, src.number))"
+	primaryCountryJoinSQL       = "JOIN LATERAL (SELECT country_id FROM company_countries WHERE company_id = co.id ORDER BY id ASC LIMIT 1) cc ON TRUE JOIN countries c ON c.id = cc.country_id"
+	purgeCompanySQL             = "DELETE FROM companies WHERE id = $1"
+	recordDomainConflictSQL     = "INSERT INTO company_website_domain_conflicts (company_id, kept_company_id, website_domain) VALUES ($1, $2, $3)"
+	removeCompanyCustomFieldSQL = "UPDATE companies SET custom_fields = custom_fields - $1, updated_at = $2, version = version + 1 WHERE custom_fields ? $1"
+	reassignSubsidiariesSQL     = "UPDATE companies SET parent_company_id = $1, updated_at = $2, version = version + 1 WHERE parent_company_id = $3"
+	purgeDeletedCompaniesSQL    = "DELETE FROM companies WHERE deleted_at IS NOT NULL AND deleted_at < $1"
+	restoreCompanySQL           = "UPDATE companies SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2 RETURNING version"
+	setWebsiteDomainSQL         = "UPDATE companies SET website_domain = $1 WHERE id = $2"
+	// searchCompaniesSQL ranks full text matches together with trigram
+	// similarity so misspelt terms still match. Highlights are delimited with
+	// private use characters and turned into markup by the caller.
+	searchCompaniesSQL = "WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS tsq, LOWER($1) AS term) SELECT " + companyColumnsSQL + ", ts_rank(co.search_vector, q.tsq) + GREATEST(similarity(LOWER(co.name), q.term), similarity(LOWER(co.code), q.term), similarity(LOWER(co.website), q.term)) AS score, ts_headline('simple', co.name, q.tsq, $4), ts_headline('simple', co.code, q.tsq, $4), ts_headline('simple', co.website, q.tsq, $4) FROM companies co CROSS JOIN q " + primaryCountryJoinSQL + " WHERE co.deleted_at IS NULL AND (co.search_vector @@ q.tsq OR LOWER(co.name) % q.term OR LOWER(co.code) % q.term OR LOWER(co.website) % q.term) ORDER BY score DESC, co.id ASC LIMIT $2 OFFSET $3"
+	saveCompanySQL     = "INSERT INTO companies (name, code, website, website_domain, country_code, number, operation_status, parent_company_id, custom_fields, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, version"
+	updateCompanySQL   = "UPDATE companies SET name = $1, code = $2, website = $3, website_domain = $4, country_code = $5, number = $6, operation_status = $7, parent_company_id = $8, custom_fields = $9, updated_at = $10, version = version + 1 WHERE id = $11 AND version = $12 RETURNING version"
 )
 
+// companySortColumns maps the sortable fields in forms.CompanySortFields to
+// their columns.
+var companySortColumns = map[string]string{
+	"code":       "co.code",
+	"country":    "c.name",
+	"created_at": "co.created_at",
+	"name":       "co.name",
+	"updated_at": "co.updated_at",
+}
+
 type (
 	CompanyRepository interface {
+		CompaniesWithoutWebsiteDomain(ctx context.Context, operations db.SQLOperations, afterID int64, limit int) ([]*entities.Company, error)
 		CompanyByCode(ctx context.Context, operations db.SQLOperations, code string) (*entities.Company, error)
 		CompanyByID(ctx context.Context, operations db.SQLOperations, companyID int64) (*entities.Company, error)
 		CompanyByName(ctx context.Context, operations db.SQLOperations, companyName string) (*entities.Company, error)
 		CompanyByPhoneNumber(ctx context.Context, operations db.SQLOperations, phoneNumber entities.PhoneNumber) (*entities.Company, error)
-		CompanyByWebsite(ctx context.Context, operations db.SQLOperations, website string) (*entities.Company, error)
+		CompanyByWebsite(ctx context.Context, operations db.SQLOperations, websiteDomain string) (*entities.Company, error)
 		CompanyCount(ctx context.Context, operations db.SQLOperations, filter *forms.Filter) (int, error)
-		DeleteCompany(ctx context.Context, operations db.SQLOperations, companyID int64) error
+		CompanyTree(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyNode, error)
+		DeleteCompany(ctx context.Context, operations db.SQLOperations, companyID, version int64) error
+		DeletedCompanyByID(ctx context.Context, operations db.SQLOperations, companyID int64) (*entities.Company, error)
+		DescendantCompanies(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.Company, error)
+		DuplicateCompanies(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyDuplicate, error)
+		IsCompanyAncestor(ctx context.Context, operations db.SQLOperations, ancestorID, companyID int64) (bool, error)
 		ListCompanies(ctx context.Context, operations db.SQLOperations, filter *forms.Filter) ([]*entities.Company, error)
+		LockCompany(ctx context.Context, operations db.SQLOperations, companyID int64) error
+		LockCompanyHierarchy(ctx context.Context, operations db.SQLOperations) error
+		PurgeCompany(ctx context.Context, operations db.SQLOperations, companyID int64) error
+		PurgeDeletedCompanies(ctx context.Context, operations db.SQLOperations, deletedBefore time.Time) (int64, error)
+		ReassignSubsidiaries(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		RecordWebsiteDomainConflict(ctx context.Context, operations db.SQLOperations, companyID, keptCompanyID int64, websiteDomain string) error
+		RemoveCompanyCustomField(ctx context.Context, operations db.SQLOperations, key string) error
+		RestoreCompany(ctx context.Context, operations db.SQLOperations, company *entities.Company) error
+		SearchCompanies(ctx context.Context, operations db.SQLOperations, filter *forms.SearchFilter) ([]*entities.CompanySearchResult, error)
 		Save(ctx context.Context, operations db.SQLOperations, company *entities.Company) error
+		SetWebsiteDomain(ctx context.Context, operations db.SQLOperations, companyID int64, websiteDomain string) error
+		StreamCompanies(ctx context.Context, operations db.SQLOperations, filter *forms.Filter, fn func(*entities.Company) error) error
 	}
 
 	AppCompanyRepository struct{}
@@ -44,6 +101,53 @@ func NewCompanyRepository() *AppCompanyRepository {
 	return &AppCompanyRepository{}
 }
 
+// CompaniesWithoutWebsiteDomain lists live and deleted companies, after
+// afterID, whose website domain has not been derived yet. Companies with a
+// recorded website domain conflict are left out.
+func (r *AppCompanyRepository) CompaniesWithoutWebsiteDomain(
+	ctx context.Context,
+	operations db.SQLOperations,
+	afterID int64,
+	limit int,
+) ([]*entities.Company, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompaniesToBackfillSQL,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		return []*entities.Company{}, utils.NewError(
+			err,
+			"companies without website domain query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	companies := make([]*entities.Company, 0)
+
+	for rows.Next() {
+
+		company, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.Company{}, err
+		}
+
+		companies = append(companies, company)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.Company{}, utils.NewError(
+			rows.Err(),
+			"companies without website domain rows error",
+		)
+	}
+
+	return companies, nil
+}
+
 func (r *AppCompanyRepository) CompanyByCode(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -105,16 +209,18 @@ func (r *AppCompanyRepository) CompanyByPhoneNumber(
 	return r.scanRow(row)
 }
 
+// CompanyByWebsite looks up a live company by the canonical domain of its
+// website, see utils.NormalizeWebsite.
 func (r *AppCompanyRepository) CompanyByWebsite(
 	ctx context.Context,
 	operations db.SQLOperations,
-	website string,
+	websiteDomain string,
 ) (*entities.Company, error) {
 
 	row := operations.QueryRowContext(
 		ctx,
 		getCompanyByWebsiteSQL,
-		website,
+		websiteDomain,
 	)
 
 	return r.scanRow(row)
@@ -145,16 +251,72 @@ func (r *AppCompanyRepository) CompanyCount(
 	return count, nil
 }
 
-func (r *AppCompanyRepository) DeleteCompany(
+// CompanyTree returns the whole group the company belongs to, starting at
+// its top most live parent. Companies are ordered depth first.
+func (r *AppCompanyRepository) CompanyTree(
 	ctx context.Context,
 	operations db.SQLOperations,
 	companyID int64,
+) ([]*entities.CompanyNode, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyTreeSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyNode{}, utils.NewError(
+			err,
+			"company tree query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	nodes := make([]*entities.CompanyNode, 0)
+
+	for rows.Next() {
+
+		node := &entities.CompanyNode{
+			Subsidiaries: make([]*entities.CompanyNode, 0),
+		}
+
+		node.Company, err = r.scanRow(rows, &node.Depth)
+		if err != nil {
+			return []*entities.CompanyNode{}, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyNode{}, utils.NewError(
+			rows.Err(),
+			"company tree rows error",
+		)
+	}
+
+	return nodes, nil
+}
+
+// DeleteCompany soft deletes the company if it is still at version, so that
+// concurrent changes are not silently discarded.
+func (r *AppCompanyRepository) DeleteCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	version int64,
 ) error {
 
-	_, err := operations.ExecContext(
+	deletedAt := time.Now()
+
+	result, err := operations.ExecContext(
 		ctx,
 		deleteCompanySQL,
+		deletedAt,
+		deletedAt,
 		companyID,
+		version,
 	)
 	if err != nil {
 		return utils.NewError(
@@ -163,9 +325,176 @@ func (r *AppCompanyRepository) DeleteCompany(
 		)
 	}
 
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete company rows affected error",
+		)
+	}
+
+	if deleted == 0 {
+		return utils.NewErrorWithCode(
+			errors.New("company version conflict"),
+			utils.ErrorCodePreconditionFailed,
+			"company id = %v version = %v was modified concurrently",
+			companyID,
+			version,
+		)
+	}
+
 	return nil
 }
 
+func (r *AppCompanyRepository) DeletedCompanyByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) (*entities.Company, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getDeletedCompanyByIDSQL,
+		companyID,
+	)
+
+	return r.scanRow(row)
+}
+
+// DescendantCompanies returns the live subsidiaries of a company at every
+// level below it.
+func (r *AppCompanyRepository) DescendantCompanies(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.Company, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getDescendantCompaniesSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.Company{}, utils.NewError(
+			err,
+			"descendant companies query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	companies := make([]*entities.Company, 0)
+
+	for rows.Next() {
+
+		company, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.Company{}, err
+		}
+
+		companies = append(companies, company)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.Company{}, utils.NewError(
+			rows.Err(),
+			"descendant companies rows error",
+		)
+	}
+
+	return companies, nil
+}
+
+// DuplicateCompanies finds live companies sharing a normalized name, domain
+// or phone number with the given company.
+func (r *AppCompanyRepository) DuplicateCompanies(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyDuplicate, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getDuplicateCompaniesSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyDuplicate{}, utils.NewError(
+			err,
+			"duplicate companies query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	duplicates := make([]*entities.CompanyDuplicate, 0)
+
+	for rows.Next() {
+
+		var nameMatch, domainMatch, phoneMatch bool
+
+		company, err := r.scanRow(rows, &nameMatch, &domainMatch, &phoneMatch)
+		if err != nil {
+			return []*entities.CompanyDuplicate{}, err
+		}
+
+		duplicate := &entities.CompanyDuplicate{
+			Company:   company,
+			MatchedOn: make([]string, 0),
+		}
+
+		if nameMatch {
+			duplicate.MatchedOn = append(duplicate.MatchedOn, entities.DuplicateMatchName)
+		}
+
+		if domainMatch {
+			duplicate.MatchedOn = append(duplicate.MatchedOn, entities.DuplicateMatchDomain)
+		}
+
+		if phoneMatch {
+			duplicate.MatchedOn = append(duplicate.MatchedOn, entities.DuplicateMatchPhone)
+		}
+
+		duplicates = append(duplicates, duplicate)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyDuplicate{}, utils.NewError(
+			rows.Err(),
+			"duplicate companies rows error",
+		)
+	}
+
+	return duplicates, nil
+}
+
+// IsCompanyAncestor reports whether ancestorID is companyID itself or one of
+// its parents at any level.
+func (r *AppCompanyRepository) IsCompanyAncestor(
+	ctx context.Context,
+	operations db.SQLOperations,
+	ancestorID,
+	companyID int64,
+) (bool, error) {
+
+	var isAncestor bool
+
+	err := operations.QueryRowContext(
+		ctx,
+		isCompanyAncestorSQL,
+		ancestorID,
+		companyID,
+	).Scan(&isAncestor)
+	if err != nil {
+		return false, utils.NewError(
+			err,
+			"is company ancestor query row error",
+		)
+	}
+
+	return isAncestor, nil
+}
+
 func (r *AppCompanyRepository) ListCompanies(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -206,6 +535,207 @@ func (r *AppCompanyRepository) ListCompanies(
 	return companies, nil
 }
 
+// LockCompany locks the company row for the rest of the transaction so
+// concurrent changes to what the company owns are serialized.
+func (r *AppCompanyRepository) LockCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) error {
+
+	var id int64
+
+	err := operations.QueryRowContext(
+		ctx,
+		lockCompanySQL,
+		companyID,
+	).Scan(&id)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"lock company id = %v query row error",
+			companyID,
+		)
+	}
+
+	return nil
+}
+
+// LockCompanyHierarchy serializes changes to parent companies for the rest
+// of the transaction so concurrent moves cannot form a cycle.
+func (r *AppCompanyRepository) LockCompanyHierarchy(
+	ctx context.Context,
+	operations db.SQLOperations,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		lockCompanyHierarchySQL,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"lock company hierarchy exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRepository) PurgeCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		purgeCompanySQL,
+		companyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"purge company exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRepository) PurgeDeletedCompanies(
+	ctx context.Context,
+	operations db.SQLOperations,
+	deletedBefore time.Time,
+) (int64, error) {
+
+	result, err := operations.ExecContext(
+		ctx,
+		purgeDeletedCompaniesSQL,
+		deletedBefore,
+	)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"purge deleted companies exec context error",
+		)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"purge deleted companies rows affected error",
+		)
+	}
+
+	return purged, nil
+}
+
+func (r *AppCompanyRepository) ReassignSubsidiaries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignSubsidiariesSQL,
+		toCompanyID,
+		time.Now(),
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign subsidiaries exec context error",
+		)
+	}
+
+	return nil
+}
+
+// RecordWebsiteDomainConflict notes that companyID could not be given
+// websiteDomain because keptCompanyID already has it.
+func (r *AppCompanyRepository) RecordWebsiteDomainConflict(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	keptCompanyID int64,
+	websiteDomain string,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		recordDomainConflictSQL,
+		companyID,
+		keptCompanyID,
+		websiteDomain,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"record website domain conflict for company id = %v exec context error",
+			companyID,
+		)
+	}
+
+	return nil
+}
+
+// RemoveCompanyCustomField drops key from the custom fields of every company
+// carrying it.
+func (r *AppCompanyRepository) RemoveCompanyCustomField(
+	ctx context.Context,
+	operations db.SQLOperations,
+	key string,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		removeCompanyCustomFieldSQL,
+		key,
+		time.Now(),
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"remove company custom field exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRepository) RestoreCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	company *entities.Company,
+) error {
+
+	company.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		restoreCompanySQL,
+		company.UpdatedAt,
+		company.ID,
+	).Scan(
+		&company.Version,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"restore company exec context error",
+		)
+	}
+
+	company.DeletedAt = null.Time{}
+
+	return nil
+}
+
 func (r *AppCompanyRepository) Save(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -214,6 +744,18 @@ func (r *AppCompanyRepository) Save(
 
 	company.Touch()
 
+	if company.CustomFields == nil {
+		company.CustomFields = map[string]interface{}{}
+	}
+
+	customFields, err := json.Marshal(company.CustomFields)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"marshal company custom fields error",
+		)
+	}
+
 	if company.IsNew() {
 
 		err := operations.QueryRowContext(
@@ -222,12 +764,17 @@ func (r *AppCompanyRepository) Save(
 			company.Name,
 			company.Code,
 			company.Website,
+			company.WebsiteDomain,
 			company.PhoneNumber.CountryCode,
 			company.PhoneNumber.Number,
+			company.OperationStatus,
+			company.ParentCompanyID,
+			customFields,
 			company.CreatedAt,
 			company.UpdatedAt,
 		).Scan(
 			&company.ID,
+			&company.Version,
 		)
 		if err != nil {
 			return utils.NewError(
@@ -239,21 +786,162 @@ func (r *AppCompanyRepository) Save(
 		return nil
 	}
 
-	_, err := operations.ExecContext(
+	err = operations.QueryRowContext(
 		ctx,
 		updateCompanySQL,
 		company.Name,
 		company.Code,
 		company.Website,
+		company.WebsiteDomain,
 		company.PhoneNumber.CountryCode,
 		company.PhoneNumber.Number,
+		company.OperationStatus,
+		company.ParentCompanyID,
+		customFields,
 		company.UpdatedAt,
 		company.ID,
+		company.Version,
+	).Scan(
+		&company.Version,
 	)
 	if err != nil {
+		if utils.IsErrNoRows(err) {
+			return utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodePreconditionFailed,
+				"company id = %v version = %v was modified concurrently",
+				company.ID,
+				company.Version,
+			)
+		}
+
 		return utils.NewError(
 			err,
-			"update company exec error",
+			"update company query row error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRepository) SearchCompanies(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.SearchFilter,
+) ([]*entities.CompanySearchResult, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		searchCompaniesSQL,
+		filter.Query,
+		filter.Per+1,
+		(filter.Page-1)*filter.Per,
+		fmt.Sprintf(`StartSel="%v", StopSel="%v", HighlightAll=true`, utils.HighlightStart, utils.HighlightStop),
+	)
+	if err != nil {
+		return []*entities.CompanySearchResult{}, utils.NewError(
+			err,
+			"search companies query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	results := make([]*entities.CompanySearchResult, 0)
+
+	for rows.Next() {
+
+		var result entities.CompanySearchResult
+		var nameHighlight, codeHighlight, websiteHighlight string
+
+		result.Company, err = r.scanRow(rows, &result.Score, &nameHighlight, &codeHighlight, &websiteHighlight)
+		if err != nil {
+			return []*entities.CompanySearchResult{}, err
+		}
+
+		result.Highlights = utils.Highlights(map[string]string{
+			"code":    codeHighlight,
+			"name":    nameHighlight,
+			"website": websiteHighlight,
+		})
+
+		results = append(results, &result)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanySearchResult{}, utils.NewError(
+			rows.Err(),
+			"search companies rows error",
+		)
+	}
+
+	return results, nil
+}
+
+// StreamCompanies calls fn for every company matching filter, one row at a
+// time, ignoring pagination.
+// SetWebsiteDomain stores the canonical domain derived from the website of
+// the company. It leaves the version alone since the company is not changed.
+func (r *AppCompanyRepository) SetWebsiteDomain(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+	websiteDomain string,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		setWebsiteDomainSQL,
+		websiteDomain,
+		companyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"set website domain for company id = %v exec context error",
+			companyID,
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyRepository) StreamCompanies(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.Filter,
+	fn func(*entities.Company) error,
+) error {
+
+	query, args := r.buildQuery(getCompaniesSQL, filter.NoPagination())
+
+	rows, err := operations.QueryContext(ctx, query+r.buildOrderBy(filter), args...)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"stream companies query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		company, err := r.scanRow(rows)
+		if err != nil {
+			return err
+		}
+
+		err = fn(company)
+		if err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return utils.NewError(
+			rows.Err(),
+			"stream companies rows error",
 		)
 	}
 
@@ -270,7 +958,7 @@ func (r *AppCompanyRepository) buildQuery(
 	counter := utils.NewPlaceholder()
 
 	if filter.Term != "" {
-		filterColumns := []string{"co.name", "co.code", "co.website", "c.name", "CONCAT(co.country_code, co.number)"}
+		filterColumns := []string{"co.name", "co.code", "co.website", "CONCAT(co.country_code, co.number)"}
 		likeStatements := make([]string, 0)
 
 		args = append(args, strings.ToLower(filter.Term))
@@ -279,45 +967,211 @@ func (r *AppCompanyRepository) buildQuery(
 			stmt := fmt.Sprintf("LOWER(%s) LIKE '%%' || $%d || '%%'", col, termPlaceholder)
 			likeStatements = append(likeStatements, stmt)
 		}
+
+		countryStmt := fmt.Sprintf("EXISTS (SELECT 1 FROM company_countries tcc JOIN countries tc ON tc.id = tcc.country_id WHERE tcc.company_id = co.id AND LOWER(tc.name) LIKE '%%' || $%d || '%%')", termPlaceholder)
+		likeStatements = append(likeStatements, countryStmt)
 		condition := fmt.Sprintf(" (%s)", strings.Join(likeStatements, " OR "))
 		conditions = append(conditions, condition)
 	}
 
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, " co.deleted_at IS NULL")
+	}
+
 	if filter.Status != "" {
-		conditions = append(conditions, fmt.Sprintf(" cc.operation_status = $%d", counter.Touch()))
+		conditions = append(conditions, fmt.Sprintf(" co.operation_status = $%d", counter.Touch()))
 		args = append(args, filter.Status)
 	}
 
+	if filter.Code != "" {
+		conditions = append(conditions, fmt.Sprintf(" co.code = $%d", counter.Touch()))
+		args = append(args, filter.Code)
+	}
+
+	if filter.ParentID > 0 {
+		conditions = append(conditions, fmt.Sprintf(" co.parent_company_id = $%d", counter.Touch()))
+		args = append(args, filter.ParentID)
+	}
+
+	if filter.Identifier != "" {
+		conditions = append(conditions, fmt.Sprintf(" EXISTS (SELECT 1 FROM company_identifiers ci WHERE ci.company_id = co.id AND ci.identifier_type = $%d AND ci.value = $%d)", counter.Touch(), counter.Touch()))
+		args = append(args, filter.IdentifierType, filter.Identifier)
+	}
+
+	if len(filter.Tags) > 0 {
+		tagsStmt := fmt.Sprintf("SELECT 1 FROM company_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.company_id = co.id AND LOWER(t.name) = ANY($%d)", counter.Touch())
+		args = append(args, pq.Array(filter.Tags))
+
+		if filter.MatchAllTags {
+			conditions = append(conditions, fmt.Sprintf(" (SELECT COUNT(1) FROM (%s) fct) = $%d", tagsStmt, counter.Touch()))
+			args = append(args, len(filter.Tags))
+		} else {
+			conditions = append(conditions, fmt.Sprintf(" EXISTS (%s)", tagsStmt))
+		}
+	}
+
+	customFieldKeys := make([]string, 0, len(filter.CustomFields))
+	for key := range filter.CustomFields {
+		customFieldKeys = append(customFieldKeys, key)
+	}
+
+	sort.Strings(customFieldKeys)
+
+	for _, key := range customFieldKeys {
+		conditions = append(conditions, fmt.Sprintf(" co.custom_fields ->> $%d = $%d", counter.Touch(), counter.Touch()))
+		args = append(args, key, filter.CustomFields[key])
+	}
+
+	if filter.Country != "" {
+		countryPlaceholder := counter.Touch()
+		conditions = append(conditions, fmt.Sprintf(" EXISTS (SELECT 1 FROM company_countries fcc JOIN countries fc ON fc.id = fcc.country_id WHERE fcc.company_id = co.id AND (LOWER(fc.name) = LOWER($%d) OR LOWER(fc.code) = LOWER($%d)))", countryPlaceholder, countryPlaceholder))
+		args = append(args, filter.Country)
+	}
+
+	if filter.CreatedAfter.Valid {
+		conditions = append(conditions, fmt.Sprintf(" co.created_at > $%d", counter.Touch()))
+		args = append(args, filter.CreatedAfter.Time)
+	}
+
+	if filter.CreatedBefore.Valid {
+		conditions = append(conditions, fmt.Sprintf(" co.created_at < $%d", counter.Touch()))
+		args = append(args, filter.CreatedBefore.Time)
+	}
+
+	if filter.UpdatedAfter.Valid {
+		conditions = append(conditions, fmt.Sprintf(" co.updated_at > $%d", counter.Touch()))
+		args = append(args, filter.UpdatedAfter.Time)
+	}
+
+	if filter.PhoneCountryCode != "" {
+		conditions = append(conditions, fmt.Sprintf(" co.country_code = $%d", counter.Touch()))
+		args = append(args, filter.PhoneCountryCode)
+	}
+
+	if filter.Cursor && filter.After != nil {
+		condition, cursorArgs := r.buildCursorCondition(filter, counter.Touch)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+
 	if len(conditions) > 0 {
 		query += " WHERE" + strings.Join(conditions, " AND ")
 	}
 
-	if filter.Page > 0 && filter.Per > 0 {
-		query += fmt.Sprintf(" ORDER BY co.name ASC LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
-		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	limit := filter.Per
+	if filter.HasLookahead() {
+		limit++
+	}
+
+	if filter.Cursor && filter.Per > 0 {
+		query += r.buildOrderBy(filter) + fmt.Sprintf(" LIMIT $%d", counter.Touch())
+		args = append(args, limit)
+	} else if filter.Page > 0 && filter.Per > 0 {
+		query += r.buildOrderBy(filter) + fmt.Sprintf(" LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, limit, (filter.Page-1)*filter.Per)
 	}
 
 	return query, args
 }
 
+func (r *AppCompanyRepository) buildOrderBy(
+	filter *forms.Filter,
+) string {
+
+	orderBy := make([]string, 0, len(filter.SortFields())+1)
+
+	for _, sortField := range filter.SortFields() {
+
+		direction := "ASC"
+		if sortField.Descending {
+			direction = "DESC"
+		}
+
+		orderBy = append(orderBy, companySortColumns[sortField.Field]+" "+direction)
+	}
+
+	orderBy = append(orderBy, "co.id ASC")
+
+	return " ORDER BY " + strings.Join(orderBy, ", ")
+}
+
+// buildCursorCondition selects the rows strictly after the cursor in sort
+// order, expanding the tuple comparison so each column can carry its own
+// direction.
+func (r *AppCompanyRepository) buildCursorCondition(
+	filter *forms.Filter,
+	nextPlaceholder func() int,
+) (string, []interface{}) {
+
+	args := make([]interface{}, 0, len(filter.After.Values)+1)
+	columns := make([]string, 0, len(filter.After.Values)+1)
+	operators := make([]string, 0, len(filter.After.Values)+1)
+	placeholders := make([]int, 0, len(filter.After.Values)+1)
+
+	for i, sortField := range filter.SortFields() {
+
+		operator := ">"
+		if sortField.Descending {
+			operator = "<"
+		}
+
+		columns = append(columns, companySortColumns[sortField.Field])
+		operators = append(operators, operator)
+		placeholders = append(placeholders, nextPlaceholder())
+		args = append(args, filter.After.Values[i])
+	}
+
+	columns = append(columns, "co.id")
+	operators = append(operators, ">")
+	placeholders = append(placeholders, nextPlaceholder())
+	args = append(args, filter.After.ID)
+
+	alternatives := make([]string, 0, len(columns))
+
+	for i := range columns {
+
+		terms := make([]string, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			terms = append(terms, fmt.Sprintf("%s = $%d", columns[j], placeholders[j]))
+		}
+
+		terms = append(terms, fmt.Sprintf("%s %s $%d", columns[i], operators[i], placeholders[i]))
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return " (" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// scanRow scans the company columns followed by any extra selected columns.
 func (r *AppCompanyRepository) scanRow(
 	rowScanner db.RowScanner,
+	extra ...interface{},
 ) (*entities.Company, error) {
 
 	var company entities.Company
+	var customFields []byte
 
-	err := rowScanner.Scan(
+	dest := []interface{}{
 		&company.ID,
 		&company.Name,
 		&company.Code,
 		&company.Country,
 		&company.Website,
+		&company.WebsiteDomain,
 		&company.PhoneNumber.CountryCode,
 		&company.PhoneNumber.Number,
 		&company.OperationStatus,
+		&company.ParentCompanyID,
+		&customFields,
+		&company.DeletedAt,
+		&company.Version,
 		&company.CreatedAt,
 		&company.UpdatedAt,
-	)
+	}
+
+	err := rowScanner.Scan(append(dest, extra...)...)
 	if err != nil {
 		return &entities.Company{}, utils.NewError(
 			err,
@@ -325,5 +1179,13 @@ func (r *AppCompanyRepository) scanRow(
 		)
 	}
 
+	err = json.Unmarshal(customFields, &company.CustomFields)
+	if err != nil {
+		return &entities.Company{}, utils.NewError(
+			err,
+			"unmarshal company custom fields error",
+		)
+	}
+
 	return &company, nil
 }
@@ -3,6 +3,7 @@ package repos
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
@@ -93,7 +94,7 @@ func TestCompanyRepository(t *testing.T) {
 			company, _, err := CreateCompany(ctx, dB, "Trading Point LLC", country)
 			So(err, ShouldBeNil)
 
-			foundCompany, err := companyRepository.CompanyByWebsite(ctx, dB, company.Website)
+			foundCompany, err := companyRepository.CompanyByWebsite(ctx, dB, company.WebsiteDomain)
 			So(err, ShouldBeNil)
 
 			So(foundCompany.ID, ShouldEqual, company.ID)
@@ -237,6 +238,7 @@ func TestCompanyRepository(t *testing.T) {
 		Convey("can list companies based on status filter", func() {
 
 			closedCompany := entities.BuildCompany("Safari", country)
+			closedCompany.OperationStatus = entities.OperationStatusTypeClosed
 			err := companyRepository.Save(ctx, dB, closedCompany)
 			So(err, ShouldBeNil)
 
@@ -245,7 +247,6 @@ func TestCompanyRepository(t *testing.T) {
 			So(err, ShouldBeNil)
 
 			closedCyprusCompany := entities.BuildCompanyCountry(closedCompany.ID, country.ID)
-			closedCyprusCompany.OperationStatus = entities.OperationStatusTypeClosed
 			err = companyCountryRepository.Save(ctx, dB, closedCyprusCompany)
 			So(err, ShouldBeNil)
 
@@ -276,5 +277,103 @@ func TestCompanyRepository(t *testing.T) {
 
 			So(count, ShouldEqual, 2)
 		})
+
+		Convey("can soft delete and restore a company", func() {
+
+			company, _, err := CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.DeleteCompany(ctx, dB, company.ID, company.Version)
+			So(err, ShouldBeNil)
+
+			_, err = companyRepository.CompanyByID(ctx, dB, company.ID)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+
+			foundCompanies, err := companyRepository.ListCompanies(ctx, dB, &forms.Filter{})
+			So(err, ShouldBeNil)
+			So(len(foundCompanies), ShouldEqual, 0)
+
+			foundCompanies, err = companyRepository.ListCompanies(ctx, dB, &forms.Filter{IncludeDeleted: true})
+			So(err, ShouldBeNil)
+			So(len(foundCompanies), ShouldEqual, 1)
+			So(foundCompanies[0].DeletedAt.Valid, ShouldBeTrue)
+
+			deletedCompany, err := companyRepository.DeletedCompanyByID(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.RestoreCompany(ctx, dB, deletedCompany)
+			So(err, ShouldBeNil)
+
+			foundCompany, err := companyRepository.CompanyByID(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(foundCompany.DeletedAt.Valid, ShouldBeFalse)
+		})
+
+		Convey("cannot delete a company modified concurrently", func() {
+
+			company, _, err := CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			staleVersion := company.Version
+
+			err = companyRepository.Save(ctx, dB, company)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.DeleteCompany(ctx, dB, company.ID, staleVersion)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodePreconditionFailed)
+
+			_, err = companyRepository.CompanyByID(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("can lock a company", func() {
+
+			company, _, err := CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.LockCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.LockCompany(ctx, dB, company.ID+1000)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
+
+		Convey("can reuse the code of a deleted company", func() {
+
+			company, _, err := CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.DeleteCompany(ctx, dB, company.ID, company.Version)
+			So(err, ShouldBeNil)
+
+			newCompany := entities.BuildCompany("Google", country)
+			newCompany.Code = company.Code
+
+			err = companyRepository.Save(ctx, dB, newCompany)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("can purge deleted companies", func() {
+
+			company, _, err := CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			_, _, err = CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			err = companyRepository.DeleteCompany(ctx, dB, company.ID, company.Version)
+			So(err, ShouldBeNil)
+
+			purged, err := companyRepository.PurgeDeletedCompanies(ctx, dB, time.Now().Add(time.Hour))
+			So(err, ShouldBeNil)
+			So(purged, ShouldEqual, 1)
+
+			_, err = companyRepository.DeletedCompanyByID(ctx, dB, company.ID)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
 	}))
 }
@@ -0,0 +1,140 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	getCompanyStatusTransitionsByCompanyIDSQL = "SELECT id, actor_id, company_id, from_status, reason, to_status, created_at FROM company_status_transitions WHERE company_id = $1 ORDER BY id ASC"
+	reassignCompanyStatusTransitionsSQL       = "UPDATE company_status_transitions SET company_id = $1 WHERE company_id = $2"
+	saveCompanyStatusTransitionSQL            = "INSERT INTO company_status_transitions (actor_id, company_id, from_status, reason, to_status, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+)
+
+type (
+	CompanyStatusTransitionRepository interface {
+		CompanyStatusTransitionsByCompanyID(ctx context.Context, operations db.SQLOperations, companyID int64) ([]*entities.CompanyStatusTransition, error)
+		ReassignCompanyStatusTransitions(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+		Save(ctx context.Context, operations db.SQLOperations, transition *entities.CompanyStatusTransition) error
+	}
+
+	AppCompanyStatusTransitionRepository struct{}
+)
+
+func NewCompanyStatusTransitionRepository() *AppCompanyStatusTransitionRepository {
+	return &AppCompanyStatusTransitionRepository{}
+}
+
+func (r *AppCompanyStatusTransitionRepository) CompanyStatusTransitionsByCompanyID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) ([]*entities.CompanyStatusTransition, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyStatusTransitionsByCompanyIDSQL,
+		companyID,
+	)
+	if err != nil {
+		return []*entities.CompanyStatusTransition{}, utils.NewError(
+			err,
+			"company status transitions query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	transitions := make([]*entities.CompanyStatusTransition, 0)
+
+	for rows.Next() {
+
+		var transition entities.CompanyStatusTransition
+
+		err := rows.Scan(
+			&transition.ID,
+			&transition.ActorID,
+			&transition.CompanyID,
+			&transition.FromStatus,
+			&transition.Reason,
+			&transition.ToStatus,
+			&transition.CreatedAt,
+		)
+		if err != nil {
+			return []*entities.CompanyStatusTransition{}, utils.NewError(
+				err,
+				"scan company status transition row error",
+			)
+		}
+
+		transitions = append(transitions, &transition)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CompanyStatusTransition{}, utils.NewError(
+			rows.Err(),
+			"company status transitions rows error",
+		)
+	}
+
+	return transitions, nil
+}
+
+func (r *AppCompanyStatusTransitionRepository) ReassignCompanyStatusTransitions(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyStatusTransitionsSQL,
+		toCompanyID,
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company status transitions exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyStatusTransitionRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	transition *entities.CompanyStatusTransition,
+) error {
+
+	if transition.CreatedAt.IsZero() {
+		transition.CreatedAt = time.Now()
+	}
+
+	err := operations.QueryRowContext(
+		ctx,
+		saveCompanyStatusTransitionSQL,
+		transition.ActorID,
+		transition.CompanyID,
+		transition.FromStatus,
+		transition.Reason,
+		transition.ToStatus,
+		transition.CreatedAt,
+	).Scan(
+		&transition.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save company status transition query row error",
+		)
+	}
+
+	return nil
+}
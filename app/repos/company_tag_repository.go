@@ -0,0 +1,175 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	attachCompanyTagSQL           = "INSERT INTO company_tags (company_id, tag_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+	detachCompanyTagSQL           = "DELETE FROM company_tags WHERE company_id = $1 AND tag_id = $2"
+	getCompanyTagsByCompanyIDsSQL = "SELECT ct.company_id, t.id, t.name, t.created_at, t.updated_at FROM company_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.company_id = ANY($1) ORDER BY LOWER(t.name) ASC"
+	reassignCompanyTagsSQL        = "INSERT INTO company_tags (company_id, tag_id, created_at) SELECT $1, tag_id, created_at FROM company_tags WHERE company_id = $2 ON CONFLICT DO NOTHING"
+)
+
+type (
+	CompanyTagRepository interface {
+		AttachCompanyTag(ctx context.Context, operations db.SQLOperations, companyID, tagID int64) error
+		CompanyTagsByCompanyIDs(ctx context.Context, operations db.SQLOperations, companyIDs []int64) (map[int64][]*entities.Tag, error)
+		DetachCompanyTag(ctx context.Context, operations db.SQLOperations, companyID, tagID int64) (bool, error)
+		ReassignCompanyTags(ctx context.Context, operations db.SQLOperations, fromCompanyID, toCompanyID int64) error
+	}
+
+	AppCompanyTagRepository struct{}
+)
+
+func NewCompanyTagRepository() *AppCompanyTagRepository {
+	return &AppCompanyTagRepository{}
+}
+
+// AttachCompanyTag tags a company. Attaching a tag the company already
+// carries is a no-op.
+func (r *AppCompanyTagRepository) AttachCompanyTag(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	tagID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		attachCompanyTagSQL,
+		companyID,
+		tagID,
+		time.Now(),
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"attach company tag exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCompanyTagRepository) CompanyTagsByCompanyIDs(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyIDs []int64,
+) (map[int64][]*entities.Tag, error) {
+
+	companyTags := make(map[int64][]*entities.Tag)
+
+	if len(companyIDs) == 0 {
+		return companyTags, nil
+	}
+
+	rows, err := operations.QueryContext(
+		ctx,
+		getCompanyTagsByCompanyIDsSQL,
+		pq.Array(companyIDs),
+	)
+	if err != nil {
+		return companyTags, utils.NewError(
+			err,
+			"company tags by company ids query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var companyID int64
+		var tag entities.Tag
+
+		err := rows.Scan(
+			&companyID,
+			&tag.ID,
+			&tag.Name,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return companyTags, utils.NewError(
+				err,
+				"scan company tag row error",
+			)
+		}
+
+		companyTags[companyID] = append(companyTags[companyID], &tag)
+	}
+
+	if rows.Err() != nil {
+		return companyTags, utils.NewError(
+			rows.Err(),
+			"company tags by company ids rows error",
+		)
+	}
+
+	return companyTags, nil
+}
+
+// DetachCompanyTag removes a tag from a company and reports whether the
+// company carried it.
+func (r *AppCompanyTagRepository) DetachCompanyTag(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID,
+	tagID int64,
+) (bool, error) {
+
+	result, err := operations.ExecContext(
+		ctx,
+		detachCompanyTagSQL,
+		companyID,
+		tagID,
+	)
+	if err != nil {
+		return false, utils.NewError(
+			err,
+			"detach company tag exec context error",
+		)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, utils.NewError(
+			err,
+			"detach company tag rows affected error",
+		)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ReassignCompanyTags copies the tags of one company onto another. The
+// source rows go when the source company is removed.
+func (r *AppCompanyTagRepository) ReassignCompanyTags(
+	ctx context.Context,
+	operations db.SQLOperations,
+	fromCompanyID,
+	toCompanyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		reassignCompanyTagsSQL,
+		toCompanyID,
+		fromCompanyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"reassign company tags exec context error",
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteCustomFieldDefinitionSQL   = "DELETE FROM custom_field_definitions WHERE id = $1"
+	getCustomFieldDefinitionsSQL     = "SELECT id, key, label, field_type, required, enum_values, pattern, created_at, updated_at FROM custom_field_definitions"
+	getCustomFieldDefinitionByIDSQL  = getCustomFieldDefinitionsSQL + " WHERE id = $1"
+	getCustomFieldDefinitionByKeySQL = getCustomFieldDefinitionsSQL + " WHERE key = $1"
+	listCustomFieldDefinitionsSQL    = getCustomFieldDefinitionsSQL + " ORDER BY key ASC"
+	saveCustomFieldDefinitionSQL     = "INSERT INTO custom_field_definitions (key, label, field_type, required, enum_values, pattern, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	updateCustomFieldDefinitionSQL   = "UPDATE custom_field_definitions SET label = $1, required = $2, enum_values = $3, pattern = $4, updated_at = $5 WHERE id = $6"
+)
+
+type (
+	CustomFieldDefinitionRepository interface {
+		CustomFieldDefinitionByID(ctx context.Context, operations db.SQLOperations, definitionID int64) (*entities.CustomFieldDefinition, error)
+		CustomFieldDefinitionByKey(ctx context.Context, operations db.SQLOperations, key string) (*entities.CustomFieldDefinition, error)
+		DeleteCustomFieldDefinition(ctx context.Context, operations db.SQLOperations, definitionID int64) error
+		ListCustomFieldDefinitions(ctx context.Context, operations db.SQLOperations) ([]*entities.CustomFieldDefinition, error)
+		Save(ctx context.Context, operations db.SQLOperations, definition *entities.CustomFieldDefinition) error
+	}
+
+	AppCustomFieldDefinitionRepository struct{}
+)
+
+func NewCustomFieldDefinitionRepository() *AppCustomFieldDefinitionRepository {
+	return &AppCustomFieldDefinitionRepository{}
+}
+
+func (r *AppCustomFieldDefinitionRepository) CustomFieldDefinitionByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	definitionID int64,
+) (*entities.CustomFieldDefinition, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCustomFieldDefinitionByIDSQL,
+		definitionID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCustomFieldDefinitionRepository) CustomFieldDefinitionByKey(
+	ctx context.Context,
+	operations db.SQLOperations,
+	key string,
+) (*entities.CustomFieldDefinition, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getCustomFieldDefinitionByKeySQL,
+		key,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppCustomFieldDefinitionRepository) DeleteCustomFieldDefinition(
+	ctx context.Context,
+	operations db.SQLOperations,
+	definitionID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteCustomFieldDefinitionSQL,
+		definitionID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete custom field definition exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCustomFieldDefinitionRepository) ListCustomFieldDefinitions(
+	ctx context.Context,
+	operations db.SQLOperations,
+) ([]*entities.CustomFieldDefinition, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		listCustomFieldDefinitionsSQL,
+	)
+	if err != nil {
+		return []*entities.CustomFieldDefinition{}, utils.NewError(
+			err,
+			"list custom field definitions query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	definitions := make([]*entities.CustomFieldDefinition, 0)
+
+	for rows.Next() {
+
+		definition, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.CustomFieldDefinition{}, err
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.CustomFieldDefinition{}, utils.NewError(
+			rows.Err(),
+			"list custom field definitions rows error",
+		)
+	}
+
+	return definitions, nil
+}
+
+func (r *AppCustomFieldDefinitionRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	definition *entities.CustomFieldDefinition,
+) error {
+
+	definition.Touch()
+
+	if definition.EnumValues == nil {
+		definition.EnumValues = []string{}
+	}
+
+	if definition.IsNew() {
+
+		err := operations.QueryRowContext(
+			ctx,
+			saveCustomFieldDefinitionSQL,
+			definition.Key,
+			definition.Label,
+			definition.FieldType,
+			definition.Required,
+			pq.Array(definition.EnumValues),
+			definition.Pattern,
+			definition.CreatedAt,
+			definition.UpdatedAt,
+		).Scan(
+			&definition.ID,
+		)
+		if err != nil {
+			return utils.NewError(
+				err,
+				"save custom field definition query row error",
+			)
+		}
+
+		return nil
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		updateCustomFieldDefinitionSQL,
+		definition.Label,
+		definition.Required,
+		pq.Array(definition.EnumValues),
+		definition.Pattern,
+		definition.UpdatedAt,
+		definition.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update custom field definition exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppCustomFieldDefinitionRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.CustomFieldDefinition, error) {
+
+	var definition entities.CustomFieldDefinition
+
+	err := rowScanner.Scan(
+		&definition.ID,
+		&definition.Key,
+		&definition.Label,
+		&definition.FieldType,
+		&definition.Required,
+		pq.Array(&definition.EnumValues),
+		&definition.Pattern,
+		&definition.CreatedAt,
+		&definition.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, utils.NewError(
+			err,
+			"scan custom field definition row error",
+		)
+	}
+
+	if definition.EnumValues == nil {
+		definition.EnumValues = []string{}
+	}
+
+	return &definition, nil
+}
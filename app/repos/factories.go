@@ -5,11 +5,24 @@ import (
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
 )
 
+func CreateAccessPolicyRule(ctx context.Context, dB db.DB) (*entities.AccessPolicyRule, error) {
+	rule := entities.BuildAccessPolicyRule()
+	err := NewAccessPolicyRuleRepository().Save(ctx, dB, rule)
+	return rule, err
+}
+
 func CreateCompany(ctx context.Context, dB db.DB, companyName string, country *entities.Country) (*entities.Company, *entities.CompanyCountry, error) {
 	company := entities.BuildCompany(companyName, country)
-	err := NewCompanyRepository().Save(ctx, dB, company)
+	website, err := utils.NormalizeWebsite(company.Website)
+	if err != nil {
+		return &entities.Company{}, &entities.CompanyCountry{}, err
+	}
+	company.Website = website.URL
+	company.WebsiteDomain = website.Domain
+	err = NewCompanyRepository().Save(ctx, dB, company)
 	if err != nil {
 		return &entities.Company{}, &entities.CompanyCountry{}, err
 	}
@@ -17,24 +30,60 @@ func CreateCompany(ctx context.Context, dB db.DB, companyName string, country *e
 	return company, companyCountry, err
 }
 
+func CreateCompanyAddress(ctx context.Context, dB db.DB, companyCountryID int64, addressType entities.AddressType) (*entities.CompanyAddress, error) {
+	address := entities.BuildCompanyAddress(companyCountryID, addressType)
+	err := NewCompanyAddressRepository().Save(ctx, dB, address)
+	return address, err
+}
+
+func CreateCompanyContact(ctx context.Context, dB db.DB, companyID int64) (*entities.CompanyContact, error) {
+	contact := entities.BuildCompanyContact(companyID)
+	err := NewCompanyContactRepository().Save(ctx, dB, contact)
+	return contact, err
+}
+
 func CreateCompanyCountry(ctx context.Context, dB db.DB, companyID, countryID int64) (*entities.CompanyCountry, error) {
 	companyCountry := entities.BuildCompanyCountry(companyID, countryID)
 	err := NewCompanyCountryRepository().Save(ctx, dB, companyCountry)
 	return companyCountry, err
 }
 
+func CreateCompanyDocument(ctx context.Context, dB db.DB, companyID int64) (*entities.CompanyDocument, error) {
+	document := entities.BuildCompanyDocument(companyID)
+	err := NewCompanyDocumentRepository().Save(ctx, dB, document)
+	return document, err
+}
+
+func CreateCompanyIdentifier(ctx context.Context, dB db.DB, companyID int64) (*entities.CompanyIdentifier, error) {
+	identifier := entities.BuildCompanyIdentifier(companyID)
+	err := NewCompanyIdentifierRepository().Save(ctx, dB, identifier)
+	return identifier, err
+}
+
 func CreateCountry(ctx context.Context, dB db.DB) (*entities.Country, error) {
 	country := entities.BuildCountry()
 	err := NewCountryRepository().Save(ctx, dB, country)
 	return country, err
 }
 
+func CreateCustomFieldDefinition(ctx context.Context, dB db.DB) (*entities.CustomFieldDefinition, error) {
+	definition := entities.BuildCustomFieldDefinition()
+	err := NewCustomFieldDefinitionRepository().Save(ctx, dB, definition)
+	return definition, err
+}
+
 func CreateSession(ctx context.Context, dB db.DB, userID int64) (*entities.Session, error) {
 	session := entities.BuildSession(userID)
 	err := NewSessionRepository().Save(ctx, dB, session)
 	return session, err
 }
 
+func CreateTag(ctx context.Context, dB db.DB) (*entities.Tag, error) {
+	tag := entities.BuildTag()
+	err := NewTagRepository().Save(ctx, dB, tag)
+	return tag, err
+}
+
 func CreateUser(ctx context.Context, dB db.DB) (*entities.User, error) {
 	user := entities.BuildUser()
 	err := NewUserRepository().Save(ctx, dB, user)
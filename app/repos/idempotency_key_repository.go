@@ -0,0 +1,196 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	// claimIdempotencyKeySQL inserts a pending key, taking over an existing key only once it has expired.
+	claimIdempotencyKeySQL           = "INSERT INTO idempotency_keys (idempotency_key, user_id, method, path, request_hash, expires_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (user_id, idempotency_key) DO UPDATE SET method = EXCLUDED.method, path = EXCLUDED.path, request_hash = EXCLUDED.request_hash, response_status = NULL, response_content_type = '', response_body = NULL, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at WHERE idempotency_keys.expires_at < $9 RETURNING id"
+	deleteExpiredIdempotencyKeysSQL  = "DELETE FROM idempotency_keys WHERE expires_at < $1"
+	deleteIdempotencyKeySQL          = "DELETE FROM idempotency_keys WHERE id = $1"
+	getIdempotencyKeyByUserAndKeySQL = "SELECT id, idempotency_key, user_id, method, path, request_hash, response_status, response_content_type, response_body, expires_at, created_at, updated_at FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2"
+	saveIdempotencyKeyResponseSQL    = "UPDATE idempotency_keys SET response_status = $1, response_content_type = $2, response_body = $3, updated_at = $4 WHERE id = $5"
+)
+
+type (
+	IdempotencyKeyRepository interface {
+		ClaimIdempotencyKey(ctx context.Context, operations db.SQLOperations, idempotencyKey *entities.IdempotencyKey) (bool, error)
+		DeleteExpiredIdempotencyKeys(ctx context.Context, operations db.SQLOperations, now time.Time) (int64, error)
+		DeleteIdempotencyKey(ctx context.Context, operations db.SQLOperations, idempotencyKeyID int64) error
+		IdempotencyKeyByUserAndKey(ctx context.Context, operations db.SQLOperations, userID int64, key string) (*entities.IdempotencyKey, error)
+		SaveResponse(ctx context.Context, operations db.SQLOperations, idempotencyKey *entities.IdempotencyKey) error
+	}
+
+	AppIdempotencyKeyRepository struct{}
+)
+
+func NewIdempotencyKeyRepository() *AppIdempotencyKeyRepository {
+	return &AppIdempotencyKeyRepository{}
+}
+
+func (r *AppIdempotencyKeyRepository) ClaimIdempotencyKey(
+	ctx context.Context,
+	operations db.SQLOperations,
+	idempotencyKey *entities.IdempotencyKey,
+) (bool, error) {
+
+	idempotencyKey.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		claimIdempotencyKeySQL,
+		idempotencyKey.Key,
+		idempotencyKey.UserID,
+		idempotencyKey.Method,
+		idempotencyKey.Path,
+		idempotencyKey.RequestHash,
+		idempotencyKey.ExpiresAt,
+		idempotencyKey.CreatedAt,
+		idempotencyKey.UpdatedAt,
+		time.Now(),
+	).Scan(
+		&idempotencyKey.ID,
+	)
+	if err != nil {
+		if utils.IsErrNoRows(err) {
+			return false, nil
+		}
+
+		return false, utils.NewError(
+			err,
+			"claim idempotency key query row error",
+		)
+	}
+
+	return true, nil
+}
+
+func (r *AppIdempotencyKeyRepository) DeleteExpiredIdempotencyKeys(
+	ctx context.Context,
+	operations db.SQLOperations,
+	now time.Time,
+) (int64, error) {
+
+	result, err := operations.ExecContext(
+		ctx,
+		deleteExpiredIdempotencyKeysSQL,
+		now,
+	)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"delete expired idempotency keys exec context error",
+		)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"delete expired idempotency keys rows affected error",
+		)
+	}
+
+	return deleted, nil
+}
+
+func (r *AppIdempotencyKeyRepository) DeleteIdempotencyKey(
+	ctx context.Context,
+	operations db.SQLOperations,
+	idempotencyKeyID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteIdempotencyKeySQL,
+		idempotencyKeyID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete idempotency key exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppIdempotencyKeyRepository) IdempotencyKeyByUserAndKey(
+	ctx context.Context,
+	operations db.SQLOperations,
+	userID int64,
+	key string,
+) (*entities.IdempotencyKey, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getIdempotencyKeyByUserAndKeySQL,
+		userID,
+		key,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppIdempotencyKeyRepository) SaveResponse(
+	ctx context.Context,
+	operations db.SQLOperations,
+	idempotencyKey *entities.IdempotencyKey,
+) error {
+
+	idempotencyKey.Touch()
+
+	_, err := operations.ExecContext(
+		ctx,
+		saveIdempotencyKeyResponseSQL,
+		idempotencyKey.ResponseStatus,
+		idempotencyKey.ResponseContentType,
+		idempotencyKey.ResponseBody,
+		idempotencyKey.UpdatedAt,
+		idempotencyKey.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save idempotency key response exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppIdempotencyKeyRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.IdempotencyKey, error) {
+
+	var idempotencyKey entities.IdempotencyKey
+
+	err := rowScanner.Scan(
+		&idempotencyKey.ID,
+		&idempotencyKey.Key,
+		&idempotencyKey.UserID,
+		&idempotencyKey.Method,
+		&idempotencyKey.Path,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.ResponseStatus,
+		&idempotencyKey.ResponseContentType,
+		&idempotencyKey.ResponseBody,
+		&idempotencyKey.ExpiresAt,
+		&idempotencyKey.CreatedAt,
+		&idempotencyKey.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.IdempotencyKey{}, utils.NewError(
+			err,
+			"scan idempotency key row error",
+		)
+	}
+
+	return &idempotencyKey, nil
+}
@@ -0,0 +1,102 @@
+package repos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotencyKeyRepository(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	idempotencyKeyRepository := NewIdempotencyKeyRepository()
+
+	ctx := context.Background()
+
+	Convey("Idempotency Key Repository", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		buildIdempotencyKey := func(ttl time.Duration) *entities.IdempotencyKey {
+			return &entities.IdempotencyKey{
+				ExpiresAt:   time.Now().Add(ttl),
+				Key:         "create-company-1",
+				Method:      http.MethodPost,
+				Path:        "/v1/companies",
+				RequestHash: "hash",
+				UserID:      1,
+			}
+		}
+
+		Convey("can claim an unused idempotency key", func() {
+
+			idempotencyKey := buildIdempotencyKey(time.Hour)
+
+			claimed, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, idempotencyKey)
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeTrue)
+			So(idempotencyKey.ID, ShouldNotBeZeroValue)
+		})
+
+		Convey("cannot claim an idempotency key that has not expired", func() {
+
+			_, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, buildIdempotencyKey(time.Hour))
+			So(err, ShouldBeNil)
+
+			claimed, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, buildIdempotencyKey(time.Hour))
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeFalse)
+		})
+
+		Convey("can claim an idempotency key that has expired", func() {
+
+			_, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, buildIdempotencyKey(-time.Hour))
+			So(err, ShouldBeNil)
+
+			claimed, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, buildIdempotencyKey(time.Hour))
+			So(err, ShouldBeNil)
+			So(claimed, ShouldBeTrue)
+		})
+
+		Convey("can save and fetch an idempotency key response", func() {
+
+			idempotencyKey := buildIdempotencyKey(time.Hour)
+
+			_, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, idempotencyKey)
+			So(err, ShouldBeNil)
+
+			idempotencyKey.ResponseStatus = null.IntFrom(http.StatusCreated)
+			idempotencyKey.ResponseContentType = "application/json"
+			idempotencyKey.ResponseBody = []byte(`{"id":1}`)
+
+			err = idempotencyKeyRepository.SaveResponse(ctx, dB, idempotencyKey)
+			So(err, ShouldBeNil)
+
+			foundIdempotencyKey, err := idempotencyKeyRepository.IdempotencyKeyByUserAndKey(ctx, dB, idempotencyKey.UserID, idempotencyKey.Key)
+			So(err, ShouldBeNil)
+
+			So(foundIdempotencyKey.ID, ShouldEqual, idempotencyKey.ID)
+			So(foundIdempotencyKey.IsCompleted(), ShouldBeTrue)
+			So(foundIdempotencyKey.ResponseStatus.Int64, ShouldEqual, http.StatusCreated)
+			So(string(foundIdempotencyKey.ResponseBody), ShouldEqual, `{"id":1}`)
+		})
+
+		Convey("can delete expired idempotency keys", func() {
+
+			_, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, buildIdempotencyKey(-time.Hour))
+			So(err, ShouldBeNil)
+
+			deleted, err := idempotencyKeyRepository.DeleteExpiredIdempotencyKeys(ctx, dB, time.Now())
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+		})
+	}))
+}
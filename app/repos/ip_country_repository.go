@@ -0,0 +1,138 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteExpiredIPCountriesSQL = "DELETE FROM ip_countries WHERE expires_at < $1"
+	getIPCountryByIPAddressSQL  = "SELECT id, city, country_calling_code, country_code, country_name, currency, expires_at, ip_address, created_at, updated_at FROM ip_countries WHERE ip_address = $1 AND expires_at > $2"
+	saveIPCountrySQL            = "INSERT INTO ip_countries (city, country_calling_code, country_code, country_name, currency, expires_at, ip_address, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (ip_address) DO UPDATE SET city = EXCLUDED.city, country_calling_code = EXCLUDED.country_calling_code, country_code = EXCLUDED.country_code, country_name = EXCLUDED.country_name, currency = EXCLUDED.currency, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at RETURNING id"
+)
+
+type (
+	IPCountryRepository interface {
+		DeleteExpiredIPCountries(ctx context.Context, operations db.SQLOperations, now time.Time) (int64, error)
+		IPCountryByIPAddress(ctx context.Context, operations db.SQLOperations, ipAddress string) (*entities.IPCountry, error)
+		Save(ctx context.Context, operations db.SQLOperations, ipCountry *entities.IPCountry) error
+	}
+
+	AppIPCountryRepository struct{}
+)
+
+func NewIPCountryRepository() *AppIPCountryRepository {
+	return &AppIPCountryRepository{}
+}
+
+func (r *AppIPCountryRepository) DeleteExpiredIPCountries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	now time.Time,
+) (int64, error) {
+
+	result, err := operations.ExecContext(
+		ctx,
+		deleteExpiredIPCountriesSQL,
+		now,
+	)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"delete expired ip countries exec context error",
+		)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"delete expired ip countries rows affected error",
+		)
+	}
+
+	return deleted, nil
+}
+
+// IPCountryByIPAddress only returns unexpired entries.
+func (r *AppIPCountryRepository) IPCountryByIPAddress(
+	ctx context.Context,
+	operations db.SQLOperations,
+	ipAddress string,
+) (*entities.IPCountry, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getIPCountryByIPAddressSQL,
+		ipAddress,
+		time.Now(),
+	)
+
+	return r.scanRow(row)
+}
+
+// Save inserts ipCountry or replaces the entry cached for its IP address.
+func (r *AppIPCountryRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	ipCountry *entities.IPCountry,
+) error {
+
+	ipCountry.Touch()
+
+	err := operations.QueryRowContext(
+		ctx,
+		saveIPCountrySQL,
+		ipCountry.City,
+		ipCountry.CountryCallingCode,
+		ipCountry.CountryCode,
+		ipCountry.CountryName,
+		ipCountry.Currency,
+		ipCountry.ExpiresAt,
+		ipCountry.IPAddress,
+		ipCountry.CreatedAt,
+		ipCountry.UpdatedAt,
+	).Scan(
+		&ipCountry.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"save ip country query row error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppIPCountryRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.IPCountry, error) {
+
+	var ipCountry entities.IPCountry
+
+	err := rowScanner.Scan(
+		&ipCountry.ID,
+		&ipCountry.City,
+		&ipCountry.CountryCallingCode,
+		&ipCountry.CountryCode,
+		&ipCountry.CountryName,
+		&ipCountry.Currency,
+		&ipCountry.ExpiresAt,
+		&ipCountry.IPAddress,
+		&ipCountry.CreatedAt,
+		&ipCountry.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.IPCountry{}, utils.NewError(
+			err,
+			"scan ip country row error",
+		)
+	}
+
+	return &ipCountry, nil
+}
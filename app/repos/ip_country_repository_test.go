@@ -0,0 +1,82 @@
+package repos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIPCountryRepository(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ipCountryRepository := NewIPCountryRepository()
+
+	ctx := context.Background()
+
+	Convey("IP Country Repository", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		buildIPCountry := func(ttl time.Duration) *entities.IPCountry {
+			return &entities.IPCountry{
+				CountryCode: "CY",
+				CountryName: "Cyprus",
+				ExpiresAt:   time.Now().Add(ttl),
+				IPAddress:   "176.56.168.100",
+			}
+		}
+
+		Convey("can save and fetch an ip country", func() {
+
+			err := ipCountryRepository.Save(ctx, dB, buildIPCountry(time.Hour))
+			So(err, ShouldBeNil)
+
+			ipCountry, err := ipCountryRepository.IPCountryByIPAddress(ctx, dB, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(ipCountry.CountryCode, ShouldEqual, "CY")
+			So(ipCountry.CountryName, ShouldEqual, "Cyprus")
+		})
+
+		Convey("saving an ip address again replaces its country", func() {
+
+			err := ipCountryRepository.Save(ctx, dB, buildIPCountry(time.Hour))
+			So(err, ShouldBeNil)
+
+			ipCountry := buildIPCountry(time.Hour)
+			ipCountry.CountryCode = "KE"
+			ipCountry.CountryName = "Kenya"
+
+			err = ipCountryRepository.Save(ctx, dB, ipCountry)
+			So(err, ShouldBeNil)
+
+			foundIPCountry, err := ipCountryRepository.IPCountryByIPAddress(ctx, dB, "176.56.168.100")
+			So(err, ShouldBeNil)
+			So(foundIPCountry.CountryCode, ShouldEqual, "KE")
+		})
+
+		Convey("cannot fetch an expired ip country", func() {
+
+			err := ipCountryRepository.Save(ctx, dB, buildIPCountry(-time.Hour))
+			So(err, ShouldBeNil)
+
+			_, err = ipCountryRepository.IPCountryByIPAddress(ctx, dB, "176.56.168.100")
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
+
+		Convey("can delete expired ip countries", func() {
+
+			err := ipCountryRepository.Save(ctx, dB, buildIPCountry(-time.Hour))
+			So(err, ShouldBeNil)
+
+			deleted, err := ipCountryRepository.DeleteExpiredIPCountries(ctx, dB, time.Now())
+			So(err, ShouldBeNil)
+			So(deleted, ShouldEqual, 1)
+		})
+	}))
+}
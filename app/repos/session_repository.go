@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
@@ -9,13 +10,15 @@ import (
 )
 
 const (
-	getSessionByIDSQL = "SELECT s.id, s.deactivated_at, s.ip_address, s.last_refreshed_at, s.user_agent, u.status, s.user_id, s.created_at, s.updated_at FROM sessions s JOIN users u ON u.id = s.user_id WHERE s.id = $1"
-	saveSessionSQL    = "INSERT INTO sessions (deactivated_at, ip_address, last_refreshed_at, user_agent, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
-	updateSessionSQL  = "UPDATE sessions SET deactivated_at = $1, last_refreshed_at = $2, updated_at = $3 WHERE id = $4"
+	deactivateUserSessionsSQL = "UPDATE sessions SET deactivated_at = $1, updated_at = $1 WHERE user_id = $2 AND deactivated_at IS NULL"
+	getSessionByIDSQL         = "SELECT s.id, s.deactivated_at, s.ip_address, s.last_refreshed_at, s.user_agent, u.status, s.user_id, s.created_at, s.updated_at FROM sessions s JOIN users u ON u.id = s.user_id WHERE s.id = $1"
+	saveSessionSQL            = "INSERT INTO sessions (deactivated_at, ip_address, last_refreshed_at, user_agent, user_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+	updateSessionSQL          = "UPDATE sessions SET deactivated_at = $1, last_refreshed_at = $2, updated_at = $3 WHERE id = $4"
 )
 
 type (
 	SessionRepository interface {
+		DeactivateUserSessions(ctx context.Context, operations db.SQLOperations, userID int64) error
 		Save(ctx context.Context, operations db.SQLOperations, session *entities.Session) error
 		SessionByID(ctx context.Context, operations db.SQLOperations, sessionID int64) (*entities.Session, error)
 	}
@@ -27,6 +30,29 @@ func NewSessionRepository() *AppSessionRepository {
 	return &AppSessionRepository{}
 }
 
+// DeactivateUserSessions ends every session of the user still active.
+func (r *AppSessionRepository) DeactivateUserSessions(
+	ctx context.Context,
+	operations db.SQLOperations,
+	userID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deactivateUserSessionsSQL,
+		time.Now(),
+		userID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"deactivate user sessions exec context error",
+		)
+	}
+
+	return nil
+}
+
 func (r *AppSessionRepository) Save(
 	ctx context.Context,
 	operations db.SQLOperations,
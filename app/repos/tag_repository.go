@@ -0,0 +1,197 @@
+package repos
+
+import (
+	"context"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	deleteTagSQL    = "DELETE FROM tags WHERE id = $1"
+	getTagsSQL      = "SELECT t.id, t.name, (SELECT COUNT(ct.company_id) FROM company_tags ct JOIN companies co ON co.id = ct.company_id WHERE ct.tag_id = t.id AND co.deleted_at IS NULL), t.created_at, t.updated_at FROM tags t"
+	getTagByIDSQL   = getTagsSQL + " WHERE t.id = $1"
+	getTagByNameSQL = getTagsSQL + " WHERE LOWER(t.name) = LOWER($1)"
+	listTagsSQL     = getTagsSQL + " ORDER BY LOWER(t.name) ASC"
+	saveTagSQL      = "INSERT INTO tags (name, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id"
+	updateTagSQL    = "UPDATE tags SET name = $1, updated_at = $2 WHERE id = $3"
+)
+
+type (
+	TagRepository interface {
+		DeleteTag(ctx context.Context, operations db.SQLOperations, tagID int64) error
+		ListTags(ctx context.Context, operations db.SQLOperations) ([]*entities.Tag, error)
+		Save(ctx context.Context, operations db.SQLOperations, tag *entities.Tag) error
+		TagByID(ctx context.Context, operations db.SQLOperations, tagID int64) (*entities.Tag, error)
+		TagByName(ctx context.Context, operations db.SQLOperations, name string) (*entities.Tag, error)
+	}
+
+	AppTagRepository struct{}
+)
+
+func NewTagRepository() *AppTagRepository {
+	return &AppTagRepository{}
+}
+
+func (r *AppTagRepository) DeleteTag(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tagID int64,
+) error {
+
+	_, err := operations.ExecContext(
+		ctx,
+		deleteTagSQL,
+		tagID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"delete tag exec context error",
+		)
+	}
+
+	return nil
+}
+
+// ListTags returns all tags by name along with how many live companies carry
+// each of them.
+func (r *AppTagRepository) ListTags(
+	ctx context.Context,
+	operations db.SQLOperations,
+) ([]*entities.Tag, error) {
+
+	rows, err := operations.QueryContext(
+		ctx,
+		listTagsSQL,
+	)
+	if err != nil {
+		return []*entities.Tag{}, utils.NewError(
+			err,
+			"list tags query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	tags := make([]*entities.Tag, 0)
+
+	for rows.Next() {
+
+		tag, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.Tag{}, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.Tag{}, utils.NewError(
+			rows.Err(),
+			"list tags rows error",
+		)
+	}
+
+	return tags, nil
+}
+
+func (r *AppTagRepository) Save(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tag *entities.Tag,
+) error {
+
+	tag.Touch()
+
+	if tag.IsNew() {
+
+		err := operations.QueryRowContext(
+			ctx,
+			saveTagSQL,
+			tag.Name,
+			tag.CreatedAt,
+			tag.UpdatedAt,
+		).Scan(
+			&tag.ID,
+		)
+		if err != nil {
+			return utils.NewError(
+				err,
+				"save tag query row error",
+			)
+		}
+
+		return nil
+	}
+
+	_, err := operations.ExecContext(
+		ctx,
+		updateTagSQL,
+		tag.Name,
+		tag.UpdatedAt,
+		tag.ID,
+	)
+	if err != nil {
+		return utils.NewError(
+			err,
+			"update tag exec context error",
+		)
+	}
+
+	return nil
+}
+
+func (r *AppTagRepository) TagByID(
+	ctx context.Context,
+	operations db.SQLOperations,
+	tagID int64,
+) (*entities.Tag, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getTagByIDSQL,
+		tagID,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppTagRepository) TagByName(
+	ctx context.Context,
+	operations db.SQLOperations,
+	name string,
+) (*entities.Tag, error) {
+
+	row := operations.QueryRowContext(
+		ctx,
+		getTagByNameSQL,
+		name,
+	)
+
+	return r.scanRow(row)
+}
+
+func (r *AppTagRepository) scanRow(
+	rowScanner db.RowScanner,
+) (*entities.Tag, error) {
+
+	var tag entities.Tag
+
+	err := rowScanner.Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.UsageCount,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		return &entities.Tag{}, utils.NewError(
+			err,
+			"scan tag row error",
+		)
+	}
+
+	return &tag, nil
+}
@@ -2,25 +2,31 @@ package repos
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/utils"
 )
 
 const (
 	getUserByIDSQL       = getUsersSQL + " WHERE id = $1"
-	getUserByUsernameSQL = getUsersSQL + " WHERE username = $1"
-	getUsersSQL          = "SELECT id, first_name, last_name, username, password_hash, status, created_at, updated_at FROM users "
-	saveUserSQL          = "INSERT INTO users (first_name, last_name, username, password_hash, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
-	updateUserSQL        = "UPDATE users SET first_name = $1, last_name = $2, username = $3, password_hash = $4, status = $5, updated_at = $6 WHERE id = $7"
+	getUserByUsernameSQL = getUsersSQL + " WHERE lower(username) = lower($1)"
+	getUserCountSQL      = "SELECT COUNT(id) FROM users"
+	getUsersSQL          = "SELECT id, first_name, last_name, username, password_hash, role, status, created_at, updated_at FROM users "
+	saveUserSQL          = "INSERT INTO users (first_name, last_name, username, password_hash, role, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+	updateUserSQL        = "UPDATE users SET first_name = $1, last_name = $2, username = $3, password_hash = $4, role = $5, status = $6, updated_at = $7 WHERE id = $8"
 )
 
 type (
 	UserRepository interface {
+		ListUsers(ctx context.Context, operations db.SQLOperations, filter *forms.UserFilter) ([]*entities.User, error)
+		Save(ctx context.Context, operations db.SQLOperations, user *entities.User) error
 		UserByID(ctx context.Context, operations db.SQLOperations, userID int64) (*entities.User, error)
 		UserByUsername(ctx context.Context, operations db.SQLOperations, username string) (*entities.User, error)
-		Save(ctx context.Context, operations db.SQLOperations, user *entities.User) error
+		UserCount(ctx context.Context, operations db.SQLOperations, filter *forms.UserFilter) (int, error)
 	}
 
 	AppUserRepository struct{}
@@ -30,6 +36,46 @@ func NewUserRepository() *AppUserRepository {
 	return &AppUserRepository{}
 }
 
+func (r *AppUserRepository) ListUsers(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.UserFilter,
+) ([]*entities.User, error) {
+
+	query, args := r.buildQuery(getUsersSQL, filter)
+
+	rows, err := operations.QueryContext(ctx, query, args...)
+	if err != nil {
+		return []*entities.User{}, utils.NewError(
+			err,
+			"list users query context error",
+		)
+	}
+
+	defer rows.Close()
+
+	users := make([]*entities.User, 0)
+
+	for rows.Next() {
+
+		user, err := r.scanRow(rows)
+		if err != nil {
+			return []*entities.User{}, err
+		}
+
+		users = append(users, user)
+	}
+
+	if rows.Err() != nil {
+		return []*entities.User{}, utils.NewError(
+			rows.Err(),
+			"list users rows error",
+		)
+	}
+
+	return users, nil
+}
+
 func (r *AppUserRepository) UserByID(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -53,6 +99,8 @@ func (r *AppUserRepository) UserByID(
 	return user, nil
 }
 
+// UserByUsername matches usernames case insensitively, which is how they are
+// kept unique.
 func (r *AppUserRepository) UserByUsername(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -76,6 +124,31 @@ func (r *AppUserRepository) UserByUsername(
 	return user, nil
 }
 
+func (r *AppUserRepository) UserCount(
+	ctx context.Context,
+	operations db.SQLOperations,
+	filter *forms.UserFilter,
+) (int, error) {
+
+	var count int
+
+	query, args := r.buildQuery(getUserCountSQL, filter.NoPagination())
+
+	err := operations.QueryRowContext(
+		ctx,
+		query,
+		args...,
+	).Scan(&count)
+	if err != nil {
+		return 0, utils.NewError(
+			err,
+			"user count query row error",
+		)
+	}
+
+	return count, nil
+}
+
 func (r *AppUserRepository) Save(
 	ctx context.Context,
 	operations db.SQLOperations,
@@ -93,6 +166,7 @@ func (r *AppUserRepository) Save(
 			user.LastName,
 			user.Username,
 			user.PasswordHash,
+			user.Role,
 			user.Status,
 			user.CreatedAt,
 			user.UpdatedAt,
@@ -116,6 +190,7 @@ func (r *AppUserRepository) Save(
 		user.LastName,
 		user.Username,
 		user.PasswordHash,
+		user.Role,
 		user.Status,
 		user.UpdatedAt,
 		user.ID,
@@ -130,6 +205,43 @@ func (r *AppUserRepository) Save(
 	return nil
 }
 
+func (r *AppUserRepository) buildQuery(
+	query string,
+	filter *forms.UserFilter,
+) (string, []interface{}) {
+
+	args := make([]interface{}, 0)
+	conditions := make([]string, 0)
+	counter := utils.NewPlaceholder()
+
+	if filter.Term != "" {
+		termPlaceholder := counter.Touch()
+		conditions = append(conditions, fmt.Sprintf(" (LOWER(username) LIKE '%%' || $%d || '%%' OR LOWER(first_name || ' ' || last_name) LIKE '%%' || $%d || '%%')", termPlaceholder, termPlaceholder))
+		args = append(args, strings.ToLower(filter.Term))
+	}
+
+	if filter.Role != "" {
+		conditions = append(conditions, fmt.Sprintf(" role = $%d", counter.Touch()))
+		args = append(args, filter.Role)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf(" status = $%d", counter.Touch()))
+		args = append(args, filter.Status)
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE" + strings.Join(conditions, " AND ")
+	}
+
+	if filter.Page > 0 && filter.Per > 0 {
+		query += fmt.Sprintf(" ORDER BY id ASC LIMIT $%d OFFSET $%d", counter.Touch(), counter.Touch())
+		args = append(args, filter.Per, (filter.Page-1)*filter.Per)
+	}
+
+	return query, args
+}
+
 func (r *AppUserRepository) scanRow(
 	rowScanner db.RowScanner,
 ) (*entities.User, error) {
@@ -142,6 +254,7 @@ func (r *AppUserRepository) scanRow(
 		&user.LastName,
 		&user.Username,
 		&user.PasswordHash,
+		&user.Role,
 		&user.Status,
 		&user.CreatedAt,
 		&user.UpdatedAt,
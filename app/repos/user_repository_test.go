@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -82,6 +83,23 @@ func TestUserRepository(t *testing.T) {
 			So(err, ShouldBeNil)
 
 			So(foundUser.ID, ShouldEqual, user.ID)
+
+			foundUser, err = userRepository.UserByUsername(ctx, dB, strings.ToUpper(user.Username))
+			So(err, ShouldBeNil)
+
+			So(foundUser.ID, ShouldEqual, user.ID)
+		})
+
+		Convey("cannot save usernames differing only by case", func() {
+
+			user, err := CreateUser(ctx, dB)
+			So(err, ShouldBeNil)
+
+			duplicateUser := entities.BuildUser()
+			duplicateUser.Username = strings.ToUpper(user.Username)
+
+			err = userRepository.Save(ctx, dB, duplicateUser)
+			So(utils.IsUniqueViolation(err), ShouldBeTrue)
 		})
 	}))
 }
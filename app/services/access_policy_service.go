@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+)
+
+// accessPolicyRulesTTL is how long rules stored in the database are cached
+// before they are reloaded.
+const accessPolicyRulesTTL = time.Minute
+
+type (
+	AccessPolicyService interface {
+		Evaluate(ctx context.Context, dB db.DB, method, route string) (*entities.AccessDecision, error)
+		Mode() entities.AccessPolicyMode
+	}
+
+	AppAccessPolicyService struct {
+		accessPolicyRuleRepository repos.AccessPolicyRuleRepository
+		configRules                []*entities.AccessPolicyRule
+		ipAPIProvider              providers.IPAPI
+		mode                       entities.AccessPolicyMode
+
+		mu            sync.Mutex
+		rules         []*entities.AccessPolicyRule
+		rulesLoadedAt time.Time
+	}
+)
+
+// NewAccessPolicyService configures the access policy from the environment.
+// ACCESS_POLICY_MODE is either enforce, the default, or dry_run, which only
+// logs the requests that would be denied. ACCESS_POLICY_RULES_PATH optionally
+// names a JSON file of rules applied alongside those stored in the database.
+func NewAccessPolicyService(
+	accessPolicyRuleRepository repos.AccessPolicyRuleRepository,
+	ipAPIProvider providers.IPAPI,
+) *AppAccessPolicyService {
+
+	mode := entities.AccessPolicyMode(os.Getenv("ACCESS_POLICY_MODE"))
+	if mode == "" {
+		mode = entities.AccessPolicyModeEnforce
+	}
+
+	if !mode.IsValid() {
+		logger.Fatalf("invalid access policy mode = [%v]", mode)
+	}
+
+	configRules := []*entities.AccessPolicyRule{}
+
+	if path := os.Getenv("ACCESS_POLICY_RULES_PATH"); path != "" {
+
+		rules, err := LoadAccessPolicyRules(path)
+		if err != nil {
+			logger.Fatalf("failed to load access policy rules err = %v", err)
+		}
+
+		configRules = rules
+	}
+
+	return NewAccessPolicyServiceWithConfig(
+		accessPolicyRuleRepository,
+		ipAPIProvider,
+		mode,
+		configRules,
+	)
+}
+
+func NewAccessPolicyServiceWithConfig(
+	accessPolicyRuleRepository repos.AccessPolicyRuleRepository,
+	ipAPIProvider providers.IPAPI,
+	mode entities.AccessPolicyMode,
+	configRules []*entities.AccessPolicyRule,
+) *AppAccessPolicyService {
+	return &AppAccessPolicyService{
+		accessPolicyRuleRepository: accessPolicyRuleRepository,
+		configRules:                configRules,
+		ipAPIProvider:              ipAPIProvider,
+		mode:                       mode,
+	}
+}
+
+// LoadAccessPolicyRules reads a JSON array of rules from path. Every rule in
+// the file is enabled.
+func LoadAccessPolicyRules(path string) ([]*entities.AccessPolicyRule, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []*entities.AccessPolicyRule{}, fmt.Errorf("failed to read access policy rules path = %v err = %v", path, err)
+	}
+
+	var rules []*entities.AccessPolicyRule
+
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return []*entities.AccessPolicyRule{}, fmt.Errorf("failed to parse access policy rules path = %v err = %v", path, err)
+	}
+
+	for index, rule := range rules {
+
+		if rule == nil || !rule.RuleType.IsValid() {
+			return []*entities.AccessPolicyRule{}, fmt.Errorf("invalid access policy rule at index = %v path = %v", index, path)
+		}
+
+		rule.Enabled = true
+	}
+
+	return rules, nil
+}
+
+// Evaluate decides whether a request to method on the gin route template
+// route is allowed. The request is denied by the first rule that rejects it.
+// An allowed request skips authentication when a rule lets it. Rules are
+// skipped when the country lookup fails open, allowing the request.
+func (s *AppAccessPolicyService) Evaluate(
+	ctx context.Context,
+	dB db.DB,
+	method,
+	route string,
+) (*entities.AccessDecision, error) {
+
+	rules, err := s.loadRules(ctx, dB)
+	if err != nil {
+		return &entities.AccessDecision{}, err
+	}
+
+	request := &entities.AccessRequest{
+		Method: method,
+		Route:  route,
+	}
+
+	for _, rule := range rules {
+		if rule.RequiresCountry(method, route) {
+
+			countryWithIP, err := s.countryForRequest(ctx)
+			if err != nil {
+				return &entities.AccessDecision{}, err
+			}
+
+			if countryWithIP.Unavailable {
+				logger.Warnf("Skipping access policy rules for %v %v, the country of ip address = [%v] is unavailable", method, route, countryWithIP.IP)
+				return &entities.AccessDecision{Allowed: true}, nil
+			}
+
+			request.Country = strings.ToUpper(countryWithIP.CountryCode)
+
+			break
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Denies(request) {
+			return &entities.AccessDecision{
+				Allowed: false,
+				Country: request.Country,
+				Rule:    rule,
+			}, nil
+		}
+	}
+
+	decision := &entities.AccessDecision{
+		Allowed: true,
+		Country: request.Country,
+	}
+
+	for _, rule := range rules {
+		if rule.SkipsAuthentication(request) {
+			decision.Rule = rule
+			decision.SkipAuthentication = true
+			break
+		}
+	}
+
+	return decision, nil
+}
+
+func (s *AppAccessPolicyService) Mode() entities.AccessPolicyMode {
+	return s.mode
+}
+
+// countryForRequest looks up the country of the request's IP address. An
+// unknown location is returned as an empty country code, which only matches
+// rules restricting routes to other countries.
+func (s *AppAccessPolicyService) countryForRequest(
+	ctx context.Context,
+) (*providers.CountryWithIP, error) {
+
+	ipAddress := ctxhelper.IPAddress(ctx)
+	if ipAddress == "" {
+		return &providers.CountryWithIP{}, nil
+	}
+
+	countryWithIP, err := s.ipAPIProvider.CountryForIP(ctx, ipAddress)
+	if err != nil {
+		return &providers.CountryWithIP{}, utils.NewError(
+			err,
+			"failed to find country for ip address = [%v]",
+			ipAddress,
+		)
+	}
+
+	return countryWithIP, nil
+}
+
+// loadRules returns the configured rules followed by the enabled rules in the
+// database. When reloading fails the previously loaded rules are kept.
+func (s *AppAccessPolicyService) loadRules(
+	ctx context.Context,
+	dB db.DB,
+) ([]*entities.AccessPolicyRule, error) {
+
+	s.mu.Lock()
+	loadedRules, rulesLoadedAt := s.rules, s.rulesLoadedAt
+	s.mu.Unlock()
+
+	if !rulesLoadedAt.IsZero() && time.Since(rulesLoadedAt) < accessPolicyRulesTTL {
+		return loadedRules, nil
+	}
+
+	storedRules, err := s.accessPolicyRuleRepository.ListEnabledAccessPolicyRules(ctx, dB)
+	if err != nil {
+		if rulesLoadedAt.IsZero() {
+			return []*entities.AccessPolicyRule{}, err
+		}
+
+		logger.Errorf("Failed to reload access policy rules, keeping %v loaded rules err = %v", len(loadedRules), err)
+		return loadedRules, nil
+	}
+
+	rules := make([]*entities.AccessPolicyRule, 0, len(s.configRules)+len(storedRules))
+	rules = append(rules, s.configRules...)
+	rules = append(rules, storedRules...)
+
+	s.mu.Lock()
+	s.rules = rules
+	s.rulesLoadedAt = time.Now()
+	s.mu.Unlock()
+
+	return rules, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testIPAPI struct {
+	countryCodes map[string]string
+	lookups      int
+}
+
+func (p *testIPAPI) CountryForIP(ctx context.Context, ipAddress string) (*providers.CountryWithIP, error) {
+
+	p.lookups++
+
+	if ipAddress == "0.0.0.0" {
+		return &providers.CountryWithIP{}, errors.New("provider unavailable")
+	}
+
+	if ipAddress == "0.0.0.1" {
+		return &providers.CountryWithIP{IP: ipAddress, Unavailable: true}, nil
+	}
+
+	return &providers.CountryWithIP{IP: ipAddress, CountryCode: p.countryCodes[ipAddress]}, nil
+}
+
+func TestAccessPolicyService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	accessPolicyRuleRepository := repos.NewAccessPolicyRuleRepository()
+
+	Convey("Access Policy Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		ipAPI := &testIPAPI{
+			countryCodes: map[string]string{
+				"176.56.168.100":  "CY",
+				"197.156.118.251": "KE",
+			},
+		}
+
+		storedRule, err := repos.CreateAccessPolicyRule(ctx, dB)
+		So(err, ShouldBeNil)
+
+		storedRule.Countries = []string{"KE"}
+		storedRule.Methods = []string{http.MethodDelete}
+		storedRule.Routes = []string{"/v1/tags/:id"}
+
+		err = accessPolicyRuleRepository.Save(ctx, dB, storedRule)
+		So(err, ShouldBeNil)
+
+		configRules := []*entities.AccessPolicyRule{
+			{
+				Name:      "kenya_reads_only",
+				RuleType:  entities.AccessPolicyRuleTypeAllowOnly,
+				Countries: []string{"KE"},
+				Methods:   []string{http.MethodGet, http.MethodDelete},
+				Enabled:   true,
+			},
+			{
+				Name:      "cyprus_unauthenticated_company_creation",
+				RuleType:  entities.AccessPolicyRuleTypeSkipAuthentication,
+				Countries: []string{"CY"},
+				Methods:   []string{http.MethodPost},
+				Routes:    []string{"/v1/companies"},
+				Enabled:   true,
+			},
+		}
+
+		accessPolicyService := NewAccessPolicyServiceWithConfig(
+			accessPolicyRuleRepository,
+			ipAPI,
+			entities.AccessPolicyModeDryRun,
+			configRules,
+		)
+
+		So(accessPolicyService.Mode(), ShouldEqual, entities.AccessPolicyModeDryRun)
+
+		kenyaCtx := ctxhelper.WithIpAddress(ctx, "197.156.118.251")
+
+		Convey("applies configured rules", func() {
+
+			decision, err := accessPolicyService.Evaluate(kenyaCtx, dB, http.MethodPut, "/v1/tags/:id")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeFalse)
+			So(decision.Country, ShouldEqual, "KE")
+			So(decision.Rule.Name, ShouldEqual, "kenya_reads_only")
+		})
+
+		Convey("applies rules stored in the database", func() {
+
+			decision, err := accessPolicyService.Evaluate(kenyaCtx, dB, http.MethodDelete, "/v1/tags/:id")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeFalse)
+			So(decision.Rule.Name, ShouldEqual, storedRule.Name)
+
+			decision, err = accessPolicyService.Evaluate(kenyaCtx, dB, http.MethodGet, "/v1/tags")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeTrue)
+		})
+
+		Convey("lets matching requests skip authentication", func() {
+
+			cyprusCtx := ctxhelper.WithIpAddress(ctx, "176.56.168.100")
+
+			decision, err := accessPolicyService.Evaluate(cyprusCtx, dB, http.MethodPost, "/v1/companies")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeTrue)
+			So(decision.SkipAuthentication, ShouldBeTrue)
+			So(decision.Rule.Name, ShouldEqual, "cyprus_unauthenticated_company_creation")
+
+			decision, err = accessPolicyService.Evaluate(cyprusCtx, dB, http.MethodDelete, "/v1/companies/:id")
+			So(err, ShouldBeNil)
+			So(decision.SkipAuthentication, ShouldBeFalse)
+		})
+
+		Convey("treats requests from unknown locations as having no country", func() {
+
+			unknownCtx := ctxhelper.WithIpAddress(ctx, "10.0.0.1")
+
+			decision, err := accessPolicyService.Evaluate(unknownCtx, dB, http.MethodPut, "/v1/tags/:id")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeTrue)
+			So(decision.Country, ShouldEqual, "")
+		})
+
+		Convey("fails when the country cannot be looked up", func() {
+
+			failingCtx := ctxhelper.WithIpAddress(ctx, "0.0.0.0")
+
+			_, err := accessPolicyService.Evaluate(failingCtx, dB, http.MethodPut, "/v1/tags/:id")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("skips rules when the country lookup fails open", func() {
+
+			accessPolicyService := NewAccessPolicyServiceWithConfig(
+				accessPolicyRuleRepository,
+				ipAPI,
+				entities.AccessPolicyModeEnforce,
+				[]*entities.AccessPolicyRule{
+					{
+						Name:      "cyprus_company_writes",
+						RuleType:  entities.AccessPolicyRuleTypeRestrict,
+						Countries: []string{"CY"},
+						Methods:   []string{http.MethodPost},
+						Routes:    []string{"/v1/companies"},
+						Enabled:   true,
+					},
+				},
+			)
+
+			unavailableCtx := ctxhelper.WithIpAddress(ctx, "0.0.0.1")
+
+			decision, err := accessPolicyService.Evaluate(unavailableCtx, dB, http.MethodPost, "/v1/companies")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeTrue)
+
+			unknownCtx := ctxhelper.WithIpAddress(ctx, "10.0.0.1")
+
+			decision, err = accessPolicyService.Evaluate(unknownCtx, dB, http.MethodPost, "/v1/companies")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeFalse)
+		})
+
+		Convey("does not look up the country when no rule depends on it", func() {
+
+			accessPolicyService := NewAccessPolicyServiceWithConfig(
+				accessPolicyRuleRepository,
+				ipAPI,
+				entities.AccessPolicyModeEnforce,
+				[]*entities.AccessPolicyRule{},
+			)
+
+			decision, err := accessPolicyService.Evaluate(kenyaCtx, dB, http.MethodGet, "/v1/companies")
+			So(err, ShouldBeNil)
+			So(decision.Allowed, ShouldBeTrue)
+			So(ipAPI.lookups, ShouldEqual, 0)
+		})
+	}))
+}
+
+func TestLoadAccessPolicyRules(t *testing.T) {
+
+	Convey("LoadAccessPolicyRules", t, func() {
+
+		dir, err := ioutil.TempDir("", "access-policy")
+		So(err, ShouldBeNil)
+
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "rules.json")
+
+		Convey("loads rules from a json file", func() {
+
+			err := ioutil.WriteFile(path, []byte(`[{"name": "deny_kenya", "rule_type": "deny", "countries": ["KE"]}]`), 0o600)
+			So(err, ShouldBeNil)
+
+			rules, err := LoadAccessPolicyRules(path)
+			So(err, ShouldBeNil)
+			So(len(rules), ShouldEqual, 1)
+			So(rules[0].RuleType, ShouldEqual, entities.AccessPolicyRuleTypeDeny)
+			So(rules[0].Countries, ShouldResemble, []string{"KE"})
+			So(rules[0].Enabled, ShouldBeTrue)
+		})
+
+		Convey("rejects rules of an unknown type", func() {
+
+			err := ioutil.WriteFile(path, []byte(`[{"name": "allow_kenya", "rule_type": "allow", "countries": ["KE"]}]`), 0o600)
+			So(err, ShouldBeNil)
+
+			_, err = LoadAccessPolicyRules(path)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
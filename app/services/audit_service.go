@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+)
+
+var companyAuditIgnoredFields = []string{"id", "countries", "status_history", "created_at", "updated_at", "version"}
+
+type (
+	AuditService interface {
+		ListAuditLogs(ctx context.Context, dB db.DB, filter *forms.AuditFilter) (*entities.AuditLogList, error)
+	}
+
+	AppAuditService struct {
+		auditLogRepository repos.AuditLogRepository
+	}
+)
+
+func NewAuditService(
+	auditLogRepository repos.AuditLogRepository,
+) *AppAuditService {
+	return &AppAuditService{
+		auditLogRepository: auditLogRepository,
+	}
+}
+
+func NewTestAuditService() *AppAuditService {
+	return NewAuditService(
+		repos.NewAuditLogRepository(),
+	)
+}
+
+func (s *AppAuditService) ListAuditLogs(
+	ctx context.Context,
+	dB db.DB,
+	filter *forms.AuditFilter,
+) (*entities.AuditLogList, error) {
+
+	auditLogs, err := s.auditLogRepository.ListAuditLogs(ctx, dB, filter)
+	if err != nil {
+		return &entities.AuditLogList{}, err
+	}
+
+	count, err := s.auditLogRepository.AuditLogCount(ctx, dB, filter)
+	if err != nil {
+		return &entities.AuditLogList{}, err
+	}
+
+	auditLogList := &entities.AuditLogList{
+		AuditLogs:  auditLogs,
+		Pagination: entities.NewPagination(count, filter.Page, filter.Per),
+	}
+
+	return auditLogList, nil
+}
+
+func recordCompanyAudit(
+	ctx context.Context,
+	operations db.SQLOperations,
+	auditLogRepository repos.AuditLogRepository,
+	action entities.AuditAction,
+	companyID int64,
+	before,
+	after *entities.Company,
+) error {
+
+	changes, err := utils.DiffJSON(before, after, companyAuditIgnoredFields...)
+	if err != nil {
+		return err
+	}
+
+	return saveCompanyAuditLog(ctx, operations, auditLogRepository, action, companyID, changes)
+}
+
+func saveCompanyAuditLog(
+	ctx context.Context,
+	operations db.SQLOperations,
+	auditLogRepository repos.AuditLogRepository,
+	action entities.AuditAction,
+	companyID int64,
+	changes map[string]*entities.FieldChange,
+) error {
+
+	auditLog := &entities.AuditLog{
+		Action:       action,
+		Changes:      changes,
+		IPAddress:    ctxhelper.IPAddress(ctx),
+		RequestID:    ctxhelper.RequestId(ctx),
+		ResourceID:   companyID,
+		ResourceType: entities.AuditResourceCompany,
+		UserAgent:    ctxhelper.UserAgent(ctx),
+	}
+
+	if userID := ctxhelper.UserID(ctx); userID != 0 {
+		auditLog.ActorID = null.IntFrom(userID)
+	}
+
+	return auditLogRepository.Save(ctx, operations, auditLog)
+}
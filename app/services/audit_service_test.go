@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAuditService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	auditService := NewTestAuditService()
+	companyService := NewTestCompanyService()
+
+	Convey("Audit Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		_, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		user, err := repos.CreateUser(ctx, dB)
+		So(err, ShouldBeNil)
+
+		ctx = ctxhelper.WithUserID(ctx, user.ID)
+		ctx = ctxhelper.WithRequestId(ctx, utils.GenerateUUID())
+		ctx = ctxhelper.WithIpAddress(ctx, "176.56.168.100")
+
+		form := &forms.CreateCompanyForm{
+			Name:    "Microsoft",
+			Code:    "SOFT",
+			Country: "cyprus",
+			Website: "https://microsoft.com",
+			Phone:   "+35790034567",
+		}
+
+		company, err := companyService.CreateCompany(ctx, dB, form)
+		So(err, ShouldBeNil)
+
+		Convey("records company creation", func() {
+
+			filter := &forms.AuditFilter{
+				Page:         1,
+				Per:          10,
+				ResourceID:   company.ID,
+				ResourceType: entities.AuditResourceCompany,
+			}
+
+			auditLogList, err := auditService.ListAuditLogs(ctx, dB, filter)
+			So(err, ShouldBeNil)
+			So(len(auditLogList.AuditLogs), ShouldEqual, 1)
+
+			auditLog := auditLogList.AuditLogs[0]
+			So(auditLog.Action, ShouldEqual, entities.AuditActionCreate)
+			So(auditLog.ActorID.Int64, ShouldEqual, user.ID)
+			So(auditLog.IPAddress, ShouldEqual, "176.56.168.100")
+			So(auditLog.RequestID, ShouldEqual, ctxhelper.RequestId(ctx))
+			So(auditLog.Changes["name"].To, ShouldEqual, "Microsoft")
+		})
+
+		Convey("records company updates with changed fields only", func() {
+
+			_, err := companyService.UpdateCompany(ctx, dB, company.ID, &forms.UpdateCompanyForm{
+				Name: null.StringFrom("Microsoft Corp"),
+			})
+			So(err, ShouldBeNil)
+
+			filter := &forms.AuditFilter{
+				Page:       1,
+				Per:        10,
+				Action:     string(entities.AuditActionUpdate),
+				ActorID:    user.ID,
+				ResourceID: company.ID,
+			}
+
+			auditLogList, err := auditService.ListAuditLogs(ctx, dB, filter)
+			So(err, ShouldBeNil)
+			So(len(auditLogList.AuditLogs), ShouldEqual, 1)
+
+			changes := auditLogList.AuditLogs[0].Changes
+			So(len(changes), ShouldEqual, 1)
+			So(changes["name"].From, ShouldEqual, "Microsoft")
+			So(changes["name"].To, ShouldEqual, "Microsoft Corp")
+		})
+
+		Convey("records company deletion", func() {
+
+			_, err := companyService.DeleteCompany(ctx, dB, company.ID, null.Int{})
+			So(err, ShouldBeNil)
+
+			filter := &forms.AuditFilter{
+				Page:   1,
+				Per:    10,
+				Action: string(entities.AuditActionDelete),
+			}
+
+			auditLogList, err := auditService.ListAuditLogs(ctx, dB, filter)
+			So(err, ShouldBeNil)
+			So(len(auditLogList.AuditLogs), ShouldEqual, 1)
+			So(auditLogList.AuditLogs[0].ResourceID, ShouldEqual, company.ID)
+		})
+	}))
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+type (
+	CompanyAddressService interface {
+		AddCompanyAddress(ctx context.Context, dB db.DB, companyID, countryID int64, form *forms.CreateCompanyAddressForm) (*entities.CompanyAddress, error)
+		ListCompanyAddresses(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyAddress, error)
+		RemoveCompanyAddress(ctx context.Context, dB db.DB, companyID, addressID int64) (*entities.CompanyAddress, error)
+		UpdateCompanyAddress(ctx context.Context, dB db.DB, companyID, addressID int64, form *forms.UpdateCompanyAddressForm) (*entities.CompanyAddress, error)
+	}
+
+	AppCompanyAddressService struct {
+		companyAddressRepository repos.CompanyAddressRepository
+		companyCountryRepository repos.CompanyCountryRepository
+		companyRepository        repos.CompanyRepository
+	}
+)
+
+func NewCompanyAddressService(
+	companyAddressRepository repos.CompanyAddressRepository,
+	companyCountryRepository repos.CompanyCountryRepository,
+	companyRepository repos.CompanyRepository,
+) *AppCompanyAddressService {
+	return &AppCompanyAddressService{
+		companyAddressRepository: companyAddressRepository,
+		companyCountryRepository: companyCountryRepository,
+		companyRepository:        companyRepository,
+	}
+}
+
+func NewTestCompanyAddressService() *AppCompanyAddressService {
+	return NewCompanyAddressService(
+		repos.NewCompanyAddressRepository(),
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyRepository(),
+	)
+}
+
+func (s *AppCompanyAddressService) AddCompanyAddress(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	countryID int64,
+	form *forms.CreateCompanyAddressForm,
+) (*entities.CompanyAddress, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	companyCountry, err := s.companyCountryRepository.CompanyCountryByCompanyAndCountry(ctx, dB, company.ID, countryID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyAddress{}, err
+		}
+
+		return &entities.CompanyAddress{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company country not found",
+		)
+	}
+
+	address := &entities.CompanyAddress{
+		CompanyID:        company.ID,
+		CompanyCountryID: companyCountry.ID,
+		CountryCode:      companyCountry.CountryCode,
+		CountryName:      companyCountry.CountryName,
+	}
+
+	err = s.setAddressDetails(address, form.AddressType, form.Line1, form.Line2, form.City, form.Region, form.PostalCode)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	err = s.validateDuplicateAddressType(ctx, dB, address)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	err = s.companyAddressRepository.Save(ctx, dB, address)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	return address, nil
+}
+
+func (s *AppCompanyAddressService) ListCompanyAddresses(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyAddress, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyAddress{}, err
+	}
+
+	return s.companyAddressRepository.CompanyAddressesByCompanyID(ctx, dB, company.ID)
+}
+
+func (s *AppCompanyAddressService) RemoveCompanyAddress(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	addressID int64,
+) (*entities.CompanyAddress, error) {
+
+	address, err := s.getCompanyAddress(ctx, dB, companyID, addressID)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	err = s.companyAddressRepository.DeleteCompanyAddress(ctx, dB, address.ID)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	return address, nil
+}
+
+func (s *AppCompanyAddressService) UpdateCompanyAddress(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	addressID int64,
+	form *forms.UpdateCompanyAddressForm,
+) (*entities.CompanyAddress, error) {
+
+	address, err := s.getCompanyAddress(ctx, dB, companyID, addressID)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	addressType := address.AddressType.String()
+	if form.AddressType.Valid {
+		addressType = form.AddressType.String
+	}
+
+	line1 := address.Line1
+	if form.Line1.Valid {
+		line1 = form.Line1.String
+	}
+
+	line2 := address.Line2
+	if form.Line2.Valid {
+		line2 = form.Line2.String
+	}
+
+	city := address.City
+	if form.City.Valid {
+		city = form.City.String
+	}
+
+	region := address.Region
+	if form.Region.Valid {
+		region = form.Region.String
+	}
+
+	postalCode := address.PostalCode
+	if form.PostalCode.Valid {
+		postalCode = form.PostalCode.String
+	}
+
+	err = s.setAddressDetails(address, addressType, line1, line2, city, region, postalCode)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	err = s.validateDuplicateAddressType(ctx, dB, address)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	err = s.companyAddressRepository.Save(ctx, dB, address)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	return address, nil
+}
+
+// setAddressDetails validates and applies the address fields. The postal code
+// is checked against the format of the country the address is held under.
+func (s *AppCompanyAddressService) setAddressDetails(
+	address *entities.CompanyAddress,
+	addressType,
+	line1,
+	line2,
+	city,
+	region,
+	postalCode string,
+) error {
+
+	parsedAddressType := entities.AddressType(strings.ToLower(strings.TrimSpace(addressType)))
+	if !parsedAddressType.IsValid() {
+		return utils.NewErrorWithCode(
+			errors.New("invalid address type"),
+			utils.ErrorCodeInvalidForm,
+			"unknown address type = %v",
+			addressType,
+		)
+	}
+
+	line1 = strings.TrimSpace(line1)
+	city = strings.TrimSpace(city)
+
+	if line1 == "" || city == "" {
+		return utils.NewErrorWithCode(
+			errors.New("address line1 and city are required"),
+			utils.ErrorCodeInvalidForm,
+			"missing company address line1 or city",
+		)
+	}
+
+	normalizedPostalCode, err := utils.NormalizePostalCode(address.CountryCode, postalCode)
+	if err != nil {
+		return err
+	}
+
+	address.AddressType = parsedAddressType
+	address.Line1 = line1
+	address.Line2 = strings.TrimSpace(line2)
+	address.City = city
+	address.Region = strings.TrimSpace(region)
+	address.PostalCode = normalizedPostalCode
+
+	return nil
+}
+
+// validateDuplicateAddressType allows a single registered office per company
+// and a single billing address per operating country. A company may have any
+// number of operating addresses.
+func (s *AppCompanyAddressService) validateDuplicateAddressType(
+	ctx context.Context,
+	dB db.DB,
+	address *entities.CompanyAddress,
+) error {
+
+	if address.AddressType == entities.AddressTypeOperating {
+		return nil
+	}
+
+	addresses, err := s.companyAddressRepository.CompanyAddressesByCompanyID(ctx, dB, address.CompanyID)
+	if err != nil {
+		return err
+	}
+
+	for _, existingAddress := range addresses {
+
+		if existingAddress.ID == address.ID || existingAddress.AddressType != address.AddressType {
+			continue
+		}
+
+		if address.AddressType == entities.AddressTypeRegistered || existingAddress.CompanyCountryID == address.CompanyCountryID {
+			return utils.NewErrorWithCode(
+				errors.New("company address already exists"),
+				utils.ErrorCodeResourceExists,
+				"duplicate %v address for company = %v",
+				address.AddressType,
+				address.CompanyID,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *AppCompanyAddressService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppCompanyAddressService) getCompanyAddress(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	addressID int64,
+) (*entities.CompanyAddress, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyAddress{}, err
+	}
+
+	address, err := s.companyAddressRepository.CompanyAddressByCompanyAndID(ctx, dB, company.ID, addressID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyAddress{}, err
+		}
+
+		return &entities.CompanyAddress{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company address not found",
+		)
+	}
+
+	return address, nil
+}
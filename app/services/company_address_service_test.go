@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyAddressService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyAddressService := NewTestCompanyAddressService()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Address Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		greece := &entities.Country{
+			CountryCode:  "GR",
+			Currency:     "EUR",
+			Name:         "Greece",
+			DiallingCode: "+30",
+		}
+
+		err = repos.NewCountryRepository().Save(ctx, dB, greece)
+		So(err, ShouldBeNil)
+
+		company, companyCountry, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		greekCompanyCountry, err := repos.CreateCompanyCountry(ctx, dB, company.ID, greece.ID)
+		So(err, ShouldBeNil)
+
+		Convey("can add an address to a company country", func() {
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "Registered",
+				Line1:       " 1 Makarios Avenue ",
+				City:        "Nicosia",
+				PostalCode:  "1065",
+			}
+
+			address, err := companyAddressService.AddCompanyAddress(ctx, dB, company.ID, country.ID, form)
+			So(err, ShouldBeNil)
+
+			So(address.ID, ShouldNotBeZeroValue)
+			So(address.CompanyCountryID, ShouldEqual, companyCountry.ID)
+			So(address.AddressType, ShouldEqual, entities.AddressTypeRegistered)
+			So(address.Line1, ShouldEqual, "1 Makarios Avenue")
+			So(address.CountryCode, ShouldEqual, "CY")
+
+			foundCompany, err := companyService.GetCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(foundCompany.Addresses), ShouldEqual, 1)
+			So(foundCompany.Addresses[0].ID, ShouldEqual, address.ID)
+			So(foundCompany.Addresses[0].PostalCode, ShouldEqual, "1065")
+		})
+
+		Convey("normalizes the postal code for the country", func() {
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "operating",
+				Line1:       "Ermou 10",
+				City:        "Athens",
+				PostalCode:  "10563",
+			}
+
+			address, err := companyAddressService.AddCompanyAddress(ctx, dB, company.ID, greece.ID, form)
+			So(err, ShouldBeNil)
+			So(address.CompanyCountryID, ShouldEqual, greekCompanyCountry.ID)
+			So(address.PostalCode, ShouldEqual, "105 63")
+		})
+
+		Convey("cannot add an address with an invalid postal code", func() {
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "billing",
+				Line1:       "1 Makarios Avenue",
+				City:        "Nicosia",
+				PostalCode:  "SW1A 1AA",
+			}
+
+			_, err := companyAddressService.AddCompanyAddress(ctx, dB, company.ID, country.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidPostalCode)
+		})
+
+		Convey("cannot add an address with an unknown type", func() {
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "shipping",
+				Line1:       "1 Makarios Avenue",
+				City:        "Nicosia",
+				PostalCode:  "1065",
+			}
+
+			_, err := companyAddressService.AddCompanyAddress(ctx, dB, company.ID, country.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("cannot add an address for a country the company does not operate in", func() {
+
+			italy := &entities.Country{
+				CountryCode:  "IT",
+				Currency:     "EUR",
+				Name:         "Italy",
+				DiallingCode: "+39",
+			}
+
+			err := repos.NewCountryRepository().Save(ctx, dB, italy)
+			So(err, ShouldBeNil)
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "operating",
+				Line1:       "Via Roma 1",
+				City:        "Rome",
+				PostalCode:  "00184",
+			}
+
+			_, err = companyAddressService.AddCompanyAddress(ctx, dB, company.ID, italy.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+
+		Convey("a company has a single registered office", func() {
+
+			_, err := repos.CreateCompanyAddress(ctx, dB, companyCountry.ID, entities.AddressTypeRegistered)
+			So(err, ShouldBeNil)
+
+			form := &forms.CreateCompanyAddressForm{
+				AddressType: "registered",
+				Line1:       "Ermou 10",
+				City:        "Athens",
+				PostalCode:  "105 63",
+			}
+
+			_, err = companyAddressService.AddCompanyAddress(ctx, dB, company.ID, greece.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("a company can have several operating addresses", func() {
+
+			_, err := repos.CreateCompanyAddress(ctx, dB, companyCountry.ID, entities.AddressTypeOperating)
+			So(err, ShouldBeNil)
+
+			_, err = repos.CreateCompanyAddress(ctx, dB, companyCountry.ID, entities.AddressTypeOperating)
+			So(err, ShouldBeNil)
+
+			addresses, err := companyAddressService.ListCompanyAddresses(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(addresses), ShouldEqual, 2)
+		})
+
+		Convey("can update an address", func() {
+
+			address, err := repos.CreateCompanyAddress(ctx, dB, companyCountry.ID, entities.AddressTypeOperating)
+			So(err, ShouldBeNil)
+
+			form := &forms.UpdateCompanyAddressForm{
+				AddressType: null.StringFrom("billing"),
+				PostalCode:  null.StringFrom("3036"),
+			}
+
+			updatedAddress, err := companyAddressService.UpdateCompanyAddress(ctx, dB, company.ID, address.ID, form)
+			So(err, ShouldBeNil)
+			So(updatedAddress.AddressType, ShouldEqual, entities.AddressTypeBilling)
+			So(updatedAddress.Line1, ShouldEqual, address.Line1)
+			So(updatedAddress.PostalCode, ShouldEqual, "3036")
+		})
+
+		Convey("can remove an address", func() {
+
+			address, err := repos.CreateCompanyAddress(ctx, dB, companyCountry.ID, entities.AddressTypeBilling)
+			So(err, ShouldBeNil)
+
+			_, err = companyAddressService.RemoveCompanyAddress(ctx, dB, company.ID, address.ID)
+			So(err, ShouldBeNil)
+
+			addresses, err := companyAddressService.ListCompanyAddresses(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(addresses), ShouldEqual, 0)
+		})
+
+		Convey("addresses are removed with their company country", func() {
+
+			_, err := repos.CreateCompanyAddress(ctx, dB, greekCompanyCountry.ID, entities.AddressTypeOperating)
+			So(err, ShouldBeNil)
+
+			_, err = NewTestCompanyCountryService().RemoveCompanyCountry(ctx, dB, company.ID, greece.ID)
+			So(err, ShouldBeNil)
+
+			addresses, err := companyAddressService.ListCompanyAddresses(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(addresses), ShouldEqual, 0)
+		})
+	}))
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const maxCompanyContactFieldLength = 100
+
+type (
+	CompanyContactService interface {
+		AddCompanyContact(ctx context.Context, dB db.DB, companyID int64, form *forms.CreateCompanyContactForm) (*entities.CompanyContact, error)
+		GetCompanyContact(ctx context.Context, dB db.DB, companyID, contactID int64) (*entities.CompanyContact, error)
+		ListCompanyContacts(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyContact, error)
+		RemoveCompanyContact(ctx context.Context, dB db.DB, companyID, contactID int64) (*entities.CompanyContact, error)
+		UpdateCompanyContact(ctx context.Context, dB db.DB, companyID, contactID int64, form *forms.UpdateCompanyContactForm) (*entities.CompanyContact, error)
+	}
+
+	AppCompanyContactService struct {
+		companyContactRepository repos.CompanyContactRepository
+		companyRepository        repos.CompanyRepository
+	}
+)
+
+func NewCompanyContactService(
+	companyContactRepository repos.CompanyContactRepository,
+	companyRepository repos.CompanyRepository,
+) *AppCompanyContactService {
+	return &AppCompanyContactService{
+		companyContactRepository: companyContactRepository,
+		companyRepository:        companyRepository,
+	}
+}
+
+func NewTestCompanyContactService() *AppCompanyContactService {
+	return NewCompanyContactService(
+		repos.NewCompanyContactRepository(),
+		repos.NewCompanyRepository(),
+	)
+}
+
+func (s *AppCompanyContactService) AddCompanyContact(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CreateCompanyContactForm,
+) (*entities.CompanyContact, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	contact := &entities.CompanyContact{
+		CompanyID: company.ID,
+		IsPrimary: form.IsPrimary,
+	}
+
+	err = s.setContactDetails(contact, form.Name, form.Role, form.Email, form.Phone)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	err = s.validateDuplicateContactEmail(ctx, dB, contact)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	err = s.saveContact(ctx, dB, contact)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	return contact, nil
+}
+
+func (s *AppCompanyContactService) GetCompanyContact(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	contactID int64,
+) (*entities.CompanyContact, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	return s.getCompanyContact(ctx, dB, company.ID, contactID)
+}
+
+func (s *AppCompanyContactService) ListCompanyContacts(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyContact, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyContact{}, err
+	}
+
+	return s.companyContactRepository.CompanyContactsByCompanyID(ctx, dB, company.ID)
+}
+
+func (s *AppCompanyContactService) RemoveCompanyContact(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	contactID int64,
+) (*entities.CompanyContact, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	contact, err := s.getCompanyContact(ctx, dB, company.ID, contactID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	err = s.companyContactRepository.DeleteCompanyContact(ctx, dB, contact.ID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	return contact, nil
+}
+
+func (s *AppCompanyContactService) UpdateCompanyContact(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	contactID int64,
+	form *forms.UpdateCompanyContactForm,
+) (*entities.CompanyContact, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	contact, err := s.getCompanyContact(ctx, dB, company.ID, contactID)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	name := contact.Name
+	if form.Name.Valid {
+		name = form.Name.String
+	}
+
+	role := contact.Role
+	if form.Role.Valid {
+		role = form.Role.String
+	}
+
+	email := contact.Email.String
+	if form.Email.Valid {
+		email = form.Email.String
+	}
+
+	phone := contact.Phone
+	if form.Phone.Valid {
+		phone = form.Phone.String
+	}
+
+	if form.IsPrimary.Valid {
+		contact.IsPrimary = form.IsPrimary.Bool
+	}
+
+	err = s.setContactDetails(contact, name, role, email, phone)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	err = s.validateDuplicateContactEmail(ctx, dB, contact)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	err = s.saveContact(ctx, dB, contact)
+	if err != nil {
+		return &entities.CompanyContact{}, err
+	}
+
+	return contact, nil
+}
+
+// saveContact saves the contact, taking over the primary flag from any other
+// contact of the company when it is marked primary.
+func (s *AppCompanyContactService) saveContact(
+	ctx context.Context,
+	dB db.DB,
+	contact *entities.CompanyContact,
+) error {
+
+	return dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		if contact.IsPrimary {
+			err := s.companyContactRepository.UnsetPrimaryCompanyContact(ctx, operations, contact.CompanyID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.companyContactRepository.Save(ctx, operations, contact)
+	})
+}
+
+// setContactDetails validates and applies the contact fields. A contact needs
+// a name and at least one of an email or a phone number to be reachable.
+func (s *AppCompanyContactService) setContactDetails(
+	contact *entities.CompanyContact,
+	name,
+	role,
+	email,
+	phone string,
+) error {
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return utils.NewErrorWithCode(
+			errors.New("contact name is required"),
+			utils.ErrorCodeInvalidForm,
+			"missing company contact name",
+		)
+	}
+
+	role = strings.TrimSpace(role)
+
+	if len(name) > maxCompanyContactFieldLength || len(role) > maxCompanyContactFieldLength {
+		return utils.NewErrorWithCode(
+			errors.New("contact field too long"),
+			utils.ErrorCodeInvalidForm,
+			"company contact name and role must be at most %v characters",
+			maxCompanyContactFieldLength,
+		)
+	}
+
+	email = strings.TrimSpace(email)
+	phone = strings.TrimSpace(phone)
+
+	if email == "" && phone == "" {
+		return utils.NewErrorWithCode(
+			errors.New("contact email or phone is required"),
+			utils.ErrorCodeInvalidForm,
+			"company contact has neither email nor phone",
+		)
+	}
+
+	contact.Email = null.String{}
+	if email != "" {
+		normalizedEmail, err := utils.NormalizeEmail(email)
+		if err != nil {
+			return err
+		}
+
+		contact.Email = null.StringFrom(normalizedEmail)
+	}
+
+	contact.PhoneNumber = entities.PhoneNumber{}
+	if phone != "" {
+		phoneNumber, err := utils.ParsePhoneNumber(phone)
+		if err != nil {
+			return err
+		}
+
+		contact.PhoneNumber = phoneNumber
+	}
+
+	contact.Name = name
+	contact.Role = role
+	contact.Phone = contact.PhoneNumber.Phone()
+
+	return nil
+}
+
+func (s *AppCompanyContactService) validateDuplicateContactEmail(
+	ctx context.Context,
+	dB db.DB,
+	contact *entities.CompanyContact,
+) error {
+
+	if !contact.Email.Valid {
+		return nil
+	}
+
+	existingContact, err := s.companyContactRepository.CompanyContactByCompanyAndEmail(ctx, dB, contact.CompanyID, contact.Email.String)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return err
+		}
+		return nil
+	}
+
+	if existingContact.ID == contact.ID {
+		return nil
+	}
+
+	return utils.NewErrorWithCode(
+		errors.New("company contact email already exists"),
+		utils.ErrorCodeResourceExists,
+		"duplicate company contact email",
+	)
+}
+
+func (s *AppCompanyContactService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppCompanyContactService) getCompanyContact(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	contactID int64,
+) (*entities.CompanyContact, error) {
+
+	contact, err := s.companyContactRepository.CompanyContactByCompanyAndID(ctx, dB, companyID, contactID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyContact{}, err
+		}
+
+		return &entities.CompanyContact{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company contact not found",
+		)
+	}
+
+	return contact, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyContactService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyContactService := NewTestCompanyContactService()
+
+	Convey("Company Contact Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		Convey("can add a contact to a company", func() {
+
+			form := &forms.CreateCompanyContactForm{
+				Name:  " Jane Doe ",
+				Role:  "CFO",
+				Email: "Jane.Doe@Example.com",
+				Phone: "+35790034567",
+			}
+
+			contact, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, form)
+			So(err, ShouldBeNil)
+
+			So(contact.ID, ShouldNotBeZeroValue)
+			So(contact.CompanyID, ShouldEqual, company.ID)
+			So(contact.Name, ShouldEqual, "Jane Doe")
+			So(contact.Email.String, ShouldEqual, "Jane.Doe@Example.com")
+			So(contact.Phone, ShouldEqual, "+35790034567")
+
+			foundContact, err := companyContactService.GetCompanyContact(ctx, dB, company.ID, contact.ID)
+			So(err, ShouldBeNil)
+			So(foundContact.Email.String, ShouldEqual, contact.Email.String)
+			So(foundContact.Phone, ShouldEqual, contact.Phone)
+		})
+
+		Convey("cannot add a contact with an invalid email", func() {
+
+			form := &forms.CreateCompanyContactForm{
+				Name:  "Jane Doe",
+				Email: "jane.doe",
+			}
+
+			_, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidEmail)
+		})
+
+		Convey("cannot add a contact with an invalid phone", func() {
+
+			form := &forms.CreateCompanyContactForm{
+				Name:  "Jane Doe",
+				Phone: "12345",
+			}
+
+			_, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidPhone)
+		})
+
+		Convey("cannot add a contact without an email or phone", func() {
+
+			form := &forms.CreateCompanyContactForm{
+				Name: "Jane Doe",
+			}
+
+			_, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("cannot add a contact with an existing email", func() {
+
+			contact, err := repos.CreateCompanyContact(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			form := &forms.CreateCompanyContactForm{
+				Name:  "Jane Doe",
+				Email: contact.Email.String,
+			}
+
+			_, err = companyContactService.AddCompanyContact(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("cannot add a contact to a non-existent company", func() {
+
+			form := &forms.CreateCompanyContactForm{
+				Name:  "Jane Doe",
+				Email: "jane.doe@example.com",
+			}
+
+			_, err := companyContactService.AddCompanyContact(ctx, dB, company.ID+1000, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+
+		Convey("a new primary contact replaces the previous one", func() {
+
+			first, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, &forms.CreateCompanyContactForm{
+				Name:      "Jane Doe",
+				Email:     "jane.doe@example.com",
+				IsPrimary: true,
+			})
+			So(err, ShouldBeNil)
+			So(first.IsPrimary, ShouldBeTrue)
+
+			second, err := companyContactService.AddCompanyContact(ctx, dB, company.ID, &forms.CreateCompanyContactForm{
+				Name:      "John Doe",
+				Email:     "john.doe@example.com",
+				IsPrimary: true,
+			})
+			So(err, ShouldBeNil)
+			So(second.IsPrimary, ShouldBeTrue)
+
+			contacts, err := companyContactService.ListCompanyContacts(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(contacts), ShouldEqual, 2)
+			So(contacts[0].ID, ShouldEqual, second.ID)
+			So(contacts[0].IsPrimary, ShouldBeTrue)
+			So(contacts[1].ID, ShouldEqual, first.ID)
+			So(contacts[1].IsPrimary, ShouldBeFalse)
+		})
+
+		Convey("can update a contact", func() {
+
+			contact, err := repos.CreateCompanyContact(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			form := &forms.UpdateCompanyContactForm{
+				Role:      null.StringFrom("CEO"),
+				Phone:     null.StringFrom(""),
+				IsPrimary: null.BoolFrom(true),
+			}
+
+			updatedContact, err := companyContactService.UpdateCompanyContact(ctx, dB, company.ID, contact.ID, form)
+			So(err, ShouldBeNil)
+			So(updatedContact.Name, ShouldEqual, contact.Name)
+			So(updatedContact.Role, ShouldEqual, "CEO")
+			So(updatedContact.Phone, ShouldBeEmpty)
+			So(updatedContact.IsPrimary, ShouldBeTrue)
+
+			Convey("but not remove both email and phone", func() {
+
+				form := &forms.UpdateCompanyContactForm{
+					Email: null.StringFrom(""),
+				}
+
+				_, err := companyContactService.UpdateCompanyContact(ctx, dB, company.ID, contact.ID, form)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+			})
+		})
+
+		Convey("can remove a contact", func() {
+
+			contact, err := repos.CreateCompanyContact(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyContactService.RemoveCompanyContact(ctx, dB, company.ID, contact.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyContactService.GetCompanyContact(ctx, dB, company.ID, contact.ID)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+	}))
+}
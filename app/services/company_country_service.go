@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+type (
+	CompanyCountryService interface {
+		AddCompanyCountry(ctx context.Context, dB db.DB, companyID int64, form *forms.CreateCompanyCountryForm) (*entities.CompanyCountry, error)
+		ListCompanyCountries(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyCountry, error)
+		RemoveCompanyCountry(ctx context.Context, dB db.DB, companyID, countryID int64) (*entities.CompanyCountry, error)
+		UpdateCompanyCountry(ctx context.Context, dB db.DB, companyID, countryID int64, form *forms.UpdateCompanyCountryForm) (*entities.CompanyCountry, error)
+	}
+
+	AppCompanyCountryService struct {
+		companyCountryRepository repos.CompanyCountryRepository
+		companyRepository        repos.CompanyRepository
+		countryReposistory       repos.CountryRepository
+	}
+)
+
+func NewCompanyCountryService(
+	companyCountryRepository repos.CompanyCountryRepository,
+	companyRepository repos.CompanyRepository,
+	countryReposistory repos.CountryRepository,
+) *AppCompanyCountryService {
+	return &AppCompanyCountryService{
+		companyCountryRepository: companyCountryRepository,
+		companyRepository:        companyRepository,
+		countryReposistory:       countryReposistory,
+	}
+}
+
+func NewTestCompanyCountryService() *AppCompanyCountryService {
+	return NewCompanyCountryService(
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyRepository(),
+		repos.NewCountryRepository(),
+	)
+}
+
+func (s *AppCompanyCountryService) AddCompanyCountry(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CreateCompanyCountryForm,
+) (*entities.CompanyCountry, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	operationStatus := entities.OperationStatusTypePending
+	if form.OperationStatus.Valid {
+		operationStatus, err = s.parseOperationStatus(form.OperationStatus.String)
+		if err != nil {
+			return &entities.CompanyCountry{}, err
+		}
+	}
+
+	country, err := s.countryReposistory.CountryByName(ctx, dB, form.Country)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyCountry{}, err
+		}
+
+		return &entities.CompanyCountry{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"country not found",
+		)
+	}
+
+	_, err = s.companyCountryRepository.CompanyCountryByCompanyAndCountry(ctx, dB, company.ID, country.ID)
+	if err == nil {
+		return &entities.CompanyCountry{}, utils.NewErrorWithCode(
+			errors.New("company country already exists"),
+			utils.ErrorCodeResourceExists,
+			"duplicate company country",
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.CompanyCountry{}, err
+	}
+
+	companyCountry := &entities.CompanyCountry{
+		CompanyID:       company.ID,
+		CountryID:       country.ID,
+		CountryCode:     country.CountryCode,
+		CountryName:     country.Name,
+		OperationStatus: operationStatus,
+	}
+
+	err = s.companyCountryRepository.Save(ctx, dB, companyCountry)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	return companyCountry, nil
+}
+
+func (s *AppCompanyCountryService) ListCompanyCountries(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyCountry, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyCountry{}, err
+	}
+
+	return s.companyCountryRepository.CompanyCountriesByCompanyID(ctx, dB, company.ID)
+}
+
+func (s *AppCompanyCountryService) RemoveCompanyCountry(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	countryID int64,
+) (*entities.CompanyCountry, error) {
+
+	companyCountry, err := s.getCompanyCountry(ctx, dB, companyID, countryID)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		// Concurrent removals would otherwise both count the other's country
+		// and leave the company with none.
+		err := s.companyRepository.LockCompany(ctx, operations, companyCountry.CompanyID)
+		if err != nil {
+			return err
+		}
+
+		count, err := s.companyCountryRepository.CompanyCountryCount(ctx, operations, companyCountry.CompanyID)
+		if err != nil {
+			return err
+		}
+
+		if count <= 1 {
+			return utils.NewErrorWithCode(
+				errors.New("company must operate in at least one country"),
+				utils.ErrorCodeInvalidArgument,
+				"cannot remove last country for company = %v",
+				companyCountry.CompanyID,
+			)
+		}
+
+		return s.companyCountryRepository.DeleteCompanyCountry(ctx, operations, companyCountry.ID)
+	})
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	return companyCountry, nil
+}
+
+func (s *AppCompanyCountryService) UpdateCompanyCountry(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	countryID int64,
+	form *forms.UpdateCompanyCountryForm,
+) (*entities.CompanyCountry, error) {
+
+	operationStatus, err := s.parseOperationStatus(form.OperationStatus)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	companyCountry, err := s.getCompanyCountry(ctx, dB, companyID, countryID)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	companyCountry.OperationStatus = operationStatus
+
+	err = s.companyCountryRepository.Save(ctx, dB, companyCountry)
+	if err != nil {
+		return &entities.CompanyCountry{}, err
+	}
+
+	return companyCountry, nil
+}
+
+func (s *AppCompanyCountryService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppCompanyCountryService) getCompanyCountry(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	countryID int64,
+) (*entities.CompanyCountry, error) {
+
+	companyCountry, err := s.companyCountryRepository.CompanyCountryByCompanyAndCountry(ctx, dB, companyID, countryID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyCountry{}, err
+		}
+
+		return &entities.CompanyCountry{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company country not found",
+		)
+	}
+
+	return companyCountry, nil
+}
+
+func (s *AppCompanyCountryService) parseOperationStatus(
+	status string,
+) (entities.OperationStatusType, error) {
+
+	operationStatus := entities.OperationStatusType(status)
+	if !operationStatus.IsValid() {
+		return operationStatus, utils.NewErrorWithCode(
+			errors.New("invalid operation status"),
+			utils.ErrorCodeInvalidArgument,
+			"invalid operation status = [%v]",
+			status,
+		)
+	}
+
+	return operationStatus, nil
+}
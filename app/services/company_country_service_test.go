@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyCountryService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyCountryService := NewTestCompanyCountryService()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Country Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		greece := &entities.Country{
+			CountryCode:  "GR",
+			Currency:     "EUR",
+			Name:         "Greece",
+			DiallingCode: "+30",
+		}
+
+		err = repos.NewCountryRepository().Save(ctx, dB, greece)
+		So(err, ShouldBeNil)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		Convey("can add a country to a company", func() {
+
+			form := &forms.CreateCompanyCountryForm{
+				Country: "greece",
+			}
+
+			companyCountry, err := companyCountryService.AddCompanyCountry(ctx, dB, company.ID, form)
+			So(err, ShouldBeNil)
+
+			So(companyCountry.ID, ShouldNotBeZeroValue)
+			So(companyCountry.CountryID, ShouldEqual, greece.ID)
+			So(companyCountry.OperationStatus, ShouldEqual, entities.OperationStatusTypePending)
+
+			foundCompany, err := companyService.GetCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(foundCompany.Countries), ShouldEqual, 2)
+			So(foundCompany.Country, ShouldEqual, country.Name)
+
+			companyList, err := companyService.ListCompanies(ctx, dB, &forms.Filter{Page: 1, Per: 10})
+			So(err, ShouldBeNil)
+			So(len(companyList.Companies), ShouldEqual, 1)
+			So(companyList.Pagination.Count, ShouldEqual, 1)
+		})
+
+		Convey("cannot add a country twice", func() {
+
+			form := &forms.CreateCompanyCountryForm{
+				Country: country.Name,
+			}
+
+			_, err := companyCountryService.AddCompanyCountry(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("cannot add a country with an invalid status", func() {
+
+			form := &forms.CreateCompanyCountryForm{
+				Country:         "greece",
+				OperationStatus: null.StringFrom("dormant"),
+			}
+
+			_, err := companyCountryService.AddCompanyCountry(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidArgument)
+		})
+
+		Convey("can update a company country status", func() {
+
+			form := &forms.UpdateCompanyCountryForm{
+				OperationStatus: "closed",
+			}
+
+			companyCountry, err := companyCountryService.UpdateCompanyCountry(ctx, dB, company.ID, country.ID, form)
+			So(err, ShouldBeNil)
+			So(companyCountry.OperationStatus, ShouldEqual, entities.OperationStatusTypeClosed)
+		})
+
+		Convey("can remove a country from a company", func() {
+
+			_, err := repos.CreateCompanyCountry(ctx, dB, company.ID, greece.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyCountryService.RemoveCompanyCountry(ctx, dB, company.ID, greece.ID)
+			So(err, ShouldBeNil)
+
+			companyCountries, err := companyCountryService.ListCompanyCountries(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(companyCountries), ShouldEqual, 1)
+		})
+
+		Convey("cannot remove the last country from a company", func() {
+
+			_, err := companyCountryService.RemoveCompanyCountry(ctx, dB, company.ID, country.ID)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidArgument)
+		})
+	}))
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// MaxDocumentSize is the largest document accepted for upload.
+	MaxDocumentSize = 10 << 20
+
+	defaultDocumentFileName = "document"
+	documentSniffLength     = 512
+	maxDocumentFileName     = 255
+)
+
+// allowedDocumentContentTypes whitelists the sniffed content types accepted
+// for upload. The type declared by the client is ignored.
+var allowedDocumentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+}
+
+type (
+	CompanyDocumentService interface {
+		DeleteDocument(ctx context.Context, dB db.DB, companyID, documentID int64) (*entities.CompanyDocument, error)
+		ListDocuments(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyDocument, error)
+		OpenDocument(ctx context.Context, dB db.DB, companyID, documentID int64) (*entities.CompanyDocument, io.ReadCloser, error)
+		UploadDocument(ctx context.Context, dB db.DB, companyID int64, fileName string, reader io.Reader) (*entities.CompanyDocument, error)
+	}
+
+	AppCompanyDocumentService struct {
+		blobStore                 providers.BlobStore
+		companyDocumentRepository repos.CompanyDocumentRepository
+		companyRepository         repos.CompanyRepository
+	}
+)
+
+// byteCounter counts the bytes written through it.
+type byteCounter int64
+
+func (c *byteCounter) Write(data []byte) (int, error) {
+	*c += byteCounter(len(data))
+	return len(data), nil
+}
+
+func NewCompanyDocumentService(
+	blobStore providers.BlobStore,
+	companyDocumentRepository repos.CompanyDocumentRepository,
+	companyRepository repos.CompanyRepository,
+) *AppCompanyDocumentService {
+	return &AppCompanyDocumentService{
+		blobStore:                 blobStore,
+		companyDocumentRepository: companyDocumentRepository,
+		companyRepository:         companyRepository,
+	}
+}
+
+func NewTestCompanyDocumentService(blobStore providers.BlobStore) *AppCompanyDocumentService {
+	return NewCompanyDocumentService(
+		blobStore,
+		repos.NewCompanyDocumentRepository(),
+		repos.NewCompanyRepository(),
+	)
+}
+
+// DeleteDocument removes the document record and then its contents. Failing
+// to remove the contents only leaves an unreferenced blob behind, so it is
+// logged rather than returned.
+func (s *AppCompanyDocumentService) DeleteDocument(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	documentID int64,
+) (*entities.CompanyDocument, error) {
+
+	document, err := s.getDocument(ctx, dB, companyID, documentID)
+	if err != nil {
+		return &entities.CompanyDocument{}, err
+	}
+
+	err = s.companyDocumentRepository.DeleteCompanyDocument(ctx, dB, document.ID)
+	if err != nil {
+		return &entities.CompanyDocument{}, err
+	}
+
+	err = s.blobStore.Delete(ctx, document.StorageKey)
+	if err != nil {
+		logger.Errorf("Failed to delete contents of document id = %v key = [%v] err = %v", document.ID, document.StorageKey, err)
+	}
+
+	return document, nil
+}
+
+func (s *AppCompanyDocumentService) ListDocuments(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyDocument, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyDocument{}, err
+	}
+
+	return s.companyDocumentRepository.CompanyDocumentsByCompanyID(ctx, dB, company.ID)
+}
+
+// OpenDocument returns the document along with a reader over its contents,
+// which the caller must close.
+func (s *AppCompanyDocumentService) OpenDocument(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	documentID int64,
+) (*entities.CompanyDocument, io.ReadCloser, error) {
+
+	document, err := s.getDocument(ctx, dB, companyID, documentID)
+	if err != nil {
+		return &entities.CompanyDocument{}, nil, err
+	}
+
+	contents, err := s.blobStore.Get(ctx, document.StorageKey)
+	if err != nil {
+		if errors.Is(err, providers.ErrBlobNotFound) {
+			return &entities.CompanyDocument{}, nil, utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeNotFound,
+				"contents of document id = %v are missing",
+				document.ID,
+			)
+		}
+
+		return &entities.CompanyDocument{}, nil, utils.NewError(
+			err,
+			"failed to open document id = %v",
+			document.ID,
+		)
+	}
+
+	return document, contents, nil
+}
+
+// UploadDocument streams reader into the blob store while computing its
+// size and checksum. The content type is sniffed from the leading bytes.
+func (s *AppCompanyDocumentService) UploadDocument(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	fileName string,
+	reader io.Reader,
+) (*entities.CompanyDocument, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyDocument{}, err
+	}
+
+	head := make([]byte, documentSniffLength)
+
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return &entities.CompanyDocument{}, utils.NewError(
+			err,
+			"failed to read uploaded document",
+		)
+	}
+
+	head = head[:n]
+
+	if n == 0 {
+		return &entities.CompanyDocument{}, utils.NewErrorWithCode(
+			errors.New("empty document"),
+			utils.ErrorCodeInvalidForm,
+			"uploaded document is empty",
+		)
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !allowedDocumentContentTypes[contentType] {
+		return &entities.CompanyDocument{}, utils.NewErrorWithCode(
+			errors.New("unsupported document type"),
+			utils.ErrorCodeUnsupportedDocument,
+			"unsupported document content type = [%v]",
+			contentType,
+		)
+	}
+
+	hash := sha256.New()
+	var size byteCounter
+
+	contents := io.TeeReader(
+		io.LimitReader(io.MultiReader(bytes.NewReader(head), reader), MaxDocumentSize+1),
+		io.MultiWriter(hash, &size),
+	)
+
+	document := &entities.CompanyDocument{
+		CompanyID:   company.ID,
+		FileName:    sanitizeDocumentFileName(fileName),
+		ContentType: contentType,
+		StorageKey:  fmt.Sprintf("companies/%v/documents/%v", company.ID, uuid.NewString()),
+	}
+
+	if userID := ctxhelper.UserID(ctx); userID != 0 {
+		document.UploadedBy = null.IntFrom(userID)
+	}
+
+	err = s.blobStore.Put(ctx, document.StorageKey, contents)
+	if err != nil {
+		return &entities.CompanyDocument{}, utils.NewError(
+			err,
+			"failed to store document for company id = %v",
+			company.ID,
+		)
+	}
+
+	if size > MaxDocumentSize {
+		s.deleteContents(ctx, document)
+
+		return &entities.CompanyDocument{}, utils.NewErrorWithCode(
+			errors.New("document too large"),
+			utils.ErrorCodeDocumentTooLarge,
+			"document exceeds %v bytes",
+			MaxDocumentSize,
+		)
+	}
+
+	document.SizeBytes = int64(size)
+	document.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	err = s.companyDocumentRepository.Save(ctx, dB, document)
+	if err != nil {
+		s.deleteContents(ctx, document)
+		return &entities.CompanyDocument{}, err
+	}
+
+	return document, nil
+}
+
+func (s *AppCompanyDocumentService) deleteContents(
+	ctx context.Context,
+	document *entities.CompanyDocument,
+) {
+
+	err := s.blobStore.Delete(ctx, document.StorageKey)
+	if err != nil {
+		logger.Errorf("Failed to delete contents key = [%v] err = %v", document.StorageKey, err)
+	}
+}
+
+func (s *AppCompanyDocumentService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppCompanyDocumentService) getDocument(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	documentID int64,
+) (*entities.CompanyDocument, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyDocument{}, err
+	}
+
+	document, err := s.companyDocumentRepository.CompanyDocumentByCompanyAndID(ctx, dB, company.ID, documentID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyDocument{}, err
+		}
+
+		return &entities.CompanyDocument{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"document not found",
+		)
+	}
+
+	return document, nil
+}
+
+// sanitizeDocumentFileName keeps the base name of an uploaded file without
+// control characters, truncated to fit its column.
+func sanitizeDocumentFileName(fileName string) string {
+
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+
+	fileName = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, fileName))
+
+	if runes := []rune(fileName); len(runes) > maxDocumentFileName {
+		fileName = string(runes[:maxDocumentFileName])
+	}
+
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return defaultDocumentFileName
+	}
+
+	return fileName
+}
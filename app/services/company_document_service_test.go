@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyDocumentService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	blobStore := providers.NewLocalBlobStore(t.TempDir())
+
+	companyDocumentService := NewTestCompanyDocumentService(blobStore)
+	companyService := NewTestCompanyServiceWithBlobStore(blobStore)
+
+	Convey("Company Document Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		pdf := []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n")
+		checksum := sha256.Sum256(pdf)
+
+		Convey("can upload a document", func() {
+
+			document, err := companyDocumentService.UploadDocument(ctx, dB, company.ID, "../Certificate of Incorporation.pdf", bytes.NewReader(pdf))
+			So(err, ShouldBeNil)
+			So(document.ID, ShouldNotBeZeroValue)
+			So(document.FileName, ShouldEqual, "Certificate of Incorporation.pdf")
+			So(document.ContentType, ShouldEqual, "application/pdf")
+			So(document.SizeBytes, ShouldEqual, len(pdf))
+			So(document.SHA256, ShouldEqual, hex.EncodeToString(checksum[:]))
+
+			Convey("and download it", func() {
+
+				foundDocument, contents, err := companyDocumentService.OpenDocument(ctx, dB, company.ID, document.ID)
+				So(err, ShouldBeNil)
+				defer contents.Close()
+
+				data, err := ioutil.ReadAll(contents)
+				So(err, ShouldBeNil)
+				So(data, ShouldResemble, pdf)
+				So(foundDocument.SHA256, ShouldEqual, document.SHA256)
+			})
+
+			Convey("and list it", func() {
+
+				documents, err := companyDocumentService.ListDocuments(ctx, dB, company.ID)
+				So(err, ShouldBeNil)
+				So(len(documents), ShouldEqual, 1)
+				So(documents[0].ID, ShouldEqual, document.ID)
+			})
+
+			Convey("and delete it", func() {
+
+				_, err := companyDocumentService.DeleteDocument(ctx, dB, company.ID, document.ID)
+				So(err, ShouldBeNil)
+
+				_, _, err = companyDocumentService.OpenDocument(ctx, dB, company.ID, document.ID)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+			})
+
+			Convey("and remove its contents when the company is purged", func() {
+
+				_, err := companyService.DeleteCompany(ctx, dB, company.ID, null.Int{})
+				So(err, ShouldBeNil)
+
+				purged, err := companyService.PurgeDeletedCompanies(ctx, dB, 0)
+				So(err, ShouldBeNil)
+				So(purged, ShouldEqual, 1)
+
+				_, err = blobStore.Get(ctx, document.StorageKey)
+				So(err, ShouldEqual, providers.ErrBlobNotFound)
+			})
+		})
+
+		Convey("cannot upload an unsupported document type", func() {
+
+			_, err := companyDocumentService.UploadDocument(ctx, dB, company.ID, "script.pdf", strings.NewReader("#!/bin/sh\necho hello\n"))
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeUnsupportedDocument)
+		})
+
+		Convey("cannot upload a document over the size limit", func() {
+
+			oversized := append(append([]byte{}, pdf...), make([]byte, MaxDocumentSize)...)
+
+			_, err := companyDocumentService.UploadDocument(ctx, dB, company.ID, "large.pdf", bytes.NewReader(oversized))
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeDocumentTooLarge)
+
+			documents, err := companyDocumentService.ListDocuments(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(documents), ShouldEqual, 0)
+		})
+
+		Convey("cannot open another company's document", func() {
+
+			otherCompany, _, err := repos.CreateCompany(ctx, dB, "McDonalds", country)
+			So(err, ShouldBeNil)
+
+			document, err := repos.CreateCompanyDocument(ctx, dB, otherCompany.ID)
+			So(err, ShouldBeNil)
+
+			_, _, err = companyDocumentService.OpenDocument(ctx, dB, company.ID, document.ID)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+	}))
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+type (
+	CompanyHierarchyService interface {
+		GetCompanyTree(ctx context.Context, dB db.DB, companyID int64) (*entities.CompanyNode, error)
+		SetParentCompany(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyParentForm, expectedVersion null.Int) (*entities.Company, error)
+	}
+
+	AppCompanyHierarchyService struct {
+		auditLogRepository       repos.AuditLogRepository
+		companyCountryRepository repos.CompanyCountryRepository
+		companyRepository        repos.CompanyRepository
+	}
+)
+
+func NewCompanyHierarchyService(
+	auditLogRepository repos.AuditLogRepository,
+	companyCountryRepository repos.CompanyCountryRepository,
+	companyRepository repos.CompanyRepository,
+) *AppCompanyHierarchyService {
+	return &AppCompanyHierarchyService{
+		auditLogRepository:       auditLogRepository,
+		companyCountryRepository: companyCountryRepository,
+		companyRepository:        companyRepository,
+	}
+}
+
+func NewTestCompanyHierarchyService() *AppCompanyHierarchyService {
+	return NewCompanyHierarchyService(
+		repos.NewAuditLogRepository(),
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyRepository(),
+	)
+}
+
+// GetCompanyTree returns the group the company belongs to, rooted at its top
+// most parent.
+func (s *AppCompanyHierarchyService) GetCompanyTree(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.CompanyNode, error) {
+
+	nodes, err := s.companyRepository.CompanyTree(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyNode{}, err
+	}
+
+	if len(nodes) == 0 {
+		return &entities.CompanyNode{}, utils.NewErrorWithCode(
+			errors.New("company not found"),
+			utils.ErrorCodeNotFound,
+			"company = %v not found",
+			companyID,
+		)
+	}
+
+	companyIDs := make([]int64, 0, len(nodes))
+	nodesByID := make(map[int64]*entities.CompanyNode, len(nodes))
+
+	for _, node := range nodes {
+		companyIDs = append(companyIDs, node.ID)
+		nodesByID[node.ID] = node
+	}
+
+	companyCountries, err := s.companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, companyIDs)
+	if err != nil {
+		return &entities.CompanyNode{}, err
+	}
+
+	// Nodes arrive depth first, so every parent is seen before its
+	// subsidiaries.
+	for _, node := range nodes[1:] {
+
+		node.Countries = companyCountries[node.ID]
+		if node.Countries == nil {
+			node.Countries = []*entities.CompanyCountry{}
+		}
+
+		parent, ok := nodesByID[node.ParentCompanyID.Int64]
+		if ok {
+			parent.Subsidiaries = append(parent.Subsidiaries, node)
+		}
+	}
+
+	root := nodes[0]
+
+	root.Countries = companyCountries[root.ID]
+	if root.Countries == nil {
+		root.Countries = []*entities.CompanyCountry{}
+	}
+
+	return root, nil
+}
+
+// SetParentCompany moves a company under another company. A company cannot
+// become a subsidiary of itself or of any of its own subsidiaries, nor be
+// placed under a closed company. When expectedVersion is set the company
+// must still be at that version.
+func (s *AppCompanyHierarchyService) SetParentCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CompanyParentForm,
+	expectedVersion null.Int,
+) (*entities.Company, error) {
+
+	var company *entities.Company
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.companyRepository.LockCompanyHierarchy(ctx, operations)
+		if err != nil {
+			return err
+		}
+
+		company, err = s.companyRepository.CompanyByID(ctx, operations, companyID)
+		if err != nil {
+			if !utils.IsErrNoRows(err) {
+				return err
+			}
+
+			return utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeNotFound,
+				"company not found",
+			)
+		}
+
+		if expectedVersion.Valid && expectedVersion.Int64 != company.Version {
+			return utils.NewErrorWithCode(
+				errors.New("company version mismatch"),
+				utils.ErrorCodePreconditionFailed,
+				"company id = %v is at version = %v, expected version = %v",
+				company.ID,
+				company.Version,
+				expectedVersion.Int64,
+			)
+		}
+
+		if form.ParentCompanyID.Valid {
+			err = validateParentCompany(ctx, operations, s.companyRepository, company.ID, form.ParentCompanyID.Int64)
+			if err != nil {
+				return err
+			}
+		}
+
+		before := *company
+
+		company.ParentCompanyID = form.ParentCompanyID
+
+		err = s.companyRepository.Save(ctx, operations, company)
+		if err != nil {
+			return err
+		}
+
+		return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionUpdate, company.ID, &before, company)
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	companyCountries, err := s.companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, []int64{company.ID})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.Countries = companyCountries[company.ID]
+	if company.Countries == nil {
+		company.Countries = []*entities.CompanyCountry{}
+	}
+
+	return company, nil
+}
+
+// validateParentCompany checks that parentCompanyID can become the parent of
+// companyID. New companies pass a zero companyID as they cannot have
+// subsidiaries yet.
+func validateParentCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyRepository repos.CompanyRepository,
+	companyID,
+	parentCompanyID int64,
+) error {
+
+	if parentCompanyID == companyID {
+		return utils.NewErrorWithCode(
+			errors.New("company cannot be its own parent"),
+			utils.ErrorCodeInvalidArgument,
+			"company = %v cannot be its own parent",
+			companyID,
+		)
+	}
+
+	parentCompany, err := companyRepository.CompanyByID(ctx, operations, parentCompanyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return err
+		}
+
+		return utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"parent company = %v not found",
+			parentCompanyID,
+		)
+	}
+
+	if parentCompany.OperationStatus == entities.OperationStatusTypeClosed {
+		return utils.NewErrorWithCode(
+			errors.New("parent company is closed"),
+			utils.ErrorCodeInvalidTransition,
+			"cannot add subsidiaries to closed company = %v",
+			parentCompanyID,
+		)
+	}
+
+	if companyID == 0 {
+		return nil
+	}
+
+	isAncestor, err := companyRepository.IsCompanyAncestor(ctx, operations, companyID, parentCompanyID)
+	if err != nil {
+		return err
+	}
+
+	if isAncestor {
+		return utils.NewErrorWithCode(
+			errors.New("company hierarchy cycle"),
+			utils.ErrorCodeInvalidArgument,
+			"company = %v is a parent of company = %v",
+			companyID,
+			parentCompanyID,
+		)
+	}
+
+	return nil
+}
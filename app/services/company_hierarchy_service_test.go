@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyHierarchyService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyHierarchyService := NewTestCompanyHierarchyService()
+	companyLifecycleService := NewTestCompanyLifecycleService()
+	companyRepository := repos.NewCompanyRepository()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Hierarchy Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		user, err := repos.CreateUser(ctx, dB)
+		So(err, ShouldBeNil)
+
+		ctx = ctxhelper.WithUserID(ctx, user.ID)
+
+		holding, _, err := repos.CreateCompany(ctx, dB, "Holding", country)
+		So(err, ShouldBeNil)
+
+		subsidiary, _, err := repos.CreateCompany(ctx, dB, "Subsidiary", country)
+		So(err, ShouldBeNil)
+
+		grandSubsidiary, _, err := repos.CreateCompany(ctx, dB, "Grand Subsidiary", country)
+		So(err, ShouldBeNil)
+
+		subsidiary, err = companyHierarchyService.SetParentCompany(ctx, dB, subsidiary.ID, &forms.CompanyParentForm{ParentCompanyID: null.IntFrom(holding.ID)}, null.Int{})
+		So(err, ShouldBeNil)
+		So(subsidiary.ParentCompanyID.Int64, ShouldEqual, holding.ID)
+
+		grandSubsidiary, err = companyHierarchyService.SetParentCompany(ctx, dB, grandSubsidiary.ID, &forms.CompanyParentForm{ParentCompanyID: null.IntFrom(subsidiary.ID)}, null.Int{})
+		So(err, ShouldBeNil)
+
+		Convey("can get the whole tree from any company in it", func() {
+
+			tree, err := companyHierarchyService.GetCompanyTree(ctx, dB, grandSubsidiary.ID)
+			So(err, ShouldBeNil)
+			So(tree.ID, ShouldEqual, holding.ID)
+			So(tree.Depth, ShouldEqual, 0)
+			So(len(tree.Subsidiaries), ShouldEqual, 1)
+			So(tree.Subsidiaries[0].ID, ShouldEqual, subsidiary.ID)
+			So(tree.Subsidiaries[0].Depth, ShouldEqual, 1)
+			So(len(tree.Subsidiaries[0].Subsidiaries), ShouldEqual, 1)
+			So(tree.Subsidiaries[0].Subsidiaries[0].ID, ShouldEqual, grandSubsidiary.ID)
+			So(len(tree.Subsidiaries[0].Subsidiaries[0].Countries), ShouldEqual, 1)
+		})
+
+		Convey("cannot create a cycle", func() {
+
+			_, err := companyHierarchyService.SetParentCompany(ctx, dB, holding.ID, &forms.CompanyParentForm{ParentCompanyID: null.IntFrom(grandSubsidiary.ID)}, null.Int{})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidArgument)
+
+			_, err = companyHierarchyService.SetParentCompany(ctx, dB, holding.ID, &forms.CompanyParentForm{ParentCompanyID: null.IntFrom(holding.ID)}, null.Int{})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("cannot move a company modified since it was read", func() {
+
+			_, err := companyHierarchyService.SetParentCompany(ctx, dB, subsidiary.ID, &forms.CompanyParentForm{}, null.IntFrom(subsidiary.Version-1))
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodePreconditionFailed)
+		})
+
+		Convey("can detach a subsidiary", func() {
+
+			detached, err := companyHierarchyService.SetParentCompany(ctx, dB, subsidiary.ID, &forms.CompanyParentForm{}, null.Int{})
+			So(err, ShouldBeNil)
+			So(detached.ParentCompanyID.Valid, ShouldBeFalse)
+
+			tree, err := companyHierarchyService.GetCompanyTree(ctx, dB, grandSubsidiary.ID)
+			So(err, ShouldBeNil)
+			So(tree.ID, ShouldEqual, subsidiary.ID)
+		})
+
+		Convey("can list direct subsidiaries", func() {
+
+			companyList, err := companyService.ListSubsidiaries(ctx, dB, holding.ID, &forms.Filter{Page: 1, Per: 20})
+			So(err, ShouldBeNil)
+			So(len(companyList.Companies), ShouldEqual, 1)
+			So(companyList.Companies[0].ID, ShouldEqual, subsidiary.ID)
+		})
+
+		Convey("can create a company under a parent", func() {
+
+			form := &forms.CreateCompanyForm{
+				Name:            "Microsoft",
+				Code:            "SOFT",
+				Country:         "cyprus",
+				Website:         "https://microsoft.com",
+				Phone:           "+35790034567",
+				ParentCompanyID: null.IntFrom(holding.ID),
+			}
+
+			company, err := companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldBeNil)
+			So(company.ParentCompanyID.Int64, ShouldEqual, holding.ID)
+		})
+
+		Convey("cannot close a parent with open subsidiaries without cascade", func() {
+
+			_, err := companyLifecycleService.CloseCompany(ctx, dB, holding.ID, &forms.CompanyStatusForm{Reason: "restructuring"})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidTransition)
+		})
+
+		Convey("can close a parent and its subsidiaries with cascade", func() {
+
+			_, err := companyLifecycleService.CloseCompany(ctx, dB, holding.ID, &forms.CompanyStatusForm{Reason: "restructuring", Cascade: true})
+			So(err, ShouldBeNil)
+
+			for _, companyID := range []int64{holding.ID, subsidiary.ID, grandSubsidiary.ID} {
+				company, err := companyRepository.CompanyByID(ctx, dB, companyID)
+				So(err, ShouldBeNil)
+				So(company.OperationStatus, ShouldEqual, entities.OperationStatusTypeClosed)
+			}
+
+			Convey("cannot reopen a subsidiary while its parent is closed", func() {
+
+				_, err := companyLifecycleService.ReopenCompany(ctx, dB, subsidiary.ID, &forms.CompanyStatusForm{Reason: "restructured"})
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidTransition)
+			})
+		})
+
+		Convey("deleting a parent detaches its subsidiaries", func() {
+
+			_, err := companyService.DeleteCompany(ctx, dB, holding.ID, null.Int{})
+			So(err, ShouldBeNil)
+
+			company, err := companyRepository.CompanyByID(ctx, dB, subsidiary.ID)
+			So(err, ShouldBeNil)
+			So(company.ParentCompanyID.Valid, ShouldBeFalse)
+
+			company, err = companyRepository.CompanyByID(ctx, dB, grandSubsidiary.ID)
+			So(err, ShouldBeNil)
+			So(company.ParentCompanyID.Int64, ShouldEqual, subsidiary.ID)
+		})
+	}))
+}
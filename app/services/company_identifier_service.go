@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+type (
+	CompanyIdentifierService interface {
+		AddCompanyIdentifier(ctx context.Context, dB db.DB, companyID int64, form *forms.CreateCompanyIdentifierForm) (*entities.CompanyIdentifier, error)
+		ListCompanyIdentifiers(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyIdentifier, error)
+		RemoveCompanyIdentifier(ctx context.Context, dB db.DB, companyID, identifierID int64) (*entities.CompanyIdentifier, error)
+	}
+
+	AppCompanyIdentifierService struct {
+		companyIdentifierRepository repos.CompanyIdentifierRepository
+		companyRepository           repos.CompanyRepository
+	}
+)
+
+func NewCompanyIdentifierService(
+	companyIdentifierRepository repos.CompanyIdentifierRepository,
+	companyRepository repos.CompanyRepository,
+) *AppCompanyIdentifierService {
+	return &AppCompanyIdentifierService{
+		companyIdentifierRepository: companyIdentifierRepository,
+		companyRepository:           companyRepository,
+	}
+}
+
+func NewTestCompanyIdentifierService() *AppCompanyIdentifierService {
+	return NewCompanyIdentifierService(
+		repos.NewCompanyIdentifierRepository(),
+		repos.NewCompanyRepository(),
+	)
+}
+
+// AddCompanyIdentifier validates the identifier offline and stores it. An
+// identifier belongs to a single company, and a company holds at most one
+// identifier of each type per issuing country.
+func (s *AppCompanyIdentifierService) AddCompanyIdentifier(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CreateCompanyIdentifierForm,
+) (*entities.CompanyIdentifier, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	identifierType := entities.IdentifierType(strings.ToLower(strings.TrimSpace(form.IdentifierType)))
+
+	value, countryCode, err := utils.NormalizeCompanyIdentifier(identifierType, form.Country, form.Value)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	identifier := &entities.CompanyIdentifier{
+		CompanyID:      company.ID,
+		IdentifierType: identifierType,
+		CountryCode:    countryCode,
+		Value:          value,
+	}
+
+	existingIdentifier, err := s.companyIdentifierRepository.CompanyIdentifierByValue(ctx, dB, identifierType, countryCode, value)
+	if err == nil {
+		return &entities.CompanyIdentifier{}, utils.NewErrorWithCode(
+			errors.New("company identifier already exists"),
+			utils.ErrorCodeResourceExists,
+			"%v identifier = %v already belongs to company = %v",
+			identifierType,
+			value,
+			existingIdentifier.CompanyID,
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	_, err = s.companyIdentifierRepository.CompanyIdentifierByCompanyAndType(ctx, dB, company.ID, identifierType, countryCode)
+	if err == nil {
+		return &entities.CompanyIdentifier{}, utils.NewErrorWithCode(
+			errors.New("company identifier type already exists"),
+			utils.ErrorCodeResourceExists,
+			"company = %v already has a %v identifier for country = [%v]",
+			company.ID,
+			identifierType,
+			countryCode,
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	err = s.companyIdentifierRepository.Save(ctx, dB, identifier)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	return identifier, nil
+}
+
+func (s *AppCompanyIdentifierService) ListCompanyIdentifiers(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyIdentifier, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyIdentifier{}, err
+	}
+
+	return s.companyIdentifierRepository.CompanyIdentifiersByCompanyID(ctx, dB, company.ID)
+}
+
+func (s *AppCompanyIdentifierService) RemoveCompanyIdentifier(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	identifierID int64,
+) (*entities.CompanyIdentifier, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	identifier, err := s.companyIdentifierRepository.CompanyIdentifierByCompanyAndID(ctx, dB, company.ID, identifierID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyIdentifier{}, err
+		}
+
+		return &entities.CompanyIdentifier{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company identifier not found",
+		)
+	}
+
+	err = s.companyIdentifierRepository.DeleteCompanyIdentifier(ctx, dB, identifier.ID)
+	if err != nil {
+		return &entities.CompanyIdentifier{}, err
+	}
+
+	return identifier, nil
+}
+
+func (s *AppCompanyIdentifierService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
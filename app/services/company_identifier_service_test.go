@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyIdentifierService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyIdentifierService := NewTestCompanyIdentifierService()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Identifier Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		otherCompany, _, err := repos.CreateCompany(ctx, dB, "McDonalds", country)
+		So(err, ShouldBeNil)
+
+		Convey("can add an lei to a company", func() {
+
+			form := &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "LEI",
+				Value:          "5493001kjtiigc8y1r12",
+			}
+
+			identifier, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, form)
+			So(err, ShouldBeNil)
+
+			So(identifier.ID, ShouldNotBeZeroValue)
+			So(identifier.IdentifierType, ShouldEqual, entities.IdentifierTypeLEI)
+			So(identifier.Value, ShouldEqual, "5493001KJTIIGC8Y1R12")
+			So(identifier.CountryCode, ShouldBeEmpty)
+
+			Convey("and find the company by it", func() {
+
+				filter := &forms.Filter{
+					Page:           1,
+					Per:            10,
+					IdentifierType: entities.IdentifierTypeLEI,
+					Identifier:     "5493001KJTIIGC8Y1R12",
+				}
+
+				companyList, err := companyService.ListCompanies(ctx, dB, filter)
+				So(err, ShouldBeNil)
+				So(len(companyList.Companies), ShouldEqual, 1)
+				So(companyList.Companies[0].ID, ShouldEqual, company.ID)
+				So(companyList.Pagination.Count, ShouldEqual, 1)
+			})
+
+			Convey("but not add the same lei to another company", func() {
+
+				_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, otherCompany.ID, form)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+			})
+
+			Convey("but not add a second lei", func() {
+
+				form := &forms.CreateCompanyIdentifierForm{
+					IdentifierType: "lei",
+					Value:          "529900T8BM49AURSDO55",
+				}
+
+				_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, form)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+			})
+		})
+
+		Convey("cannot add an lei with invalid check digits", func() {
+
+			form := &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "lei",
+				Value:          "5493001KJTIIGC8Y1R13",
+			}
+
+			_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidLEI)
+		})
+
+		Convey("can add vat numbers for several countries", func() {
+
+			cypriotVAT, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "vat",
+				Value:          "CY 10259033 P",
+			})
+			So(err, ShouldBeNil)
+			So(cypriotVAT.Value, ShouldEqual, "CY10259033P")
+			So(cypriotVAT.CountryCode, ShouldEqual, "CY")
+
+			greekVAT, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "vat",
+				Value:          "EL094259216",
+			})
+			So(err, ShouldBeNil)
+			So(greekVAT.CountryCode, ShouldEqual, "GR")
+
+			identifiers, err := companyIdentifierService.ListCompanyIdentifiers(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(identifiers), ShouldEqual, 2)
+		})
+
+		Convey("cannot add a vat number with invalid check digits", func() {
+
+			form := &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "vat",
+				Value:          "DE136695977",
+			}
+
+			_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidVATNumber)
+		})
+
+		Convey("the same registration number may exist in different countries", func() {
+
+			_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "registration",
+				Value:          "HE 123456",
+				Country:        "CY",
+			})
+			So(err, ShouldBeNil)
+
+			_, err = companyIdentifierService.AddCompanyIdentifier(ctx, dB, otherCompany.ID, &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "registration",
+				Value:          "he 123456",
+				Country:        "GR",
+			})
+			So(err, ShouldBeNil)
+		})
+
+		Convey("cannot add an identifier of an unknown type", func() {
+
+			form := &forms.CreateCompanyIdentifierForm{
+				IdentifierType: "duns",
+				Value:          "150483782",
+			}
+
+			_, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidIdentifier)
+		})
+
+		Convey("can remove an identifier", func() {
+
+			identifier, err := repos.CreateCompanyIdentifier(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyIdentifierService.RemoveCompanyIdentifier(ctx, dB, company.ID, identifier.ID)
+			So(err, ShouldBeNil)
+
+			identifiers, err := companyIdentifierService.ListCompanyIdentifiers(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(identifiers), ShouldEqual, 0)
+		})
+
+		Convey("cannot remove another company's identifier", func() {
+
+			identifier, err := repos.CreateCompanyIdentifier(ctx, dB, otherCompany.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyIdentifierService.RemoveCompanyIdentifier(ctx, dB, company.ID, identifier.ID)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+	}))
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const MaxCompanyImportRows = 1000
+
+type companyImportCandidate struct {
+	company *entities.Company
+	country *entities.Country
+	row     *entities.CompanyImportRow
+}
+
+func (s *AppCompanyService) ImportCompanies(
+	ctx context.Context,
+	dB db.DB,
+	form *forms.CompanyImportForm,
+) (*entities.CompanyImportReport, error) {
+
+	if !form.Mode.IsValid() {
+		return &entities.CompanyImportReport{}, utils.NewErrorWithCode(
+			errors.New("invalid import mode"),
+			utils.ErrorCodeInvalidArgument,
+			"invalid company import mode = [%v]",
+			form.Mode,
+		)
+	}
+
+	if len(form.Companies) == 0 || len(form.Companies) > MaxCompanyImportRows {
+		return &entities.CompanyImportReport{}, utils.NewErrorWithCode(
+			errors.New("invalid number of import rows"),
+			utils.ErrorCodeInvalidArgument,
+			"company import must have between 1 and %v rows, got %v",
+			MaxCompanyImportRows,
+			len(form.Companies),
+		)
+	}
+
+	report := &entities.CompanyImportReport{
+		DryRun: form.DryRun,
+		Mode:   form.Mode,
+		Total:  len(form.Companies),
+		Rows:   make([]*entities.CompanyImportRow, 0, len(form.Companies)),
+	}
+
+	candidates := s.validateImportRows(ctx, dB, form.Companies, report)
+
+	if form.DryRun {
+		return report, nil
+	}
+
+	if form.Mode == entities.CompanyImportModeAllOrNothing {
+		return s.importAllOrNothing(ctx, dB, candidates, report)
+	}
+
+	return s.importBestEffort(ctx, dB, candidates, report)
+}
+
+func (s *AppCompanyService) importAllOrNothing(
+	ctx context.Context,
+	dB db.DB,
+	candidates []*companyImportCandidate,
+	report *entities.CompanyImportReport,
+) (*entities.CompanyImportReport, error) {
+
+	if report.Failed > 0 {
+		skipImportRows(candidates)
+		return report, nil
+	}
+
+	var failedCandidate *companyImportCandidate
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		for _, candidate := range candidates {
+
+			err := s.saveNewCompany(ctx, operations, candidate.company, candidate.country)
+			if err != nil {
+				failedCandidate = candidate
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if failedCandidate == nil {
+			return &entities.CompanyImportReport{}, err
+		}
+
+		skipImportRows(candidates)
+		failImportRow(report, failedCandidate.row, err)
+
+		return report, nil
+	}
+
+	for _, candidate := range candidates {
+		createImportRow(report, candidate)
+	}
+
+	return report, nil
+}
+
+func (s *AppCompanyService) importBestEffort(
+	ctx context.Context,
+	dB db.DB,
+	candidates []*companyImportCandidate,
+	report *entities.CompanyImportReport,
+) (*entities.CompanyImportReport, error) {
+
+	for _, candidate := range candidates {
+
+		err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+			return s.saveNewCompany(ctx, operations, candidate.company, candidate.country)
+		})
+		if err != nil {
+			failImportRow(report, candidate.row, err)
+			continue
+		}
+
+		createImportRow(report, candidate)
+	}
+
+	return report, nil
+}
+
+// validateImportRows runs each row through the same checks as CreateCompany
+// and additionally rejects rows that clash with an earlier row in the batch.
+func (s *AppCompanyService) validateImportRows(
+	ctx context.Context,
+	dB db.DB,
+	companyForms []*forms.CreateCompanyForm,
+	report *entities.CompanyImportReport,
+) []*companyImportCandidate {
+
+	candidates := make([]*companyImportCandidate, 0, len(companyForms))
+	seen := make(map[string]int)
+
+	for i, companyForm := range companyForms {
+
+		row := &entities.CompanyImportRow{
+			Row:    i + 1,
+			Status: entities.CompanyImportRowStatusValid,
+			Code:   companyForm.Code,
+		}
+
+		report.Rows = append(report.Rows, row)
+
+		err := binding.Validator.ValidateStruct(companyForm)
+		if err != nil {
+			failImportRow(report, row, utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"invalid company import row",
+			))
+			continue
+		}
+
+		err = validateImportRowUniqueness(seen, row.Row, companyForm)
+		if err != nil {
+			failImportRow(report, row, err)
+			continue
+		}
+
+		company, country, err := s.buildCompany(ctx, dB, companyForm)
+		if err != nil {
+			failImportRow(report, row, err)
+			continue
+		}
+
+		candidates = append(candidates, &companyImportCandidate{
+			company: company,
+			country: country,
+			row:     row,
+		})
+	}
+
+	return candidates
+}
+
+func validateImportRowUniqueness(
+	seen map[string]int,
+	rowNumber int,
+	companyForm *forms.CreateCompanyForm,
+) error {
+
+	keys := map[string]string{
+		"code":    companyForm.Code,
+		"name":    companyForm.Name,
+		"phone":   companyForm.Phone,
+		"website": companyForm.Website,
+	}
+
+	if website, err := utils.NormalizeWebsite(companyForm.Website); err == nil {
+		keys["website"] = website.Domain
+	}
+
+	for field, value := range keys {
+
+		if value == "" {
+			continue
+		}
+
+		if previousRow, ok := seen[field+":"+value]; ok {
+			return utils.NewErrorWithCode(
+				errors.New("duplicate company in import"),
+				utils.ErrorCodeResourceExists,
+				"duplicate company %v, already used in row %v",
+				field,
+				previousRow,
+			)
+		}
+	}
+
+	for field, value := range keys {
+		if value != "" {
+			seen[field+":"+value] = rowNumber
+		}
+	}
+
+	return nil
+}
+
+func createImportRow(
+	report *entities.CompanyImportReport,
+	candidate *companyImportCandidate,
+) {
+	candidate.row.Status = entities.CompanyImportRowStatusCreated
+	candidate.row.Company = candidate.company
+	report.Created++
+}
+
+func failImportRow(
+	report *entities.CompanyImportReport,
+	row *entities.CompanyImportRow,
+	err error,
+) {
+
+	row.Status = entities.CompanyImportRowStatusFailed
+	row.ErrorCode = utils.ErrorCodeRequestFailed.String()
+	row.ErrorMessage = err.Error()
+
+	if appError, ok := err.(*utils.Error); ok {
+		row.ErrorCode = appError.GetErrorCode().String()
+		row.ErrorMessage = strings.SplitN(appError.Error(), "; ", 2)[0]
+
+		if appError.Err() != nil && !utils.IsErrNoRows(appError) {
+			row.ErrorMessage = fmt.Sprintf("%v: %v", row.ErrorMessage, appError.Err())
+		}
+	}
+
+	report.Failed++
+}
+
+func skipImportRows(
+	candidates []*companyImportCandidate,
+) {
+	for _, candidate := range candidates {
+		candidate.row.Status = entities.CompanyImportRowStatusSkipped
+	}
+}
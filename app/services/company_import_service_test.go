@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyImportService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyRepository := repos.NewCompanyRepository()
+
+	companyService := NewTestCompanyService()
+
+	Convey("Company Import Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		existingCompany, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		validCompany := &forms.CreateCompanyForm{
+			Name:    "Microsoft",
+			Code:    "SOFT",
+			Country: "Cyprus",
+			Website: "https://microsoft.com",
+			Phone:   "+35790034567",
+		}
+
+		invalidCompanies := []*forms.CreateCompanyForm{
+			{
+				Name:    "Apple",
+				Code:    existingCompany.Code,
+				Country: "Cyprus",
+				Website: "https://apple.com",
+				Phone:   "+35799000001",
+			},
+			{
+				Name:    "Google",
+				Code:    "GOOG",
+				Country: "Atlantis",
+				Website: "https://google.com",
+				Phone:   "+35799000002",
+			},
+			{
+				Name:    "Amazon",
+				Code:    "AMZN",
+				Country: "Cyprus",
+				Website: "https://amazon.com",
+				Phone:   "123",
+			},
+		}
+
+		Convey("can report row errors on a dry run without writing", func() {
+
+			form := &forms.CompanyImportForm{
+				Companies: append([]*forms.CreateCompanyForm{validCompany}, invalidCompanies...),
+				DryRun:    true,
+				Mode:      entities.CompanyImportModeBestEffort,
+			}
+
+			report, err := companyService.ImportCompanies(ctx, dB, form)
+			So(err, ShouldBeNil)
+
+			So(report.Total, ShouldEqual, 4)
+			So(report.Created, ShouldEqual, 0)
+			So(report.Failed, ShouldEqual, 3)
+
+			So(report.Rows[0].Status, ShouldEqual, entities.CompanyImportRowStatusValid)
+			So(report.Rows[1].ErrorCode, ShouldEqual, utils.ErrorCodeResourceExists.String())
+			So(report.Rows[2].ErrorCode, ShouldEqual, utils.ErrorCodeNotFound.String())
+			So(report.Rows[3].ErrorCode, ShouldEqual, utils.ErrorCodeInvalidPhone.String())
+
+			_, err = companyRepository.CompanyByCode(ctx, dB, validCompany.Code)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
+
+		Convey("cannot import anything in all or nothing mode when a row fails", func() {
+
+			form := &forms.CompanyImportForm{
+				Companies: append([]*forms.CreateCompanyForm{validCompany}, invalidCompanies[0]),
+				Mode:      entities.CompanyImportModeAllOrNothing,
+			}
+
+			report, err := companyService.ImportCompanies(ctx, dB, form)
+			So(err, ShouldBeNil)
+
+			So(report.Created, ShouldEqual, 0)
+			So(report.Failed, ShouldEqual, 1)
+			So(report.Rows[0].Status, ShouldEqual, entities.CompanyImportRowStatusSkipped)
+
+			_, err = companyRepository.CompanyByCode(ctx, dB, validCompany.Code)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+		})
+
+		Convey("can import valid rows in best effort mode", func() {
+
+			form := &forms.CompanyImportForm{
+				Companies: append([]*forms.CreateCompanyForm{validCompany}, invalidCompanies...),
+				Mode:      entities.CompanyImportModeBestEffort,
+			}
+
+			report, err := companyService.ImportCompanies(ctx, dB, form)
+			So(err, ShouldBeNil)
+
+			So(report.Created, ShouldEqual, 1)
+			So(report.Failed, ShouldEqual, 3)
+			So(report.Rows[0].Status, ShouldEqual, entities.CompanyImportRowStatusCreated)
+
+			company, err := companyRepository.CompanyByCode(ctx, dB, validCompany.Code)
+			So(err, ShouldBeNil)
+			So(company.ID, ShouldEqual, report.Rows[0].Company.ID)
+		})
+
+		Convey("cannot import duplicate rows within the same batch", func() {
+
+			duplicateCompany := *validCompany
+			duplicateCompany.Name = "Microsoft Cyprus"
+			duplicateCompany.Phone = "+35799000003"
+			duplicateCompany.Code = "SOFTCY"
+			duplicateCompany.Website = "http://www.Microsoft.com/cy"
+
+			form := &forms.CompanyImportForm{
+				Companies: []*forms.CreateCompanyForm{validCompany, &duplicateCompany},
+				DryRun:    true,
+				Mode:      entities.CompanyImportModeAllOrNothing,
+			}
+
+			report, err := companyService.ImportCompanies(ctx, dB, form)
+			So(err, ShouldBeNil)
+
+			So(report.Failed, ShouldEqual, 1)
+			So(report.Rows[1].ErrorCode, ShouldEqual, utils.ErrorCodeResourceExists.String())
+		})
+	}))
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+)
+
+type (
+	CompanyLifecycleService interface {
+		ActivateCompany(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyStatusForm) (*entities.Company, error)
+		CloseCompany(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyStatusForm) (*entities.Company, error)
+		ReopenCompany(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyStatusForm) (*entities.Company, error)
+	}
+
+	AppCompanyLifecycleService struct {
+		auditLogRepository                repos.AuditLogRepository
+		companyRepository                 repos.CompanyRepository
+		companyStatusTransitionRepository repos.CompanyStatusTransitionRepository
+	}
+)
+
+func NewCompanyLifecycleService(
+	auditLogRepository repos.AuditLogRepository,
+	companyRepository repos.CompanyRepository,
+	companyStatusTransitionRepository repos.CompanyStatusTransitionRepository,
+) *AppCompanyLifecycleService {
+	return &AppCompanyLifecycleService{
+		auditLogRepository:                auditLogRepository,
+		companyRepository:                 companyRepository,
+		companyStatusTransitionRepository: companyStatusTransitionRepository,
+	}
+}
+
+func NewTestCompanyLifecycleService() *AppCompanyLifecycleService {
+	return NewCompanyLifecycleService(
+		repos.NewAuditLogRepository(),
+		repos.NewCompanyRepository(),
+		repos.NewCompanyStatusTransitionRepository(),
+	)
+}
+
+func (s *AppCompanyLifecycleService) ActivateCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CompanyStatusForm,
+) (*entities.Company, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	if company.OperationStatus != entities.OperationStatusTypePending {
+		return &entities.Company{}, s.invalidTransitionError(company, entities.OperationStatusTypeActive)
+	}
+
+	return s.transition(ctx, dB, company, entities.OperationStatusTypeActive, form.Reason, func(ctx context.Context, operations db.SQLOperations) ([]*entities.Company, error) {
+		return []*entities.Company{}, s.validateParentCompanyOpen(ctx, operations, company)
+	})
+}
+
+func (s *AppCompanyLifecycleService) CloseCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CompanyStatusForm,
+) (*entities.Company, error) {
+
+	reason, err := s.requireReason(form)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	return s.transition(ctx, dB, company, entities.OperationStatusTypeClosed, reason, func(ctx context.Context, operations db.SQLOperations) ([]*entities.Company, error) {
+
+		subsidiaries, err := s.openSubsidiaries(ctx, operations, company)
+		if err != nil {
+			return []*entities.Company{}, err
+		}
+
+		if len(subsidiaries) > 0 && !form.Cascade {
+			return []*entities.Company{}, utils.NewErrorWithCode(
+				errors.New("company has open subsidiaries"),
+				utils.ErrorCodeInvalidTransition,
+				"cannot close company = %v with %v open subsidiaries without cascade",
+				company.ID,
+				len(subsidiaries),
+			)
+		}
+
+		return subsidiaries, nil
+	})
+}
+
+func (s *AppCompanyLifecycleService) ReopenCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CompanyStatusForm,
+) (*entities.Company, error) {
+
+	reason, err := s.requireReason(form)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	if company.OperationStatus != entities.OperationStatusTypeClosed {
+		return &entities.Company{}, s.invalidTransitionError(company, entities.OperationStatusTypeActive)
+	}
+
+	return s.transition(ctx, dB, company, entities.OperationStatusTypeActive, reason, func(ctx context.Context, operations db.SQLOperations) ([]*entities.Company, error) {
+		return []*entities.Company{}, s.validateParentCompanyOpen(ctx, operations, company)
+	})
+}
+
+// transition moves the company, and the subsidiaries returned by prepare, to
+// toStatus in a single transaction. prepare runs under the hierarchy lock so
+// that subsidiaries cannot be attached or moved while it checks them.
+func (s *AppCompanyLifecycleService) transition(
+	ctx context.Context,
+	dB db.DB,
+	company *entities.Company,
+	toStatus entities.OperationStatusType,
+	reason string,
+	prepare func(ctx context.Context, operations db.SQLOperations) ([]*entities.Company, error),
+) (*entities.Company, error) {
+
+	if !company.OperationStatus.CanTransitionTo(toStatus) {
+		return &entities.Company{}, s.invalidTransitionError(company, toStatus)
+	}
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.companyRepository.LockCompanyHierarchy(ctx, operations)
+		if err != nil {
+			return err
+		}
+
+		subsidiaries, err := prepare(ctx, operations)
+		if err != nil {
+			return err
+		}
+
+		for _, subsidiary := range subsidiaries {
+			err := s.saveTransition(ctx, operations, subsidiary, toStatus, reason)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.saveTransition(ctx, operations, company, toStatus, reason)
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	statusHistory, err := s.companyStatusTransitionRepository.CompanyStatusTransitionsByCompanyID(ctx, dB, company.ID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.StatusHistory = statusHistory
+
+	return company, nil
+}
+
+func (s *AppCompanyLifecycleService) saveTransition(
+	ctx context.Context,
+	operations db.SQLOperations,
+	company *entities.Company,
+	toStatus entities.OperationStatusType,
+	reason string,
+) error {
+
+	transition := &entities.CompanyStatusTransition{
+		CompanyID:  company.ID,
+		FromStatus: company.OperationStatus,
+		Reason:     strings.TrimSpace(reason),
+		ToStatus:   toStatus,
+	}
+
+	if userID := ctxhelper.UserID(ctx); userID != 0 {
+		transition.ActorID = null.IntFrom(userID)
+	}
+
+	before := *company
+
+	company.OperationStatus = toStatus
+
+	err := s.companyRepository.Save(ctx, operations, company)
+	if err != nil {
+		return err
+	}
+
+	err = s.companyStatusTransitionRepository.Save(ctx, operations, transition)
+	if err != nil {
+		return err
+	}
+
+	return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionStatusChange, company.ID, &before, company)
+}
+
+func (s *AppCompanyLifecycleService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppCompanyLifecycleService) invalidTransitionError(
+	company *entities.Company,
+	toStatus entities.OperationStatusType,
+) error {
+
+	return utils.NewErrorWithCode(
+		errors.New("invalid status transition"),
+		utils.ErrorCodeInvalidTransition,
+		"cannot transition company = %v from [%v] to [%v]",
+		company.ID,
+		company.OperationStatus,
+		toStatus,
+	)
+}
+
+// openSubsidiaries returns the subsidiaries below company, at any level,
+// that are not closed yet.
+func (s *AppCompanyLifecycleService) openSubsidiaries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	company *entities.Company,
+) ([]*entities.Company, error) {
+
+	descendants, err := s.companyRepository.DescendantCompanies(ctx, operations, company.ID)
+	if err != nil {
+		return []*entities.Company{}, err
+	}
+
+	subsidiaries := make([]*entities.Company, 0, len(descendants))
+
+	for _, descendant := range descendants {
+		if descendant.OperationStatus.CanTransitionTo(entities.OperationStatusTypeClosed) {
+			subsidiaries = append(subsidiaries, descendant)
+		}
+	}
+
+	return subsidiaries, nil
+}
+
+func (s *AppCompanyLifecycleService) requireReason(
+	form *forms.CompanyStatusForm,
+) (string, error) {
+
+	reason := strings.TrimSpace(form.Reason)
+	if reason == "" {
+		return "", utils.NewErrorWithCode(
+			errors.New("reason is required"),
+			utils.ErrorCodeInvalidForm,
+			"missing reason for company status change",
+		)
+	}
+
+	return reason, nil
+}
+
+// validateParentCompanyOpen prevents a subsidiary from operating while its
+// parent company is closed.
+func (s *AppCompanyLifecycleService) validateParentCompanyOpen(
+	ctx context.Context,
+	operations db.SQLOperations,
+	company *entities.Company,
+) error {
+
+	if !company.ParentCompanyID.Valid {
+		return nil
+	}
+
+	parentCompany, err := s.companyRepository.CompanyByID(ctx, operations, company.ParentCompanyID.Int64)
+	if err != nil {
+		if utils.IsErrNoRows(err) {
+			return nil
+		}
+		return err
+	}
+
+	if parentCompany.OperationStatus != entities.OperationStatusTypeClosed {
+		return nil
+	}
+
+	return utils.NewErrorWithCode(
+		errors.New("parent company is closed"),
+		utils.ErrorCodeInvalidTransition,
+		"cannot activate company = %v while parent company = %v is closed",
+		company.ID,
+		parentCompany.ID,
+	)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyLifecycleService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyLifecycleService := NewTestCompanyLifecycleService()
+	companyRepository := repos.NewCompanyRepository()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Lifecycle Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		user, err := repos.CreateUser(ctx, dB)
+		So(err, ShouldBeNil)
+
+		ctx = ctxhelper.WithUserID(ctx, user.ID)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		Convey("can activate a pending company", func() {
+
+			company.OperationStatus = entities.OperationStatusTypePending
+			err := companyRepository.Save(ctx, dB, company)
+			So(err, ShouldBeNil)
+
+			activatedCompany, err := companyLifecycleService.ActivateCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{})
+			So(err, ShouldBeNil)
+			So(activatedCompany.OperationStatus, ShouldEqual, entities.OperationStatusTypeActive)
+			So(len(activatedCompany.StatusHistory), ShouldEqual, 1)
+		})
+
+		Convey("cannot activate an active company", func() {
+
+			_, err := companyLifecycleService.ActivateCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidTransition)
+		})
+
+		Convey("can close and reopen a company with a reason", func() {
+
+			closedCompany, err := companyLifecycleService.CloseCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{Reason: "license revoked"})
+			So(err, ShouldBeNil)
+			So(closedCompany.OperationStatus, ShouldEqual, entities.OperationStatusTypeClosed)
+
+			reopenedCompany, err := companyLifecycleService.ReopenCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{Reason: "license reinstated"})
+			So(err, ShouldBeNil)
+			So(reopenedCompany.OperationStatus, ShouldEqual, entities.OperationStatusTypeActive)
+
+			foundCompany, err := companyService.GetCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(foundCompany.StatusHistory), ShouldEqual, 2)
+
+			closeTransition := foundCompany.StatusHistory[0]
+			So(closeTransition.FromStatus, ShouldEqual, entities.OperationStatusTypeActive)
+			So(closeTransition.ToStatus, ShouldEqual, entities.OperationStatusTypeClosed)
+			So(closeTransition.Reason, ShouldEqual, "license revoked")
+			So(closeTransition.ActorID.Int64, ShouldEqual, user.ID)
+		})
+
+		Convey("cannot close a company without a reason", func() {
+
+			_, err := companyLifecycleService.CloseCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("cannot reopen an active company", func() {
+
+			_, err := companyLifecycleService.ReopenCompany(ctx, dB, company.ID, &forms.CompanyStatusForm{Reason: "mistake"})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidTransition)
+		})
+	}))
+}
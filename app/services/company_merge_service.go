@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+)
+
+type (
+	CompanyMergeService interface {
+		FindDuplicateCompanies(ctx context.Context, dB db.DB, companyID int64) ([]*entities.CompanyDuplicate, error)
+		MergeCompanies(ctx context.Context, dB db.DB, companyID int64, form *forms.MergeCompanyForm) (*entities.Company, error)
+	}
+
+	AppCompanyMergeService struct {
+		auditLogRepository                repos.AuditLogRepository
+		companyAddressRepository          repos.CompanyAddressRepository
+		companyContactRepository          repos.CompanyContactRepository
+		companyCountryRepository          repos.CompanyCountryRepository
+		companyDocumentRepository         repos.CompanyDocumentRepository
+		companyIdentifierRepository       repos.CompanyIdentifierRepository
+		companyRedirectRepository         repos.CompanyRedirectRepository
+		companyRepository                 repos.CompanyRepository
+		companyStatusTransitionRepository repos.CompanyStatusTransitionRepository
+		companyTagRepository              repos.CompanyTagRepository
+	}
+)
+
+func NewCompanyMergeService(
+	auditLogRepository repos.AuditLogRepository,
+	companyAddressRepository repos.CompanyAddressRepository,
+	companyContactRepository repos.CompanyContactRepository,
+	companyCountryRepository repos.CompanyCountryRepository,
+	companyDocumentRepository repos.CompanyDocumentRepository,
+	companyIdentifierRepository repos.CompanyIdentifierRepository,
+	companyRedirectRepository repos.CompanyRedirectRepository,
+	companyRepository repos.CompanyRepository,
+	companyStatusTransitionRepository repos.CompanyStatusTransitionRepository,
+	companyTagRepository repos.CompanyTagRepository,
+) *AppCompanyMergeService {
+	return &AppCompanyMergeService{
+		auditLogRepository:                auditLogRepository,
+		companyAddressRepository:          companyAddressRepository,
+		companyContactRepository:          companyContactRepository,
+		companyCountryRepository:          companyCountryRepository,
+		companyDocumentRepository:         companyDocumentRepository,
+		companyIdentifierRepository:       companyIdentifierRepository,
+		companyRedirectRepository:         companyRedirectRepository,
+		companyRepository:                 companyRepository,
+		companyStatusTransitionRepository: companyStatusTransitionRepository,
+		companyTagRepository:              companyTagRepository,
+	}
+}
+
+func NewTestCompanyMergeService() *AppCompanyMergeService {
+	return NewCompanyMergeService(
+		repos.NewAuditLogRepository(),
+		repos.NewCompanyAddressRepository(),
+		repos.NewCompanyContactRepository(),
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyDocumentRepository(),
+		repos.NewCompanyIdentifierRepository(),
+		repos.NewCompanyRedirectRepository(),
+		repos.NewCompanyRepository(),
+		repos.NewCompanyStatusTransitionRepository(),
+		repos.NewCompanyTagRepository(),
+	)
+}
+
+func (s *AppCompanyMergeService) FindDuplicateCompanies(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.CompanyDuplicate, error) {
+
+	_, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyDuplicate{}, err
+	}
+
+	duplicates, err := s.companyRepository.DuplicateCompanies(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.CompanyDuplicate{}, err
+	}
+
+	if len(duplicates) == 0 {
+		return duplicates, nil
+	}
+
+	companies := make([]*entities.Company, 0, len(duplicates))
+	companyIDs := make([]int64, 0, len(duplicates))
+
+	for _, duplicate := range duplicates {
+		companies = append(companies, duplicate.Company)
+		companyIDs = append(companyIDs, duplicate.Company.ID)
+	}
+
+	companyCountries, err := s.companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, companyIDs)
+	if err != nil {
+		return []*entities.CompanyDuplicate{}, err
+	}
+
+	for _, company := range companies {
+		company.Countries = companyCountries[company.ID]
+		if company.Countries == nil {
+			company.Countries = []*entities.CompanyCountry{}
+		}
+	}
+
+	return duplicates, nil
+}
+
+// MergeCompanies folds the company named in the form into the surviving
+// company. Countries and their addresses, status history, subsidiaries, tags
+// and earlier redirects move across. Contacts and identifiers move unless the
+// survivor already has the same email or identifier type. The merged company
+// is removed and a redirect is left behind for its id.
+func (s *AppCompanyMergeService) MergeCompanies(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.MergeCompanyForm,
+) (*entities.Company, error) {
+
+	if form.MergedCompanyID == companyID {
+		return &entities.Company{}, utils.NewErrorWithCode(
+			errors.New("cannot merge company into itself"),
+			utils.ErrorCodeInvalidArgument,
+			"company = %v cannot be merged into itself",
+			companyID,
+		)
+	}
+
+	var company *entities.Company
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.companyRepository.LockCompanyHierarchy(ctx, operations)
+		if err != nil {
+			return err
+		}
+
+		survivor, err := s.getCompany(ctx, operations, companyID)
+		if err != nil {
+			return err
+		}
+
+		merged, err := s.getCompany(ctx, operations, form.MergedCompanyID)
+		if err != nil {
+			return err
+		}
+
+		isAncestor, err := s.companyRepository.IsCompanyAncestor(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		if isAncestor {
+			return utils.NewErrorWithCode(
+				errors.New("cannot merge company into its subsidiary"),
+				utils.ErrorCodeInvalidArgument,
+				"company = %v is a parent of company = %v",
+				merged.ID,
+				survivor.ID,
+			)
+		}
+
+		err = s.companyRepository.ReassignSubsidiaries(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		// Addresses move before countries so that those held under countries
+		// both companies share are kept rather than removed with the merged
+		// company.
+		err = s.companyAddressRepository.ReassignCompanyAddresses(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyCountryRepository.ReassignCompanyCountries(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyContactRepository.ReassignCompanyContacts(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyDocumentRepository.ReassignCompanyDocuments(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyIdentifierRepository.ReassignCompanyIdentifiers(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyStatusTransitionRepository.ReassignCompanyStatusTransitions(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyTagRepository.ReassignCompanyTags(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyRedirectRepository.ReassignCompanyRedirects(ctx, operations, merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.companyRepository.PurgeCompany(ctx, operations, merged.ID)
+		if err != nil {
+			return err
+		}
+
+		redirect := &entities.CompanyRedirect{
+			CompanyID:       survivor.ID,
+			MergedCompanyID: merged.ID,
+		}
+
+		if userID := ctxhelper.UserID(ctx); userID != 0 {
+			redirect.ActorID = null.IntFrom(userID)
+		}
+
+		err = s.companyRedirectRepository.Save(ctx, operations, redirect)
+		if err != nil {
+			return err
+		}
+
+		// Saving bumps the version so clients holding a stale ETag notice
+		// that the company changed underneath them.
+		err = s.companyRepository.Save(ctx, operations, survivor)
+		if err != nil {
+			return err
+		}
+
+		err = saveCompanyAuditLog(ctx, operations, s.auditLogRepository, entities.AuditActionMerge, survivor.ID, map[string]*entities.FieldChange{
+			"merged_company_id": {To: merged.ID},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = saveCompanyAuditLog(ctx, operations, s.auditLogRepository, entities.AuditActionMerge, merged.ID, map[string]*entities.FieldChange{
+			"merged_into_company_id": {To: survivor.ID},
+		})
+		if err != nil {
+			return err
+		}
+
+		company = survivor
+
+		return nil
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	companyCountries, err := s.companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, []int64{company.ID})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.Countries = companyCountries[company.ID]
+	if company.Countries == nil {
+		company.Countries = []*entities.CompanyCountry{}
+	}
+
+	statusHistory, err := s.companyStatusTransitionRepository.CompanyStatusTransitionsByCompanyID(ctx, dB, company.ID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.StatusHistory = statusHistory
+
+	return company, nil
+}
+
+func (s *AppCompanyMergeService) getCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, operations, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company = %v not found",
+			companyID,
+		)
+	}
+
+	return company, nil
+}
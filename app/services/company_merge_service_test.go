@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompanyMergeService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyMergeService := NewTestCompanyMergeService()
+	companyLifecycleService := NewTestCompanyLifecycleService()
+	companyRepository := repos.NewCompanyRepository()
+	companyService := NewTestCompanyService()
+
+	Convey("Company Merge Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		user, err := repos.CreateUser(ctx, dB)
+		So(err, ShouldBeNil)
+
+		ctx = ctxhelper.WithUserID(ctx, user.ID)
+
+		company, _, err := repos.CreateCompany(ctx, dB, "Acme Ltd", country)
+		So(err, ShouldBeNil)
+
+		company.Website = "https://www.acme.com"
+		err = companyRepository.Save(ctx, dB, company)
+		So(err, ShouldBeNil)
+
+		duplicate, _, err := repos.CreateCompany(ctx, dB, "ACME Limited", country)
+		So(err, ShouldBeNil)
+
+		duplicate.Website = "http://acme.com/about"
+		err = companyRepository.Save(ctx, dB, duplicate)
+		So(err, ShouldBeNil)
+
+		_, _, err = repos.CreateCompany(ctx, dB, "Globex", country)
+		So(err, ShouldBeNil)
+
+		Convey("can find duplicates by normalized name and domain", func() {
+
+			duplicates, err := companyMergeService.FindDuplicateCompanies(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(len(duplicates), ShouldEqual, 1)
+			So(duplicates[0].Company.ID, ShouldEqual, duplicate.ID)
+			So(duplicates[0].MatchedOn, ShouldResemble, []string{entities.DuplicateMatchName, entities.DuplicateMatchDomain})
+			So(len(duplicates[0].Company.Countries), ShouldEqual, 1)
+		})
+
+		Convey("can find duplicates by phone number", func() {
+
+			phoneTwin, _, err := repos.CreateCompany(ctx, dB, "Initech", country)
+			So(err, ShouldBeNil)
+
+			// The same number stored with different formatting, which the
+			// unique index on phone numbers does not catch.
+			phoneTwin.PhoneNumber = entities.PhoneNumber{
+				CountryCode: null.StringFrom(strings.TrimPrefix(company.PhoneNumber.CountryCode.String, "+")),
+				Number:      null.StringFrom(company.PhoneNumber.Number.String[:4] + " " + company.PhoneNumber.Number.String[4:]),
+			}
+			phoneTwin.Phone = phoneTwin.PhoneNumber.Phone()
+			err = companyRepository.Save(ctx, dB, phoneTwin)
+			So(err, ShouldBeNil)
+
+			duplicates, err := companyMergeService.FindDuplicateCompanies(ctx, dB, phoneTwin.ID)
+			So(err, ShouldBeNil)
+			So(len(duplicates), ShouldEqual, 1)
+			So(duplicates[0].Company.ID, ShouldEqual, company.ID)
+			So(duplicates[0].MatchedOn, ShouldResemble, []string{entities.DuplicateMatchPhone})
+		})
+
+		Convey("can merge a duplicate into the surviving company", func() {
+
+			otherCountry := &entities.Country{
+				CountryCode:  "GR",
+				Currency:     "EUR",
+				Name:         "Greece",
+				DiallingCode: "+30",
+			}
+
+			err := repos.NewCountryRepository().Save(ctx, dB, otherCountry)
+			So(err, ShouldBeNil)
+
+			_, err = repos.CreateCompanyCountry(ctx, dB, duplicate.ID, otherCountry.ID)
+			So(err, ShouldBeNil)
+
+			_, err = companyLifecycleService.CloseCompany(ctx, dB, duplicate.ID, &forms.CompanyStatusForm{Reason: "duplicate record"})
+			So(err, ShouldBeNil)
+
+			mergedCompany, err := companyMergeService.MergeCompanies(ctx, dB, company.ID, &forms.MergeCompanyForm{MergedCompanyID: duplicate.ID})
+			So(err, ShouldBeNil)
+			So(mergedCompany.ID, ShouldEqual, company.ID)
+			So(mergedCompany.Version, ShouldBeGreaterThan, company.Version)
+			So(len(mergedCompany.Countries), ShouldEqual, 2)
+			So(len(mergedCompany.StatusHistory), ShouldEqual, 1)
+
+			_, err = companyRepository.CompanyByID(ctx, dB, duplicate.ID)
+			So(utils.IsErrNoRows(err), ShouldBeTrue)
+
+			Convey("the merged company id resolves to the survivor", func() {
+
+				foundCompany, err := companyService.GetCompany(ctx, dB, duplicate.ID)
+				So(err, ShouldBeNil)
+				So(foundCompany.ID, ShouldEqual, company.ID)
+			})
+		})
+
+		Convey("cannot merge a company into itself", func() {
+
+			_, err := companyMergeService.MergeCompanies(ctx, dB, company.ID, &forms.MergeCompanyForm{MergedCompanyID: company.ID})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidArgument)
+		})
+
+		Convey("cannot merge a missing company", func() {
+
+			_, err := companyMergeService.MergeCompanies(ctx, dB, company.ID, &forms.MergeCompanyForm{MergedCompanyID: duplicate.ID + 1000})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+	}))
+}
@@ -3,47 +3,165 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/providers"
 	"github.com/vonmutinda/organono/app/repos"
 	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
 )
 
+// cursorTimeFormat keeps the microsecond precision of timestamp columns and
+// omits the zone, matching how they are stored.
+const cursorTimeFormat = "2006-01-02T15:04:05.999999"
+
+// websiteDomainBackfillBatchSize is how many companies are loaded at a time
+// when backfilling website domains.
+const websiteDomainBackfillBatchSize = 500
+
 type (
 	CompanyService interface {
+		BackfillWebsiteDomains(ctx context.Context, dB db.DB) (int64, int64, error)
 		CreateCompany(ctx context.Context, dB db.DB, form *forms.CreateCompanyForm) (*entities.Company, error)
-		DeleteCompany(ctx context.Context, dB db.DB, companyID int64) (*entities.Company, error)
+		DeleteCompany(ctx context.Context, dB db.DB, companyID int64, expectedVersion null.Int) (*entities.Company, error)
+		ExportCompanies(ctx context.Context, dB db.DB, filter *forms.Filter, fn func(*entities.Company) error) error
 		GetCompany(ctx context.Context, dB db.DB, companyID int64) (*entities.Company, error)
+		ImportCompanies(ctx context.Context, dB db.DB, form *forms.CompanyImportForm) (*entities.CompanyImportReport, error)
 		ListCompanies(ctx context.Context, dB db.DB, filter *forms.Filter) (*entities.CompanyList, error)
+		ListSubsidiaries(ctx context.Context, dB db.DB, companyID int64, filter *forms.Filter) (*entities.CompanyList, error)
+		PurgeDeletedCompanies(ctx context.Context, dB db.DB, retention time.Duration) (int64, error)
+		RestoreCompany(ctx context.Context, dB db.DB, companyID int64) (*entities.Company, error)
+		SearchCompanies(ctx context.Context, dB db.DB, filter *forms.SearchFilter) (*entities.CompanySearchResultList, error)
 		UpdateCompany(ctx context.Context, dB db.DB, companyID int64, form *forms.UpdateCompanyForm) (*entities.Company, error)
 	}
 
 	AppCompanyService struct {
-		companyCountryRepository repos.CompanyCountryRepository
-		companyRepository        repos.CompanyRepository
-		countryReposistory       repos.CountryRepository
+		auditLogRepository                repos.AuditLogRepository
+		blobStore                         providers.BlobStore
+		companyAddressRepository          repos.CompanyAddressRepository
+		companyCountryRepository          repos.CompanyCountryRepository
+		companyDocumentRepository         repos.CompanyDocumentRepository
+		companyRedirectRepository         repos.CompanyRedirectRepository
+		companyRepository                 repos.CompanyRepository
+		companyStatusTransitionRepository repos.CompanyStatusTransitionRepository
+		companyTagRepository              repos.CompanyTagRepository
+		countryReposistory                repos.CountryRepository
+		customFieldDefinitionRepository   repos.CustomFieldDefinitionRepository
 	}
 )
 
 func NewCompanyService(
+	auditLogRepository repos.AuditLogRepository,
+	blobStore providers.BlobStore,
+	companyAddressRepository repos.CompanyAddressRepository,
 	companyCountryRepository repos.CompanyCountryRepository,
+	companyDocumentRepository repos.CompanyDocumentRepository,
+	companyRedirectRepository repos.CompanyRedirectRepository,
 	companyRepository repos.CompanyRepository,
+	companyStatusTransitionRepository repos.CompanyStatusTransitionRepository,
+	companyTagRepository repos.CompanyTagRepository,
 	countryReposistory repos.CountryRepository,
+	customFieldDefinitionRepository repos.CustomFieldDefinitionRepository,
 ) *AppCompanyService {
 	return &AppCompanyService{
-		companyCountryRepository: companyCountryRepository,
-		companyRepository:        companyRepository,
-		countryReposistory:       countryReposistory,
+		auditLogRepository:                auditLogRepository,
+		blobStore:                         blobStore,
+		companyAddressRepository:          companyAddressRepository,
+		companyCountryRepository:          companyCountryRepository,
+		companyDocumentRepository:         companyDocumentRepository,
+		companyRedirectRepository:         companyRedirectRepository,
+		companyRepository:                 companyRepository,
+		companyStatusTransitionRepository: companyStatusTransitionRepository,
+		companyTagRepository:              companyTagRepository,
+		countryReposistory:                countryReposistory,
+		customFieldDefinitionRepository:   customFieldDefinitionRepository,
 	}
 }
 
 func NewTestCompanyService() *AppCompanyService {
+	return NewTestCompanyServiceWithBlobStore(providers.NewBlobStore())
+}
+
+func NewTestCompanyServiceWithBlobStore(blobStore providers.BlobStore) *AppCompanyService {
 	return &AppCompanyService{
-		companyCountryRepository: repos.NewCompanyCountryRepository(),
-		companyRepository:        repos.NewCompanyRepository(),
-		countryReposistory:       repos.NewCountryRepository(),
+		auditLogRepository:                repos.NewAuditLogRepository(),
+		blobStore:                         blobStore,
+		companyAddressRepository:          repos.NewCompanyAddressRepository(),
+		companyCountryRepository:          repos.NewCompanyCountryRepository(),
+		companyDocumentRepository:         repos.NewCompanyDocumentRepository(),
+		companyRedirectRepository:         repos.NewCompanyRedirectRepository(),
+		companyRepository:                 repos.NewCompanyRepository(),
+		companyStatusTransitionRepository: repos.NewCompanyStatusTransitionRepository(),
+		companyTagRepository:              repos.NewCompanyTagRepository(),
+		countryReposistory:                repos.NewCountryRepository(),
+		customFieldDefinitionRepository:   repos.NewCustomFieldDefinitionRepository(),
+	}
+}
+
+// BackfillWebsiteDomains derives the website domain of companies stored
+// before domains were introduced with utils.NormalizeWebsite, the same way
+// new companies are checked. Companies are visited from the oldest, and a live
+// company whose domain is already taken is recorded as a conflict instead. It
+// returns how many domains were set and how many conflicts were recorded.
+func (s *AppCompanyService) BackfillWebsiteDomains(
+	ctx context.Context,
+	dB db.DB,
+) (int64, int64, error) {
+
+	var backfilled, conflicts, afterID int64
+
+	for {
+		companies, err := s.companyRepository.CompaniesWithoutWebsiteDomain(ctx, dB, afterID, websiteDomainBackfillBatchSize)
+		if err != nil {
+			return backfilled, conflicts, err
+		}
+
+		if len(companies) == 0 {
+			return backfilled, conflicts, nil
+		}
+
+		for _, company := range companies {
+
+			afterID = company.ID
+
+			website, err := utils.NormalizeWebsite(company.Website)
+			if err != nil {
+				logger.Warnf("Skipping invalid website = [%v] of company id = %v err = %v", company.Website, company.ID, err)
+				continue
+			}
+
+			conflict := false
+
+			err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+				if !company.DeletedAt.Valid {
+					keptCompany, err := s.companyRepository.CompanyByWebsite(ctx, operations, website.Domain)
+					if err != nil && !utils.IsErrNoRows(err) {
+						return err
+					}
+
+					if err == nil && keptCompany.ID != company.ID {
+						conflict = true
+						return s.companyRepository.RecordWebsiteDomainConflict(ctx, operations, company.ID, keptCompany.ID, website.Domain)
+					}
+				}
+
+				return s.companyRepository.SetWebsiteDomain(ctx, operations, company.ID, website.Domain)
+			})
+			if err != nil {
+				return backfilled, conflicts, err
+			}
+
+			if conflict {
+				conflicts++
+			} else {
+				backfilled++
+			}
+		}
 	}
 }
 
@@ -53,44 +171,64 @@ func (s *AppCompanyService) CreateCompany(
 	form *forms.CreateCompanyForm,
 ) (*entities.Company, error) {
 
-	err := s.validateCreateCompany(ctx, dB, form)
+	company, country, err := s.buildCompany(ctx, dB, form)
 	if err != nil {
 		return &entities.Company{}, err
 	}
 
-	country, err := s.getCountryByName(ctx, dB, form.Country)
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+		return s.saveNewCompany(ctx, operations, company, country)
+	})
 	if err != nil {
 		return &entities.Company{}, err
 	}
 
-	phoneNumber, err := utils.ParsePhoneNumber(form.Phone)
+	return company, nil
+}
+
+func (s *AppCompanyService) DeleteCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	expectedVersion null.Int,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	err = s.validateCompanyVersion(company, expectedVersion)
 	if err != nil {
 		return &entities.Company{}, err
 	}
 
-	company := &entities.Company{
-		Name:        form.Name,
-		Code:        form.Code,
-		Website:     form.Website,
-		Country:     country.Name,
-		PhoneNumber: phoneNumber,
-		Phone:       phoneNumber.Phone(),
+	err = s.loadCompanyCountries(ctx, dB, company)
+	if err != nil {
+		return &entities.Company{}, err
 	}
 
 	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
 
-		err = s.companyRepository.Save(ctx, operations, company)
+		err := s.detachSubsidiaries(ctx, operations, company.ID)
 		if err != nil {
 			return err
 		}
 
-		companyCountry := entities.CompanyCountry{
-			CompanyID:       company.ID,
-			CountryID:       country.ID,
-			OperationStatus: entities.OperationStatusTypeActive,
+		err = s.companyRepository.DeleteCompany(ctx, operations, company.ID, company.Version)
+		if err != nil {
+			return err
 		}
 
-		return s.companyCountryRepository.Save(ctx, operations, &companyCountry)
+		return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionDelete, company.ID, company, nil)
 	})
 	if err != nil {
 		return &entities.Company{}, err
@@ -99,7 +237,18 @@ func (s *AppCompanyService) CreateCompany(
 	return company, nil
 }
 
-func (s *AppCompanyService) DeleteCompany(
+func (s *AppCompanyService) ExportCompanies(
+	ctx context.Context,
+	dB db.DB,
+	filter *forms.Filter,
+	fn func(*entities.Company) error,
+) error {
+	return s.companyRepository.StreamCompanies(ctx, dB, filter, fn)
+}
+
+// GetCompany returns the company with the given id. When the company was
+// merged away the surviving company is returned instead.
+func (s *AppCompanyService) GetCompany(
 	ctx context.Context,
 	dB db.DB,
 	companyID int64,
@@ -111,28 +260,181 @@ func (s *AppCompanyService) DeleteCompany(
 			return &entities.Company{}, err
 		}
 
-		return &entities.Company{}, utils.NewErrorWithCode(
+		company, err = s.redirectedCompany(ctx, dB, companyID)
+		if err != nil {
+			return &entities.Company{}, err
+		}
+	}
+
+	err = s.loadCompanyCountries(ctx, dB, company)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	addresses, err := s.companyAddressRepository.CompanyAddressesByCompanyID(ctx, dB, company.ID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.Addresses = addresses
+
+	err = s.loadCompanyTags(ctx, dB, company)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	statusHistory, err := s.companyStatusTransitionRepository.CompanyStatusTransitionsByCompanyID(ctx, dB, company.ID)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	company.StatusHistory = statusHistory
+
+	return company, nil
+}
+
+func (s *AppCompanyService) ListCompanies(
+	ctx context.Context,
+	dB db.DB,
+	filter *forms.Filter,
+) (*entities.CompanyList, error) {
+
+	companies, err := s.companyRepository.ListCompanies(ctx, dB, filter)
+	if err != nil {
+		return &entities.CompanyList{}, err
+	}
+
+	hasMore := false
+	if filter.HasLookahead() && len(companies) > filter.Per {
+		companies = companies[:filter.Per]
+		hasMore = true
+	}
+
+	err = s.loadCompanyCountries(ctx, dB, companies...)
+	if err != nil {
+		return &entities.CompanyList{}, err
+	}
+
+	err = s.loadCompanyTags(ctx, dB, companies...)
+	if err != nil {
+		return &entities.CompanyList{}, err
+	}
+
+	count := 0
+	if !filter.SkipCount {
+		count, err = s.companyRepository.CompanyCount(ctx, dB, filter)
+		if err != nil {
+			return &entities.CompanyList{}, err
+		}
+	}
+
+	var pagination *entities.Pagination
+
+	switch {
+	case filter.Cursor:
+		var nextCursor null.String
+		if hasMore {
+			nextCursor = null.StringFrom(companyCursor(filter, companies[len(companies)-1]).Encode())
+		}
+
+		pagination = entities.NewCursorPagination(count, filter.Per, nextCursor)
+	case filter.SkipCount:
+		pagination = entities.NewUncountedPagination(filter.Page, filter.Per, hasMore)
+	default:
+		pagination = entities.NewPagination(count, filter.Page, filter.Per)
+	}
+
+	companyList := &entities.CompanyList{
+		Companies:  companies,
+		Pagination: pagination,
+	}
+
+	return companyList, nil
+}
+
+// ListSubsidiaries lists the direct subsidiaries of a company with the same
+// filtering and pagination as ListCompanies.
+func (s *AppCompanyService) ListSubsidiaries(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	filter *forms.Filter,
+) (*entities.CompanyList, error) {
+
+	_, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CompanyList{}, err
+		}
+
+		return &entities.CompanyList{}, utils.NewErrorWithCode(
 			err,
 			utils.ErrorCodeNotFound,
 			"company not found",
 		)
 	}
 
-	err = s.companyRepository.DeleteCompany(ctx, dB, company.ID)
+	filter.ParentID = companyID
+
+	return s.ListCompanies(ctx, dB, filter)
+}
+
+func (s *AppCompanyService) PurgeDeletedCompanies(
+	ctx context.Context,
+	dB db.DB,
+	retention time.Duration,
+) (int64, error) {
+
+	if retention < 0 {
+		return 0, utils.NewErrorWithCode(
+			errors.New("negative retention"),
+			utils.ErrorCodeInvalidArgument,
+			"invalid purge retention = %v",
+			retention,
+		)
+	}
+
+	deletedBefore := time.Now().Add(-retention)
+
+	var documents []*entities.CompanyDocument
+	var purged int64
+
+	err := dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		var err error
+
+		documents, err = s.companyDocumentRepository.DeletedCompanyDocuments(ctx, operations, deletedBefore)
+		if err != nil {
+			return err
+		}
+
+		purged, err = s.companyRepository.PurgeDeletedCompanies(ctx, operations, deletedBefore)
+
+		return err
+	})
 	if err != nil {
-		return &entities.Company{}, err
+		return 0, err
 	}
 
-	return company, nil
+	// Document contents are only removed once their records are gone, a
+	// failure leaves an unreferenced blob behind and is logged.
+	for _, document := range documents {
+		err := s.blobStore.Delete(ctx, document.StorageKey)
+		if err != nil {
+			logger.Errorf("Failed to delete contents of purged document id = %v key = [%v] err = %v", document.ID, document.StorageKey, err)
+		}
+	}
+
+	return purged, nil
 }
 
-func (s *AppCompanyService) GetCompany(
+func (s *AppCompanyService) RestoreCompany(
 	ctx context.Context,
 	dB db.DB,
 	companyID int64,
 ) (*entities.Company, error) {
 
-	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	company, err := s.companyRepository.DeletedCompanyByID(ctx, dB, companyID)
 	if err != nil {
 		if !utils.IsErrNoRows(err) {
 			return &entities.Company{}, err
@@ -141,35 +443,75 @@ func (s *AppCompanyService) GetCompany(
 		return &entities.Company{}, utils.NewErrorWithCode(
 			err,
 			utils.ErrorCodeNotFound,
-			"company not found",
+			"deleted company not found",
 		)
 	}
 
+	err = s.validateCreateCompany(ctx, dB, &forms.CreateCompanyForm{
+		Name:    company.Name,
+		Code:    company.Code,
+		Website: company.Website,
+		Phone:   company.PhoneNumber.Phone(),
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	before := *company
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.companyRepository.RestoreCompany(ctx, operations, company)
+		if err != nil {
+			return err
+		}
+
+		return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionRestore, company.ID, &before, company)
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	err = s.loadCompanyCountries(ctx, dB, company)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
 	return company, nil
 }
 
-func (s *AppCompanyService) ListCompanies(
+func (s *AppCompanyService) SearchCompanies(
 	ctx context.Context,
 	dB db.DB,
-	filter *forms.Filter,
-) (*entities.CompanyList, error) {
+	filter *forms.SearchFilter,
+) (*entities.CompanySearchResultList, error) {
 
-	companies, err := s.companyRepository.ListCompanies(ctx, dB, filter)
+	results, err := s.companyRepository.SearchCompanies(ctx, dB, filter)
 	if err != nil {
-		return &entities.CompanyList{}, err
+		return &entities.CompanySearchResultList{}, err
+	}
+
+	hasMore := len(results) > filter.Per
+	if hasMore {
+		results = results[:filter.Per]
 	}
 
-	count, err := s.companyRepository.CompanyCount(ctx, dB, filter)
+	companies := make([]*entities.Company, 0, len(results))
+	for _, result := range results {
+		companies = append(companies, result.Company)
+	}
+
+	err = s.loadCompanyCountries(ctx, dB, companies...)
 	if err != nil {
-		return &entities.CompanyList{}, err
+		return &entities.CompanySearchResultList{}, err
 	}
 
-	companyList := &entities.CompanyList{
-		Companies:  companies,
-		Pagination: entities.NewPagination(count, filter.Page, filter.Per),
+	resultList := &entities.CompanySearchResultList{
+		Results:    results,
+		Pagination: entities.NewUncountedPagination(filter.Page, filter.Per, hasMore),
 	}
 
-	return companyList, nil
+	return resultList, nil
 }
 
 func (s *AppCompanyService) UpdateCompany(
@@ -184,11 +526,18 @@ func (s *AppCompanyService) UpdateCompany(
 		return &entities.Company{}, err
 	}
 
-	err = s.validateUpdateCompany(ctx, dB, form)
+	err = s.validateCompanyVersion(company, form.Version)
 	if err != nil {
 		return &entities.Company{}, err
 	}
 
+	err = s.validateUpdateCompany(ctx, dB, company.ID, form)
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	before := *company
+
 	if form.Phone.Valid {
 		phoneNumber, err := utils.ParsePhoneNumber(form.Phone.String)
 		if err != nil {
@@ -208,10 +557,38 @@ func (s *AppCompanyService) UpdateCompany(
 	}
 
 	if form.Website.Valid {
-		company.Website = form.Website.String
+		website, err := utils.NormalizeWebsite(form.Website.String)
+		if err != nil {
+			return &entities.Company{}, err
+		}
+
+		company.Website = website.URL
+		company.WebsiteDomain = website.Domain
+	}
+
+	if form.CustomFields != nil {
+		customFields, err := applyCustomFields(ctx, dB, s.customFieldDefinitionRepository, company.CustomFields, form.CustomFields)
+		if err != nil {
+			return &entities.Company{}, err
+		}
+
+		company.CustomFields = customFields
 	}
 
-	err = s.companyRepository.Save(ctx, dB, company)
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.companyRepository.Save(ctx, operations, company)
+		if err != nil {
+			return err
+		}
+
+		return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionUpdate, company.ID, &before, company)
+	})
+	if err != nil {
+		return &entities.Company{}, err
+	}
+
+	err = s.loadCompanyCountries(ctx, dB, company)
 	if err != nil {
 		return &entities.Company{}, err
 	}
@@ -219,6 +596,90 @@ func (s *AppCompanyService) UpdateCompany(
 	return company, nil
 }
 
+func (s *AppCompanyService) buildCompany(
+	ctx context.Context,
+	dB db.DB,
+	form *forms.CreateCompanyForm,
+) (*entities.Company, *entities.Country, error) {
+
+	err := s.validateCreateCompany(ctx, dB, form)
+	if err != nil {
+		return &entities.Company{}, &entities.Country{}, err
+	}
+
+	country, err := s.getCountryByName(ctx, dB, form.Country)
+	if err != nil {
+		return &entities.Company{}, &entities.Country{}, err
+	}
+
+	phoneNumber, err := utils.ParsePhoneNumber(form.Phone)
+	if err != nil {
+		return &entities.Company{}, &entities.Country{}, err
+	}
+
+	website, err := utils.NormalizeWebsite(form.Website)
+	if err != nil {
+		return &entities.Company{}, &entities.Country{}, err
+	}
+
+	if form.ParentCompanyID.Valid {
+		err = validateParentCompany(ctx, dB, s.companyRepository, 0, form.ParentCompanyID.Int64)
+		if err != nil {
+			return &entities.Company{}, &entities.Country{}, err
+		}
+	}
+
+	customFields, err := applyCustomFields(ctx, dB, s.customFieldDefinitionRepository, map[string]interface{}{}, form.CustomFields)
+	if err != nil {
+		return &entities.Company{}, &entities.Country{}, err
+	}
+
+	company := &entities.Company{
+		Name:            form.Name,
+		Code:            form.Code,
+		Website:         website.URL,
+		WebsiteDomain:   website.Domain,
+		Country:         country.Name,
+		PhoneNumber:     phoneNumber,
+		Phone:           phoneNumber.Phone(),
+		OperationStatus: entities.OperationStatusTypePending,
+		ParentCompanyID: form.ParentCompanyID,
+		CustomFields:    customFields,
+	}
+
+	return company, country, nil
+}
+
+func (s *AppCompanyService) saveNewCompany(
+	ctx context.Context,
+	operations db.SQLOperations,
+	company *entities.Company,
+	country *entities.Country,
+) error {
+
+	err := s.companyRepository.Save(ctx, operations, company)
+	if err != nil {
+		return err
+	}
+
+	companyCountry := &entities.CompanyCountry{
+		CompanyID:       company.ID,
+		CountryID:       country.ID,
+		CountryCode:     country.CountryCode,
+		CountryName:     country.Name,
+		OperationStatus: entities.OperationStatusTypeActive,
+	}
+
+	err = s.companyCountryRepository.Save(ctx, operations, companyCountry)
+	if err != nil {
+		return err
+	}
+
+	company.Countries = []*entities.CompanyCountry{companyCountry}
+
+	return recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionCreate, company.ID, nil, company)
+}
+
 func (s *AppCompanyService) validateCreateCompany(
 	ctx context.Context,
 	dB db.DB,
@@ -240,7 +701,7 @@ func (s *AppCompanyService) validateCreateCompany(
 		return err
 	}
 
-	err = s.validateDuplicateCompanyWebsite(ctx, dB, form.Website)
+	err = s.validateDuplicateCompanyWebsite(ctx, dB, 0, form.Website)
 	if err != nil {
 		return err
 	}
@@ -251,6 +712,7 @@ func (s *AppCompanyService) validateCreateCompany(
 func (s *AppCompanyService) validateUpdateCompany(
 	ctx context.Context,
 	dB db.DB,
+	companyID int64,
 	form *forms.UpdateCompanyForm,
 ) error {
 
@@ -276,7 +738,7 @@ func (s *AppCompanyService) validateUpdateCompany(
 	}
 
 	if form.Website.Valid {
-		err := s.validateDuplicateCompanyWebsite(ctx, dB, form.Website.String)
+		err := s.validateDuplicateCompanyWebsite(ctx, dB, companyID, form.Website.String)
 		if err != nil {
 			return err
 		}
@@ -285,6 +747,25 @@ func (s *AppCompanyService) validateUpdateCompany(
 	return nil
 }
 
+func (s *AppCompanyService) validateCompanyVersion(
+	company *entities.Company,
+	expectedVersion null.Int,
+) error {
+
+	if !expectedVersion.Valid || expectedVersion.Int64 == company.Version {
+		return nil
+	}
+
+	return utils.NewErrorWithCode(
+		errors.New("company version mismatch"),
+		utils.ErrorCodePreconditionFailed,
+		"company id = %v is at version = %v, expected version = %v",
+		company.ID,
+		company.Version,
+		expectedVersion.Int64,
+	)
+}
+
 func (s *AppCompanyService) validateDuplicateCompanyCode(
 	ctx context.Context,
 	dB db.DB,
@@ -353,13 +834,22 @@ func (s *AppCompanyService) validateDuplicateCompanyPhoneNumber(
 	)
 }
 
+// validateDuplicateCompanyWebsite compares canonical domains so that the
+// same website cannot be registered twice by varying its scheme, "www."
+// prefix, port or path. The company being updated, if any, is ignored.
 func (s *AppCompanyService) validateDuplicateCompanyWebsite(
 	ctx context.Context,
 	dB db.DB,
-	code string,
+	companyID int64,
+	websiteURL string,
 ) error {
 
-	_, err := s.companyRepository.CompanyByWebsite(ctx, dB, code)
+	website, err := utils.NormalizeWebsite(websiteURL)
+	if err != nil {
+		return err
+	}
+
+	company, err := s.companyRepository.CompanyByWebsite(ctx, dB, website.Domain)
 	if err != nil {
 		if !utils.IsErrNoRows(err) {
 			return err
@@ -367,6 +857,10 @@ func (s *AppCompanyService) validateDuplicateCompanyWebsite(
 		return nil
 	}
 
+	if company.ID == companyID {
+		return nil
+	}
+
 	return utils.NewErrorWithCode(
 		errors.New("company website already exists"),
 		utils.ErrorCodeResourceExists,
@@ -374,6 +868,78 @@ func (s *AppCompanyService) validateDuplicateCompanyWebsite(
 	)
 }
 
+func (s *AppCompanyService) redirectedCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	redirect, err := s.companyRedirectRepository.CompanyRedirectByMergedCompanyID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, redirect.CompanyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+// detachSubsidiaries turns the direct subsidiaries of a company that is
+// being deleted into top level companies.
+func (s *AppCompanyService) detachSubsidiaries(
+	ctx context.Context,
+	operations db.SQLOperations,
+	companyID int64,
+) error {
+
+	descendants, err := s.companyRepository.DescendantCompanies(ctx, operations, companyID)
+	if err != nil {
+		return err
+	}
+
+	for _, subsidiary := range descendants {
+
+		if subsidiary.ParentCompanyID.Int64 != companyID {
+			continue
+		}
+
+		before := *subsidiary
+
+		subsidiary.ParentCompanyID = null.Int{}
+
+		err = s.companyRepository.Save(ctx, operations, subsidiary)
+		if err != nil {
+			return err
+		}
+
+		err = recordCompanyAudit(ctx, operations, s.auditLogRepository, entities.AuditActionUpdate, subsidiary.ID, &before, subsidiary)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *AppCompanyService) getCountryByName(
 	ctx context.Context,
 	dB db.DB,
@@ -395,3 +961,86 @@ func (s *AppCompanyService) getCountryByName(
 
 	return country, nil
 }
+
+func (s *AppCompanyService) loadCompanyCountries(
+	ctx context.Context,
+	dB db.DB,
+	companies ...*entities.Company,
+) error {
+
+	companyIDs := make([]int64, 0, len(companies))
+	for _, company := range companies {
+		companyIDs = append(companyIDs, company.ID)
+	}
+
+	companyCountries, err := s.companyCountryRepository.CompanyCountriesByCompanyIDs(ctx, dB, companyIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, company := range companies {
+		company.Countries = companyCountries[company.ID]
+		if company.Countries == nil {
+			company.Countries = []*entities.CompanyCountry{}
+		}
+	}
+
+	return nil
+}
+
+func (s *AppCompanyService) loadCompanyTags(
+	ctx context.Context,
+	dB db.DB,
+	companies ...*entities.Company,
+) error {
+
+	companyIDs := make([]int64, 0, len(companies))
+	for _, company := range companies {
+		companyIDs = append(companyIDs, company.ID)
+	}
+
+	companyTags, err := s.companyTagRepository.CompanyTagsByCompanyIDs(ctx, dB, companyIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, company := range companies {
+		company.Tags = companyTags[company.ID]
+	}
+
+	return nil
+}
+
+func companyCursor(
+	filter *forms.Filter,
+	company *entities.Company,
+) *entities.CompanyCursor {
+
+	values := make([]string, 0, len(filter.SortFields()))
+
+	for _, sortField := range filter.SortFields() {
+
+		var value string
+
+		switch sortField.Field {
+		case "code":
+			value = company.Code
+		case "country":
+			value = company.Country
+		case "created_at":
+			value = company.CreatedAt.Format(cursorTimeFormat)
+		case "updated_at":
+			value = company.UpdatedAt.Format(cursorTimeFormat)
+		default:
+			value = company.Name
+		}
+
+		values = append(values, value)
+	}
+
+	return &entities.CompanyCursor{
+		ID:     company.ID,
+		Sort:   filter.SortKey(),
+		Values: values,
+	}
+}
@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/repos"
 	"github.com/vonmutinda/organono/app/utils"
@@ -97,6 +99,61 @@ func TestCompanyServiceRepository(t *testing.T) {
 			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
 		})
 
+		Convey("cannot create a company with a variant of an existing website", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			form := &forms.CreateCompanyForm{
+				Name:    "Microsoft",
+				Code:    "SOFT",
+				Country: "cyprus",
+				Website: "HTTP://www." + company.WebsiteDomain + ":80/about/",
+				Phone:   "+35790034567",
+			}
+
+			_, err = companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("can create a company with a normalized website", func() {
+
+			form := &forms.CreateCompanyForm{
+				Name:    "Microsoft",
+				Code:    "SOFT",
+				Country: "cyprus",
+				Website: "WWW.Microsoft.com:443/",
+				Phone:   "+35790034567",
+			}
+
+			company, err := companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldBeNil)
+			So(company.Website, ShouldEqual, "https://www.microsoft.com")
+			So(company.WebsiteDomain, ShouldEqual, "microsoft.com")
+		})
+
+		Convey("cannot create a company with an invalid website", func() {
+
+			form := &forms.CreateCompanyForm{
+				Name:    "Microsoft",
+				Code:    "SOFT",
+				Country: "cyprus",
+				Website: "ftp://microsoft",
+				Phone:   "+35790034567",
+			}
+
+			_, err := companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidWebsite)
+		})
+
 		Convey("cannot create a company with an existing phone number", func() {
 
 			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
@@ -139,6 +196,57 @@ func TestCompanyServiceRepository(t *testing.T) {
 			So(updatedCompany.Phone, ShouldEqual, form.Phone.String)
 		})
 
+		Convey("can update a company at the expected version", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			form := &forms.UpdateCompanyForm{
+				Name:    null.StringFrom("Burger King"),
+				Version: null.IntFrom(company.Version),
+			}
+
+			updatedCompany, err := companyService.UpdateCompany(ctx, dB, company.ID, form)
+			So(err, ShouldBeNil)
+			So(updatedCompany.Version, ShouldEqual, company.Version+1)
+		})
+
+		Convey("cannot update a company with a stale version", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			_, err = companyService.UpdateCompany(ctx, dB, company.ID, &forms.UpdateCompanyForm{
+				Name: null.StringFrom("Burger King"),
+			})
+			So(err, ShouldBeNil)
+
+			form := &forms.UpdateCompanyForm{
+				Name:    null.StringFrom("McDonalds"),
+				Version: null.IntFrom(company.Version),
+			}
+
+			_, err = companyService.UpdateCompany(ctx, dB, company.ID, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodePreconditionFailed)
+		})
+
+		Convey("cannot delete a company with a stale version", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			_, err = companyService.DeleteCompany(ctx, dB, company.ID, null.IntFrom(company.Version+1))
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodePreconditionFailed)
+		})
+
 		Convey("cannot update a company with an existing name", func() {
 
 			company, _, err := repos.CreateCompany(ctx, dB, "Burger King", country)
@@ -178,8 +286,11 @@ func TestCompanyServiceRepository(t *testing.T) {
 			company, _, err := repos.CreateCompany(ctx, dB, "Burger King", country)
 			So(err, ShouldBeNil)
 
+			existingCompany, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
 			form := &forms.UpdateCompanyForm{
-				Website: null.StringFrom(company.Website),
+				Website: null.StringFrom(existingCompany.Website),
 			}
 
 			_, err = companyService.UpdateCompany(ctx, dB, company.ID, form)
@@ -234,17 +345,273 @@ func TestCompanyServiceRepository(t *testing.T) {
 			So(foundCompanyList.Pagination.Count, ShouldEqual, 3)
 		})
 
+		Convey("can list companies with a cursor", func() {
+
+			appleCyprus, _, err := repos.CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			microsoftCyprus, _, err := repos.CreateCompany(ctx, dB, "Microsoft", country)
+			So(err, ShouldBeNil)
+
+			googleCyprus, _, err := repos.CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.Filter{
+				Cursor:    true,
+				Per:       2,
+				SkipCount: true,
+			}
+
+			firstPage, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(firstPage.Companies), ShouldEqual, 2)
+			So(firstPage.Companies[0].ID, ShouldEqual, appleCyprus.ID)
+			So(firstPage.Companies[1].ID, ShouldEqual, googleCyprus.ID)
+			So(firstPage.Pagination.NextCursor.Valid, ShouldBeTrue)
+			So(firstPage.Pagination.Count, ShouldEqual, 0)
+
+			after, err := entities.DecodeCompanyCursor(firstPage.Pagination.NextCursor.String)
+			So(err, ShouldBeNil)
+
+			// A company sorting before the cursor must not shift the next page.
+			_, _, err = repos.CreateCompany(ctx, dB, "Amazon", country)
+			So(err, ShouldBeNil)
+
+			filter.After = after
+
+			secondPage, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(secondPage.Companies), ShouldEqual, 1)
+			So(secondPage.Companies[0].ID, ShouldEqual, microsoftCyprus.ID)
+			So(secondPage.Pagination.NextCursor.Valid, ShouldBeFalse)
+		})
+
+		Convey("can list companies with custom sort and filters", func() {
+
+			appleCyprus, _, err := repos.CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			googleCyprus, _, err := repos.CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.Filter{
+				Page: 1,
+				Per:  10,
+				Sort: []forms.SortField{{Field: "created_at", Descending: true}},
+			}
+
+			foundCompanyList, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(foundCompanyList.Companies), ShouldEqual, 2)
+			So(foundCompanyList.Companies[0].ID, ShouldEqual, googleCyprus.ID)
+			So(foundCompanyList.Companies[1].ID, ShouldEqual, appleCyprus.ID)
+
+			filter = &forms.Filter{
+				Page:             1,
+				Per:              10,
+				Code:             appleCyprus.Code,
+				Country:          country.CountryCode,
+				PhoneCountryCode: appleCyprus.PhoneNumber.CountryCode.String,
+				CreatedBefore:    null.TimeFrom(time.Now().Add(time.Hour)),
+			}
+
+			foundCompanyList, err = companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(foundCompanyList.Companies), ShouldEqual, 1)
+			So(foundCompanyList.Companies[0].ID, ShouldEqual, appleCyprus.ID)
+			So(foundCompanyList.Pagination.Count, ShouldEqual, 1)
+		})
+
+		Convey("can page through companies with a cursor on a custom sort", func() {
+
+			appleCyprus, _, err := repos.CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			googleCyprus, _, err := repos.CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.Filter{
+				Cursor: true,
+				Per:    1,
+				Sort:   []forms.SortField{{Field: "created_at", Descending: true}},
+			}
+
+			firstPage, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+			So(firstPage.Companies[0].ID, ShouldEqual, googleCyprus.ID)
+
+			filter.After, err = entities.DecodeCompanyCursor(firstPage.Pagination.NextCursor.String)
+			So(err, ShouldBeNil)
+
+			secondPage, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+			So(len(secondPage.Companies), ShouldEqual, 1)
+			So(secondPage.Companies[0].ID, ShouldEqual, appleCyprus.ID)
+		})
+
+		Convey("can list companies without counting", func() {
+
+			_, _, err := repos.CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			_, _, err = repos.CreateCompany(ctx, dB, "Google", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.Filter{
+				Page:      1,
+				Per:       1,
+				SkipCount: true,
+			}
+
+			foundCompanyList, err := companyService.ListCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(foundCompanyList.Companies), ShouldEqual, 1)
+			So(foundCompanyList.Pagination.NextPage.Int64, ShouldEqual, 2)
+		})
+
+		Convey("can search companies tolerating typos", func() {
+
+			appleCyprus, _, err := repos.CreateCompany(ctx, dB, "Apple", country)
+			So(err, ShouldBeNil)
+
+			_, _, err = repos.CreateCompany(ctx, dB, "Microsoft", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.SearchFilter{
+				Page:  1,
+				Per:   10,
+				Query: "Aple",
+			}
+
+			resultList, err := companyService.SearchCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(resultList.Results), ShouldEqual, 1)
+			So(resultList.Results[0].Company.ID, ShouldEqual, appleCyprus.ID)
+			So(resultList.Results[0].Score, ShouldBeGreaterThan, 0)
+		})
+
+		Convey("can highlight matched fields in search results", func() {
+
+			appleCyprus, _, err := repos.CreateCompany(ctx, dB, "Apple Cyprus", country)
+			So(err, ShouldBeNil)
+
+			filter := &forms.SearchFilter{
+				Page:  1,
+				Per:   10,
+				Query: "apple",
+			}
+
+			resultList, err := companyService.SearchCompanies(ctx, dB, filter)
+			So(err, ShouldBeNil)
+
+			So(len(resultList.Results), ShouldEqual, 1)
+			So(resultList.Results[0].Company.ID, ShouldEqual, appleCyprus.ID)
+			So(resultList.Results[0].Highlights["name"], ShouldEqual, "<mark>Apple</mark> Cyprus")
+		})
+
+		Convey("can backfill website domains the same way new websites are checked", func() {
+
+			createLegacyCompany := func(name, website string) *entities.Company {
+
+				company := entities.BuildCompany(name, country)
+				company.Website = website
+				company.WebsiteDomain = ""
+
+				err := companyRepository.Save(ctx, dB, company)
+				So(err, ShouldBeNil)
+
+				_, err = repos.CreateCompanyCountry(ctx, dB, company.ID, country.ID)
+				So(err, ShouldBeNil)
+
+				return company
+			}
+
+			idnCompany := createLegacyCompany("Bücher", "https://www.Bücher.example:443/shop")
+			portCompany := createLegacyCompany("Example", "http://Example.org:80")
+			conflictingCompany := createLegacyCompany("Example Twin", "www.example.org")
+
+			backfilled, conflicts, err := companyService.BackfillWebsiteDomains(ctx, dB)
+			So(err, ShouldBeNil)
+			So(backfilled, ShouldEqual, 2)
+			So(conflicts, ShouldEqual, 1)
+
+			for _, company := range []*entities.Company{idnCompany, portCompany} {
+
+				website, err := utils.NormalizeWebsite(company.Website)
+				So(err, ShouldBeNil)
+
+				foundCompany, err := companyRepository.CompanyByWebsite(ctx, dB, website.Domain)
+				So(err, ShouldBeNil)
+				So(foundCompany.ID, ShouldEqual, company.ID)
+			}
+
+			foundCompany, err := companyRepository.CompanyByWebsite(ctx, dB, "xn--bcher-kva.example")
+			So(err, ShouldBeNil)
+			So(foundCompany.ID, ShouldEqual, idnCompany.ID)
+
+			foundCompany, err = companyRepository.CompanyByID(ctx, dB, conflictingCompany.ID)
+			So(err, ShouldBeNil)
+			So(foundCompany.WebsiteDomain, ShouldEqual, "")
+
+			backfilled, conflicts, err = companyService.BackfillWebsiteDomains(ctx, dB)
+			So(err, ShouldBeNil)
+			So(backfilled, ShouldEqual, 0)
+			So(conflicts, ShouldEqual, 0)
+		})
+
 		Convey("can delete a company", func() {
 
 			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
 			So(err, ShouldBeNil)
 
-			_, err = companyService.DeleteCompany(ctx, dB, company.ID)
+			_, err = companyService.DeleteCompany(ctx, dB, company.ID, null.Int{})
 			So(err, ShouldBeNil)
 
 			_, err = companyRepository.CompanyByID(ctx, dB, company.ID)
 			So(err, ShouldNotBeNil)
 			So(utils.IsErrNoRows(err), ShouldBeTrue)
 		})
+
+		Convey("can restore a deleted company", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			_, err = companyService.DeleteCompany(ctx, dB, company.ID, null.Int{})
+			So(err, ShouldBeNil)
+
+			restoredCompany, err := companyService.RestoreCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(restoredCompany.ID, ShouldEqual, company.ID)
+			So(restoredCompany.DeletedAt.Valid, ShouldBeFalse)
+
+			_, err = companyService.GetCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("cannot restore a company whose name has been taken", func() {
+
+			company, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			_, err = companyService.DeleteCompany(ctx, dB, company.ID, null.Int{})
+			So(err, ShouldBeNil)
+
+			_, _, err = repos.CreateCompany(ctx, dB, "KFC", country)
+			So(err, ShouldBeNil)
+
+			_, err = companyService.RestoreCompany(ctx, dB, company.ID)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
 	}))
 }
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	maxCustomFieldEnumValues  = 100
+	maxCustomFieldLabelLength = 100
+	maxCustomFieldPattern     = 255
+)
+
+type (
+	CustomFieldService interface {
+		CreateCustomFieldDefinition(ctx context.Context, dB db.DB, form *forms.CreateCustomFieldDefinitionForm) (*entities.CustomFieldDefinition, error)
+		CustomFieldSchema(ctx context.Context, dB db.DB) (map[string]interface{}, error)
+		DeleteCustomFieldDefinition(ctx context.Context, dB db.DB, definitionID int64) (*entities.CustomFieldDefinition, error)
+		ListCustomFieldDefinitions(ctx context.Context, dB db.DB) ([]*entities.CustomFieldDefinition, error)
+		UpdateCustomFieldDefinition(ctx context.Context, dB db.DB, definitionID int64, form *forms.UpdateCustomFieldDefinitionForm) (*entities.CustomFieldDefinition, error)
+	}
+
+	AppCustomFieldService struct {
+		companyRepository               repos.CompanyRepository
+		customFieldDefinitionRepository repos.CustomFieldDefinitionRepository
+	}
+)
+
+func NewCustomFieldService(
+	companyRepository repos.CompanyRepository,
+	customFieldDefinitionRepository repos.CustomFieldDefinitionRepository,
+) *AppCustomFieldService {
+	return &AppCustomFieldService{
+		companyRepository:               companyRepository,
+		customFieldDefinitionRepository: customFieldDefinitionRepository,
+	}
+}
+
+func NewTestCustomFieldService() *AppCustomFieldService {
+	return NewCustomFieldService(
+		repos.NewCompanyRepository(),
+		repos.NewCustomFieldDefinitionRepository(),
+	)
+}
+
+func (s *AppCustomFieldService) CreateCustomFieldDefinition(
+	ctx context.Context,
+	dB db.DB,
+	form *forms.CreateCustomFieldDefinitionForm,
+) (*entities.CustomFieldDefinition, error) {
+
+	key := strings.TrimSpace(form.Key)
+	if !utils.IsValidCustomFieldKey(key) {
+		return &entities.CustomFieldDefinition{}, utils.NewErrorWithCode(
+			errors.New("invalid custom field key"),
+			utils.ErrorCodeInvalidForm,
+			"custom field key = [%v] must be lower case letters, digits and underscores starting with a letter",
+			form.Key,
+		)
+	}
+
+	if !form.FieldType.IsValid() {
+		return &entities.CustomFieldDefinition{}, utils.NewErrorWithCode(
+			errors.New("invalid custom field type"),
+			utils.ErrorCodeInvalidForm,
+			"invalid custom field type = [%v]",
+			form.FieldType,
+		)
+	}
+
+	_, err := s.customFieldDefinitionRepository.CustomFieldDefinitionByKey(ctx, dB, key)
+	if err == nil {
+		return &entities.CustomFieldDefinition{}, utils.NewErrorWithCode(
+			errors.New("custom field already exists"),
+			utils.ErrorCodeResourceExists,
+			"duplicate custom field key = %v",
+			key,
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	definition := &entities.CustomFieldDefinition{
+		Key:        key,
+		Label:      strings.TrimSpace(form.Label),
+		FieldType:  form.FieldType,
+		Required:   form.Required,
+		EnumValues: form.EnumValues,
+		Pattern:    form.Pattern,
+	}
+
+	if definition.Label == "" {
+		definition.Label = key
+	}
+
+	err = s.validateDefinition(definition)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	err = s.customFieldDefinitionRepository.Save(ctx, dB, definition)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+// CustomFieldSchema describes the custom fields companies accept as a JSON
+// Schema object, for clients to validate against before submitting.
+func (s *AppCustomFieldService) CustomFieldSchema(
+	ctx context.Context,
+	dB db.DB,
+) (map[string]interface{}, error) {
+
+	definitions, err := s.customFieldDefinitionRepository.ListCustomFieldDefinitions(ctx, dB)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+
+	properties := make(map[string]interface{}, len(definitions))
+	required := make([]string, 0)
+
+	for _, definition := range definitions {
+
+		properties[definition.Key] = definition.JSONSchema()
+
+		if definition.Required {
+			required = append(required, definition.Key)
+		}
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// DeleteCustomFieldDefinition removes the definition along with the values
+// companies hold for it.
+func (s *AppCustomFieldService) DeleteCustomFieldDefinition(
+	ctx context.Context,
+	dB db.DB,
+	definitionID int64,
+) (*entities.CustomFieldDefinition, error) {
+
+	definition, err := s.getDefinition(ctx, dB, definitionID)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.customFieldDefinitionRepository.DeleteCustomFieldDefinition(ctx, operations, definition.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.companyRepository.RemoveCompanyCustomField(ctx, operations, definition.Key)
+	})
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+func (s *AppCustomFieldService) ListCustomFieldDefinitions(
+	ctx context.Context,
+	dB db.DB,
+) ([]*entities.CustomFieldDefinition, error) {
+
+	return s.customFieldDefinitionRepository.ListCustomFieldDefinitions(ctx, dB)
+}
+
+// UpdateCustomFieldDefinition only checks values written after the change,
+// companies already holding values keep them until they are next updated.
+func (s *AppCustomFieldService) UpdateCustomFieldDefinition(
+	ctx context.Context,
+	dB db.DB,
+	definitionID int64,
+	form *forms.UpdateCustomFieldDefinitionForm,
+) (*entities.CustomFieldDefinition, error) {
+
+	definition, err := s.getDefinition(ctx, dB, definitionID)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	if form.Label.Valid {
+		definition.Label = strings.TrimSpace(form.Label.String)
+		if definition.Label == "" {
+			definition.Label = definition.Key
+		}
+	}
+
+	if form.Required.Valid {
+		definition.Required = form.Required.Bool
+	}
+
+	if form.EnumValues != nil {
+		definition.EnumValues = form.EnumValues
+	}
+
+	if form.Pattern.Valid {
+		definition.Pattern = form.Pattern.String
+	}
+
+	err = s.validateDefinition(definition)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	err = s.customFieldDefinitionRepository.Save(ctx, dB, definition)
+	if err != nil {
+		return &entities.CustomFieldDefinition{}, err
+	}
+
+	return definition, nil
+}
+
+func (s *AppCustomFieldService) getDefinition(
+	ctx context.Context,
+	dB db.DB,
+	definitionID int64,
+) (*entities.CustomFieldDefinition, error) {
+
+	definition, err := s.customFieldDefinitionRepository.CustomFieldDefinitionByID(ctx, dB, definitionID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.CustomFieldDefinition{}, err
+		}
+
+		return &entities.CustomFieldDefinition{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"custom field not found",
+		)
+	}
+
+	return definition, nil
+}
+
+func (s *AppCustomFieldService) validateDefinition(
+	definition *entities.CustomFieldDefinition,
+) error {
+
+	if utf8.RuneCountInString(definition.Label) > maxCustomFieldLabelLength {
+		return utils.NewErrorWithCode(
+			errors.New("custom field label too long"),
+			utils.ErrorCodeInvalidForm,
+			"custom field label must be at most %v characters",
+			maxCustomFieldLabelLength,
+		)
+	}
+
+	if definition.FieldType != entities.CustomFieldTypeEnum && len(definition.EnumValues) > 0 {
+		return utils.NewErrorWithCode(
+			errors.New("enum values on non enum field"),
+			utils.ErrorCodeInvalidForm,
+			"custom field = %v of type [%v] cannot have enum values",
+			definition.Key,
+			definition.FieldType,
+		)
+	}
+
+	if definition.FieldType == entities.CustomFieldTypeEnum {
+
+		enumValues := make([]string, 0, len(definition.EnumValues))
+		seen := make(map[string]bool)
+
+		for _, enumValue := range definition.EnumValues {
+
+			enumValue = strings.TrimSpace(enumValue)
+			if enumValue == "" || seen[enumValue] {
+				continue
+			}
+
+			seen[enumValue] = true
+			enumValues = append(enumValues, enumValue)
+		}
+
+		if len(enumValues) == 0 || len(enumValues) > maxCustomFieldEnumValues {
+			return utils.NewErrorWithCode(
+				errors.New("invalid enum values"),
+				utils.ErrorCodeInvalidForm,
+				"custom field = %v must have 1 to %v enum values",
+				definition.Key,
+				maxCustomFieldEnumValues,
+			)
+		}
+
+		definition.EnumValues = enumValues
+	}
+
+	if definition.Pattern == "" {
+		return nil
+	}
+
+	if definition.FieldType != entities.CustomFieldTypeString {
+		return utils.NewErrorWithCode(
+			errors.New("pattern on non string field"),
+			utils.ErrorCodeInvalidForm,
+			"custom field = %v of type [%v] cannot have a pattern",
+			definition.Key,
+			definition.FieldType,
+		)
+	}
+
+	_, err := regexp.Compile(definition.Pattern)
+	if err != nil || len(definition.Pattern) > maxCustomFieldPattern {
+		return utils.NewErrorWithCode(
+			errors.New("invalid pattern"),
+			utils.ErrorCodeInvalidForm,
+			"custom field = %v has an invalid pattern = [%v]",
+			definition.Key,
+			definition.Pattern,
+		)
+	}
+
+	return nil
+}
+
+// applyCustomFields merges changes into a copy of customFields, validating
+// each value against its definition. A null value removes the field. Every
+// required field must be set once the changes are applied.
+func applyCustomFields(
+	ctx context.Context,
+	dB db.DB,
+	customFieldDefinitionRepository repos.CustomFieldDefinitionRepository,
+	customFields map[string]interface{},
+	changes map[string]interface{},
+) (map[string]interface{}, error) {
+
+	definitions, err := customFieldDefinitionRepository.ListCustomFieldDefinitions(ctx, dB)
+	if err != nil {
+		return map[string]interface{}{}, err
+	}
+
+	definitionsByKey := make(map[string]*entities.CustomFieldDefinition, len(definitions))
+	for _, definition := range definitions {
+		definitionsByKey[definition.Key] = definition
+	}
+
+	merged := make(map[string]interface{}, len(customFields)+len(changes))
+	for key, value := range customFields {
+		merged[key] = value
+	}
+
+	for key, value := range changes {
+
+		definition, ok := definitionsByKey[key]
+		if !ok {
+			return map[string]interface{}{}, utils.NewErrorWithCode(
+				errors.New("unknown custom field"),
+				utils.ErrorCodeInvalidCustomField,
+				"custom field = %v is not defined",
+				key,
+			)
+		}
+
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		normalized, err := utils.NormalizeCustomFieldValue(definition, value)
+		if err != nil {
+			return map[string]interface{}{}, err
+		}
+
+		merged[key] = normalized
+	}
+
+	for _, definition := range definitions {
+
+		value, ok := merged[definition.Key]
+		if definition.Required && (!ok || value == "") {
+			return map[string]interface{}{}, utils.NewErrorWithCode(
+				errors.New("missing custom field"),
+				utils.ErrorCodeInvalidCustomField,
+				"custom field = %v is required",
+				definition.Key,
+			)
+		}
+	}
+
+	return merged, nil
+}
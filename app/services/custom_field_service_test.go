@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCustomFieldService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyService := NewTestCompanyService()
+	customFieldService := NewTestCustomFieldService()
+
+	Convey("Custom Field Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		tier, err := customFieldService.CreateCustomFieldDefinition(ctx, dB, &forms.CreateCustomFieldDefinitionForm{
+			Key:        "account_tier",
+			FieldType:  entities.CustomFieldTypeEnum,
+			EnumValues: []string{"gold", " silver ", "gold"},
+		})
+		So(err, ShouldBeNil)
+		So(tier.Label, ShouldEqual, "account_tier")
+		So(tier.EnumValues, ShouldResemble, []string{"gold", "silver"})
+
+		_, err = customFieldService.CreateCustomFieldDefinition(ctx, dB, &forms.CreateCustomFieldDefinitionForm{
+			Key:       "license_number",
+			Label:     "License number",
+			FieldType: entities.CustomFieldTypeString,
+			Pattern:   `^L-\d{4}$`,
+		})
+		So(err, ShouldBeNil)
+
+		form := &forms.CreateCompanyForm{
+			Name:         "Microsoft",
+			Code:         "MSFT",
+			Country:      "cyprus",
+			Website:      "https://microsoft.com",
+			Phone:        "+35790034567",
+			CustomFields: map[string]interface{}{"account_tier": "gold", "license_number": "L-1234"},
+		}
+
+		Convey("cannot create a definition with an invalid key", func() {
+
+			_, err := customFieldService.CreateCustomFieldDefinition(ctx, dB, &forms.CreateCustomFieldDefinitionForm{
+				Key:       "Account Tier",
+				FieldType: entities.CustomFieldTypeString,
+			})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("cannot create a definition with a duplicate key", func() {
+
+			_, err := customFieldService.CreateCustomFieldDefinition(ctx, dB, &forms.CreateCustomFieldDefinitionForm{
+				Key:       "account_tier",
+				FieldType: entities.CustomFieldTypeString,
+			})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("can create a company with valid custom fields", func() {
+
+			company, err := companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldBeNil)
+
+			company, err = companyService.GetCompany(ctx, dB, company.ID)
+			So(err, ShouldBeNil)
+			So(company.CustomFields["account_tier"], ShouldEqual, "gold")
+			So(company.CustomFields["license_number"], ShouldEqual, "L-1234")
+
+			Convey("updates merge into the existing custom fields", func() {
+
+				company, err := companyService.UpdateCompany(ctx, dB, company.ID, &forms.UpdateCompanyForm{
+					CustomFields: map[string]interface{}{"account_tier": "silver", "license_number": nil},
+				})
+				So(err, ShouldBeNil)
+				So(company.CustomFields, ShouldResemble, map[string]interface{}{"account_tier": "silver"})
+			})
+
+			Convey("companies can be filtered by custom fields", func() {
+
+				_, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+				So(err, ShouldBeNil)
+
+				companyList, err := companyService.ListCompanies(ctx, dB, &forms.Filter{
+					Page:         1,
+					Per:          10,
+					CustomFields: map[string]string{"account_tier": "gold"},
+				})
+				So(err, ShouldBeNil)
+				So(len(companyList.Companies), ShouldEqual, 1)
+				So(companyList.Companies[0].ID, ShouldEqual, company.ID)
+			})
+
+			Convey("deleting a definition removes its values", func() {
+
+				_, err := customFieldService.DeleteCustomFieldDefinition(ctx, dB, tier.ID)
+				So(err, ShouldBeNil)
+
+				company, err := companyService.GetCompany(ctx, dB, company.ID)
+				So(err, ShouldBeNil)
+				So(company.CustomFields, ShouldResemble, map[string]interface{}{"license_number": "L-1234"})
+			})
+		})
+
+		Convey("cannot create a company with invalid custom fields", func() {
+
+			customFields := []map[string]interface{}{
+				{"account_tier": "bronze"},
+				{"license_number": "1234"},
+				{"onboarded_on": "2026-10-18"},
+			}
+
+			for _, fields := range customFields {
+
+				form.CustomFields = fields
+
+				_, err := companyService.CreateCompany(ctx, dB, form)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidCustomField)
+			}
+		})
+
+		Convey("required custom fields must be set", func() {
+
+			_, err := customFieldService.UpdateCustomFieldDefinition(ctx, dB, tier.ID, &forms.UpdateCustomFieldDefinitionForm{
+				Required: null.BoolFrom(true),
+			})
+			So(err, ShouldBeNil)
+
+			form.CustomFields = nil
+
+			_, err = companyService.CreateCompany(ctx, dB, form)
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidCustomField)
+		})
+	}))
+}
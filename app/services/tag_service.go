@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const maxTagNameLength = 50
+
+type (
+	TagService interface {
+		AttachCompanyTag(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyTagForm) ([]*entities.Tag, error)
+		CreateTag(ctx context.Context, dB db.DB, form *forms.CreateTagForm) (*entities.Tag, error)
+		DeleteTag(ctx context.Context, dB db.DB, tagID int64) (*entities.Tag, error)
+		DetachCompanyTag(ctx context.Context, dB db.DB, companyID, tagID int64) ([]*entities.Tag, error)
+		ListCompanyTags(ctx context.Context, dB db.DB, companyID int64) ([]*entities.Tag, error)
+		ListTags(ctx context.Context, dB db.DB) ([]*entities.Tag, error)
+	}
+
+	AppTagService struct {
+		companyRepository    repos.CompanyRepository
+		companyTagRepository repos.CompanyTagRepository
+		tagRepository        repos.TagRepository
+	}
+)
+
+func NewTagService(
+	companyRepository repos.CompanyRepository,
+	companyTagRepository repos.CompanyTagRepository,
+	tagRepository repos.TagRepository,
+) *AppTagService {
+	return &AppTagService{
+		companyRepository:    companyRepository,
+		companyTagRepository: companyTagRepository,
+		tagRepository:        tagRepository,
+	}
+}
+
+func NewTestTagService() *AppTagService {
+	return NewTagService(
+		repos.NewCompanyRepository(),
+		repos.NewCompanyTagRepository(),
+		repos.NewTagRepository(),
+	)
+}
+
+func (s *AppTagService) AttachCompanyTag(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+	form *forms.CompanyTagForm,
+) ([]*entities.Tag, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	tag, err := s.getTag(ctx, dB, form.TagID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	err = s.companyTagRepository.AttachCompanyTag(ctx, dB, company.ID, tag.ID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	return s.companyTags(ctx, dB, company.ID)
+}
+
+func (s *AppTagService) CreateTag(
+	ctx context.Context,
+	dB db.DB,
+	form *forms.CreateTagForm,
+) (*entities.Tag, error) {
+
+	name := strings.Join(strings.Fields(form.Name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength || strings.Contains(name, ",") {
+		return &entities.Tag{}, utils.NewErrorWithCode(
+			errors.New("invalid tag name"),
+			utils.ErrorCodeInvalidForm,
+			"tag name must be 1 to %v characters without commas",
+			maxTagNameLength,
+		)
+	}
+
+	_, err := s.tagRepository.TagByName(ctx, dB, name)
+	if err == nil {
+		return &entities.Tag{}, utils.NewErrorWithCode(
+			errors.New("tag already exists"),
+			utils.ErrorCodeResourceExists,
+			"duplicate tag name = %v",
+			name,
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.Tag{}, err
+	}
+
+	tag := &entities.Tag{
+		Name: name,
+	}
+
+	err = s.tagRepository.Save(ctx, dB, tag)
+	if err != nil {
+		return &entities.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// DeleteTag removes the tag from every company carrying it.
+func (s *AppTagService) DeleteTag(
+	ctx context.Context,
+	dB db.DB,
+	tagID int64,
+) (*entities.Tag, error) {
+
+	tag, err := s.getTag(ctx, dB, tagID)
+	if err != nil {
+		return &entities.Tag{}, err
+	}
+
+	err = s.tagRepository.DeleteTag(ctx, dB, tag.ID)
+	if err != nil {
+		return &entities.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (s *AppTagService) DetachCompanyTag(
+	ctx context.Context,
+	dB db.DB,
+	companyID,
+	tagID int64,
+) ([]*entities.Tag, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	detached, err := s.companyTagRepository.DetachCompanyTag(ctx, dB, company.ID, tagID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	if !detached {
+		return []*entities.Tag{}, utils.NewErrorWithCode(
+			errors.New("company tag not found"),
+			utils.ErrorCodeNotFound,
+			"company = %v is not tagged with tag = %v",
+			company.ID,
+			tagID,
+		)
+	}
+
+	return s.companyTags(ctx, dB, company.ID)
+}
+
+func (s *AppTagService) ListCompanyTags(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.Tag, error) {
+
+	company, err := s.getCompany(ctx, dB, companyID)
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	return s.companyTags(ctx, dB, company.ID)
+}
+
+func (s *AppTagService) ListTags(
+	ctx context.Context,
+	dB db.DB,
+) ([]*entities.Tag, error) {
+	return s.tagRepository.ListTags(ctx, dB)
+}
+
+func (s *AppTagService) companyTags(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) ([]*entities.Tag, error) {
+
+	companyTags, err := s.companyTagRepository.CompanyTagsByCompanyIDs(ctx, dB, []int64{companyID})
+	if err != nil {
+		return []*entities.Tag{}, err
+	}
+
+	tags := companyTags[companyID]
+	if tags == nil {
+		tags = []*entities.Tag{}
+	}
+
+	return tags, nil
+}
+
+func (s *AppTagService) getCompany(
+	ctx context.Context,
+	dB db.DB,
+	companyID int64,
+) (*entities.Company, error) {
+
+	company, err := s.companyRepository.CompanyByID(ctx, dB, companyID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Company{}, err
+		}
+
+		return &entities.Company{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"company not found",
+		)
+	}
+
+	return company, nil
+}
+
+func (s *AppTagService) getTag(
+	ctx context.Context,
+	dB db.DB,
+	tagID int64,
+) (*entities.Tag, error) {
+
+	tag, err := s.tagRepository.TagByID(ctx, dB, tagID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.Tag{}, err
+		}
+
+		return &entities.Tag{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"tag not found",
+		)
+	}
+
+	return tag, nil
+}
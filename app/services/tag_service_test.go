@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTagService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	companyService := NewTestCompanyService()
+	tagService := NewTestTagService()
+
+	Convey("Tag Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		country, err := repos.CreateCountry(ctx, dB)
+		So(err, ShouldBeNil)
+
+		kfc, _, err := repos.CreateCompany(ctx, dB, "KFC", country)
+		So(err, ShouldBeNil)
+
+		mcdonalds, _, err := repos.CreateCompany(ctx, dB, "McDonalds", country)
+		So(err, ShouldBeNil)
+
+		Convey("can create a tag", func() {
+
+			tag, err := tagService.CreateTag(ctx, dB, &forms.CreateTagForm{Name: "  Risk   Tier 1 "})
+			So(err, ShouldBeNil)
+			So(tag.ID, ShouldNotBeZeroValue)
+			So(tag.Name, ShouldEqual, "Risk Tier 1")
+
+			Convey("but not another with the same name", func() {
+
+				_, err := tagService.CreateTag(ctx, dB, &forms.CreateTagForm{Name: "risk tier 1"})
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+			})
+		})
+
+		Convey("cannot create a tag with a comma", func() {
+
+			_, err := tagService.CreateTag(ctx, dB, &forms.CreateTagForm{Name: "food, retail"})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("can attach and detach tags", func() {
+
+			food, err := tagService.CreateTag(ctx, dB, &forms.CreateTagForm{Name: "Food"})
+			So(err, ShouldBeNil)
+
+			retail, err := tagService.CreateTag(ctx, dB, &forms.CreateTagForm{Name: "Retail"})
+			So(err, ShouldBeNil)
+
+			tags, err := tagService.AttachCompanyTag(ctx, dB, kfc.ID, &forms.CompanyTagForm{TagID: food.ID})
+			So(err, ShouldBeNil)
+			So(len(tags), ShouldEqual, 1)
+
+			tags, err = tagService.AttachCompanyTag(ctx, dB, kfc.ID, &forms.CompanyTagForm{TagID: retail.ID})
+			So(err, ShouldBeNil)
+			So(len(tags), ShouldEqual, 2)
+
+			Convey("attaching a tag twice is a no-op", func() {
+
+				tags, err := tagService.AttachCompanyTag(ctx, dB, kfc.ID, &forms.CompanyTagForm{TagID: food.ID})
+				So(err, ShouldBeNil)
+				So(len(tags), ShouldEqual, 2)
+			})
+
+			Convey("tags are returned with the company", func() {
+
+				company, err := companyService.GetCompany(ctx, dB, kfc.ID)
+				So(err, ShouldBeNil)
+				So(len(company.Tags), ShouldEqual, 2)
+				So(company.Tags[0].Name, ShouldEqual, "Food")
+			})
+
+			Convey("detaching removes the tag", func() {
+
+				tags, err := tagService.DetachCompanyTag(ctx, dB, kfc.ID, food.ID)
+				So(err, ShouldBeNil)
+				So(len(tags), ShouldEqual, 1)
+				So(tags[0].ID, ShouldEqual, retail.ID)
+
+				_, err = tagService.DetachCompanyTag(ctx, dB, kfc.ID, food.ID)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+			})
+
+			Convey("tags list their usage counts", func() {
+
+				_, err := tagService.AttachCompanyTag(ctx, dB, mcdonalds.ID, &forms.CompanyTagForm{TagID: food.ID})
+				So(err, ShouldBeNil)
+
+				tags, err := tagService.ListTags(ctx, dB)
+				So(err, ShouldBeNil)
+				So(len(tags), ShouldEqual, 2)
+				So(tags[0].ID, ShouldEqual, food.ID)
+				So(tags[0].UsageCount, ShouldEqual, 2)
+				So(tags[1].ID, ShouldEqual, retail.ID)
+				So(tags[1].UsageCount, ShouldEqual, 1)
+			})
+
+			Convey("companies can be filtered by tags", func() {
+
+				_, err := tagService.AttachCompanyTag(ctx, dB, mcdonalds.ID, &forms.CompanyTagForm{TagID: food.ID})
+				So(err, ShouldBeNil)
+
+				filter := &forms.Filter{
+					Page:         1,
+					Per:          10,
+					Tags:         []string{"food", "retail"},
+					MatchAllTags: true,
+				}
+
+				companyList, err := companyService.ListCompanies(ctx, dB, filter)
+				So(err, ShouldBeNil)
+				So(len(companyList.Companies), ShouldEqual, 1)
+				So(companyList.Companies[0].ID, ShouldEqual, kfc.ID)
+				So(companyList.Pagination.Count, ShouldEqual, 1)
+
+				filter.MatchAllTags = false
+
+				companyList, err = companyService.ListCompanies(ctx, dB, filter)
+				So(err, ShouldBeNil)
+				So(len(companyList.Companies), ShouldEqual, 2)
+				So(companyList.Pagination.Count, ShouldEqual, 2)
+			})
+
+			Convey("deleting a tag removes it from companies", func() {
+
+				_, err := tagService.DeleteTag(ctx, dB, food.ID)
+				So(err, ShouldBeNil)
+
+				tags, err := tagService.ListCompanyTags(ctx, dB, kfc.ID)
+				So(err, ShouldBeNil)
+				So(len(tags), ShouldEqual, 1)
+				So(tags[0].ID, ShouldEqual, retail.ID)
+			})
+		})
+
+		Convey("cannot attach a tag that does not exist", func() {
+
+			_, err := tagService.AttachCompanyTag(ctx, dB, kfc.ID, &forms.CompanyTagForm{TagID: 1000})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeNotFound)
+		})
+	}))
+}
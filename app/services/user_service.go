@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+)
+
+const (
+	maxUserNameLength = 20
+	// maxPasswordLength is the number of bytes bcrypt hashes; anything
+	// beyond it would be silently ignored.
+	maxPasswordLength = 72
+	minPasswordLength = 8
+)
+
+var usernameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,19}$`)
+
+type (
+	UserService interface {
+		AssignRole(ctx context.Context, dB db.DB, userID int64, form *forms.AssignRoleForm) (*entities.User, error)
+		CreateUser(ctx context.Context, dB db.DB, form *forms.CreateUserForm) (*entities.User, error)
+		DeactivateUser(ctx context.Context, dB db.DB, userID int64) (*entities.User, error)
+		GetUser(ctx context.Context, dB db.DB, userID int64) (*entities.User, error)
+		ListUsers(ctx context.Context, dB db.DB, filter *forms.UserFilter) (*entities.UserList, error)
+		UpdateUser(ctx context.Context, dB db.DB, userID int64, form *forms.UpdateUserForm) (*entities.User, error)
+	}
+
+	AppUserService struct {
+		sessionRepository repos.SessionRepository
+		userRepository    repos.UserRepository
+	}
+)
+
+func NewUserService(
+	sessionRepository repos.SessionRepository,
+	userRepository repos.UserRepository,
+) *AppUserService {
+	return &AppUserService{
+		sessionRepository: sessionRepository,
+		userRepository:    userRepository,
+	}
+}
+
+func NewTestUserService() *AppUserService {
+	return NewUserService(
+		repos.NewSessionRepository(),
+		repos.NewUserRepository(),
+	)
+}
+
+// AssignRole changes the role of a user. Roles are carried in session
+// tokens, so the user's sessions are ended for the new role to take effect.
+func (s *AppUserService) AssignRole(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+	form *forms.AssignRoleForm,
+) (*entities.User, error) {
+
+	role, err := s.parseRole(form.Role)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	user, err := s.getUser(ctx, dB, userID)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	if user.ID == ctxhelper.UserID(ctx) {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("cannot change own role"),
+			utils.ErrorCodeRoleForbidden,
+			"user = %v cannot change their own role",
+			user.ID,
+		)
+	}
+
+	user.Role = role
+
+	err = dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.userRepository.Save(ctx, operations, user)
+		if err != nil {
+			return err
+		}
+
+		return s.sessionRepository.DeactivateUserSessions(ctx, operations, user.ID)
+	})
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	return user, nil
+}
+
+// CreateUser registers an unverified user, who cannot log in until
+// activated. Users are viewers unless created with another role.
+func (s *AppUserService) CreateUser(
+	ctx context.Context,
+	dB db.DB,
+	form *forms.CreateUserForm,
+) (*entities.User, error) {
+
+	username := strings.ToLower(strings.TrimSpace(form.Username))
+	if !usernameRe.MatchString(username) {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("invalid username"),
+			utils.ErrorCodeInvalidForm,
+			"username = [%v] must be 2 to %v lower case letters, digits, dots, dashes or underscores",
+			form.Username,
+			maxUserNameLength,
+		)
+	}
+
+	// Logging in trims passwords, so padded ones could never be used as
+	// typed.
+	password := form.Password
+	if password != strings.TrimSpace(password) {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("invalid password"),
+			utils.ErrorCodeInvalidForm,
+			"password must not start or end with whitespace",
+		)
+	}
+
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("invalid password"),
+			utils.ErrorCodeInvalidForm,
+			"password must be %v to %v characters",
+			minPasswordLength,
+			maxPasswordLength,
+		)
+	}
+
+	role := entities.RoleViewer
+
+	if form.Role != "" {
+		parsedRole, err := s.parseRole(form.Role)
+		if err != nil {
+			return &entities.User{}, err
+		}
+
+		role = parsedRole
+	}
+
+	user := &entities.User{
+		Username: username,
+		Role:     role,
+		Status:   entities.UserStatusUnverified,
+	}
+
+	err := s.setUserName(user, form.FirstName, form.LastName)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	_, err = s.userRepository.UserByUsername(ctx, dB, username)
+	if err == nil {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("user already exists"),
+			utils.ErrorCodeResourceExists,
+			"duplicate username = %v",
+			username,
+		)
+	}
+
+	if !utils.IsErrNoRows(err) {
+		return &entities.User{}, err
+	}
+
+	passwordHash, err := utils.GeneratePasswordHash(password)
+	if err != nil {
+		return &entities.User{}, utils.NewError(
+			err,
+			"failed to hash password for username = %v",
+			username,
+		)
+	}
+
+	user.PasswordHash = string(passwordHash)
+
+	err = s.userRepository.Save(ctx, dB, user)
+	if err != nil {
+		if utils.IsUniqueViolation(err) {
+			return &entities.User{}, utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeResourceExists,
+				"duplicate username = %v",
+				username,
+			)
+		}
+
+		return &entities.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *AppUserService) DeactivateUser(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+) (*entities.User, error) {
+
+	user, err := s.getUser(ctx, dB, userID)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	err = s.transition(ctx, dB, user, entities.UserStatusDeactivated)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *AppUserService) GetUser(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+) (*entities.User, error) {
+
+	return s.getUser(ctx, dB, userID)
+}
+
+func (s *AppUserService) ListUsers(
+	ctx context.Context,
+	dB db.DB,
+	filter *forms.UserFilter,
+) (*entities.UserList, error) {
+
+	users, err := s.userRepository.ListUsers(ctx, dB, filter)
+	if err != nil {
+		return &entities.UserList{}, err
+	}
+
+	count, err := s.userRepository.UserCount(ctx, dB, filter)
+	if err != nil {
+		return &entities.UserList{}, err
+	}
+
+	userList := &entities.UserList{
+		Users:      users,
+		Pagination: entities.NewPagination(count, filter.Page, filter.Per),
+	}
+
+	return userList, nil
+}
+
+func (s *AppUserService) UpdateUser(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+	form *forms.UpdateUserForm,
+) (*entities.User, error) {
+
+	user, err := s.getUser(ctx, dB, userID)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	firstName, lastName := user.FirstName, user.LastName
+
+	if form.FirstName.Valid {
+		firstName = form.FirstName.String
+	}
+
+	if form.LastName.Valid {
+		lastName = form.LastName.String
+	}
+
+	err = s.setUserName(user, firstName, lastName)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	status := entities.UserStatus(strings.TrimSpace(form.Status.String))
+	if !form.Status.Valid || status == user.Status {
+		err = s.userRepository.Save(ctx, dB, user)
+		if err != nil {
+			return &entities.User{}, err
+		}
+
+		return user, nil
+	}
+
+	if !status.IsValid() {
+		return &entities.User{}, utils.NewErrorWithCode(
+			errors.New("invalid user status"),
+			utils.ErrorCodeInvalidForm,
+			"invalid user status = [%v]",
+			form.Status.String,
+		)
+	}
+
+	err = s.transition(ctx, dB, user, status)
+	if err != nil {
+		return &entities.User{}, err
+	}
+
+	return user, nil
+}
+
+// transition saves user in toStatus. Deactivating a user ends all of their
+// sessions in the same transaction so that they are logged out immediately.
+func (s *AppUserService) transition(
+	ctx context.Context,
+	dB db.DB,
+	user *entities.User,
+	toStatus entities.UserStatus,
+) error {
+
+	if !user.Status.CanTransitionTo(toStatus) {
+		return utils.NewErrorWithCode(
+			errors.New("invalid status transition"),
+			utils.ErrorCodeInvalidTransition,
+			"cannot transition user = %v from [%v] to [%v]",
+			user.ID,
+			user.Status,
+			toStatus,
+		)
+	}
+
+	if toStatus == entities.UserStatusDeactivated && user.ID == ctxhelper.UserID(ctx) {
+		return utils.NewErrorWithCode(
+			errors.New("cannot deactivate self"),
+			utils.ErrorCodeInvalidTransition,
+			"user = %v cannot deactivate their own account",
+			user.ID,
+		)
+	}
+
+	user.Status = toStatus
+
+	return dB.InTransaction(ctx, func(ctx context.Context, operations db.SQLOperations) error {
+
+		err := s.userRepository.Save(ctx, operations, user)
+		if err != nil {
+			return err
+		}
+
+		if toStatus != entities.UserStatusDeactivated {
+			return nil
+		}
+
+		return s.sessionRepository.DeactivateUserSessions(ctx, operations, user.ID)
+	})
+}
+
+func (s *AppUserService) getUser(
+	ctx context.Context,
+	dB db.DB,
+	userID int64,
+) (*entities.User, error) {
+
+	user, err := s.userRepository.UserByID(ctx, dB, userID)
+	if err != nil {
+		if !utils.IsErrNoRows(err) {
+			return &entities.User{}, err
+		}
+
+		return &entities.User{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeNotFound,
+			"user not found",
+		)
+	}
+
+	return user, nil
+}
+
+func (s *AppUserService) parseRole(
+	value string,
+) (entities.Role, error) {
+
+	role := entities.Role(strings.TrimSpace(value))
+	if !role.IsValid() {
+		return "", utils.NewErrorWithCode(
+			errors.New("invalid user role"),
+			utils.ErrorCodeInvalidForm,
+			"invalid user role = [%v]",
+			value,
+		)
+	}
+
+	return role, nil
+}
+
+func (s *AppUserService) setUserName(
+	user *entities.User,
+	firstName,
+	lastName string,
+) error {
+
+	firstName = strings.Join(strings.Fields(firstName), " ")
+	lastName = strings.Join(strings.Fields(lastName), " ")
+
+	for _, name := range []string{firstName, lastName} {
+		if name == "" || utf8.RuneCountInString(name) > maxUserNameLength {
+			return utils.NewErrorWithCode(
+				errors.New("invalid user name"),
+				utils.ErrorCodeInvalidForm,
+				"first and last name must be 1 to %v characters",
+				maxUserNameLength,
+			)
+		}
+	}
+
+	user.FirstName = firstName
+	user.LastName = lastName
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUserService(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	sessionRepository := repos.NewSessionRepository()
+	userService := NewTestUserService()
+
+	Convey("User Service", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		user, err := userService.CreateUser(ctx, dB, &forms.CreateUserForm{
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Username:  "Jane.Doe",
+			Password:  "correct horse",
+		})
+		So(err, ShouldBeNil)
+		So(user.Username, ShouldEqual, "jane.doe")
+		So(user.Role, ShouldEqual, entities.RoleViewer)
+		So(user.Status, ShouldEqual, entities.UserStatusUnverified)
+		So(utils.VerifyPassword(user.PasswordHash, "correct horse"), ShouldBeNil)
+
+		Convey("cannot create a user with a duplicate username", func() {
+
+			_, err := userService.CreateUser(ctx, dB, &forms.CreateUserForm{
+				FirstName: "John",
+				LastName:  "Doe",
+				Username:  "jane.doe",
+				Password:  "battery staple",
+			})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeResourceExists)
+		})
+
+		Convey("cannot create a user with a password padded by whitespace", func() {
+
+			_, err := userService.CreateUser(ctx, dB, &forms.CreateUserForm{
+				FirstName: "John",
+				LastName:  "Doe",
+				Username:  "john.doe",
+				Password:  " battery staple ",
+			})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("can log in once activated", func() {
+
+			_, err := userService.UpdateUser(ctx, dB, user.ID, &forms.UpdateUserForm{
+				Status: null.StringFrom(string(entities.UserStatusActive)),
+			})
+			So(err, ShouldBeNil)
+
+			loggedInUser, _, err := NewTestSessionService().Login(ctx, dB, &forms.UserLoginForm{
+				Username: "Jane.Doe",
+				Password: " correct horse ",
+			})
+			So(err, ShouldBeNil)
+			So(loggedInUser.ID, ShouldEqual, user.ID)
+		})
+
+		Convey("can activate an unverified user", func() {
+
+			user, err := userService.UpdateUser(ctx, dB, user.ID, &forms.UpdateUserForm{
+				Status: null.StringFrom(string(entities.UserStatusActive)),
+			})
+			So(err, ShouldBeNil)
+			So(user.Status, ShouldEqual, entities.UserStatusActive)
+		})
+
+		Convey("assigning a role ends the user's sessions", func() {
+
+			session, err := repos.CreateSession(ctx, dB, user.ID)
+			So(err, ShouldBeNil)
+
+			user, err := userService.AssignRole(ctx, dB, user.ID, &forms.AssignRoleForm{
+				Role: string(entities.RoleEditor),
+			})
+			So(err, ShouldBeNil)
+			So(user.Role, ShouldEqual, entities.RoleEditor)
+
+			session, err = sessionRepository.SessionByID(ctx, dB, session.ID)
+			So(err, ShouldBeNil)
+			So(session.DeactivatedAt.Valid, ShouldBeTrue)
+		})
+
+		Convey("cannot assign an unknown role", func() {
+
+			_, err := userService.AssignRole(ctx, dB, user.ID, &forms.AssignRoleForm{
+				Role: "owner",
+			})
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*utils.Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidForm)
+		})
+
+		Convey("deactivating a user ends their sessions", func() {
+
+			session, err := repos.CreateSession(ctx, dB, user.ID)
+			So(err, ShouldBeNil)
+
+			user, err := userService.DeactivateUser(ctx, dB, user.ID)
+			So(err, ShouldBeNil)
+			So(user.Status, ShouldEqual, entities.UserStatusDeactivated)
+
+			session, err = sessionRepository.SessionByID(ctx, dB, session.ID)
+			So(err, ShouldBeNil)
+			So(session.DeactivatedAt.Valid, ShouldBeTrue)
+
+			Convey("a deactivated user cannot be reactivated", func() {
+
+				_, err := userService.UpdateUser(ctx, dB, user.ID, &forms.UpdateUserForm{
+					Status: null.StringFrom(string(entities.UserStatusActive)),
+				})
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*utils.Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, utils.ErrorCodeInvalidTransition)
+			})
+		})
+	}))
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vonmutinda/organono/app/entities"
+)
+
+const (
+	customFieldDateLayout     = "2006-01-02"
+	maxCustomFieldValueLength = 500
+)
+
+var customFieldKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// IsValidCustomFieldKey reports whether key is a lower case snake case name
+// of at most 50 characters.
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyRe.MatchString(key)
+}
+
+// NormalizeCustomFieldValue validates a decoded JSON value against its field
+// definition and returns it in the form it is stored in. Strings are trimmed,
+// numbers become float64 and dates are kept as YYYY-MM-DD.
+func NormalizeCustomFieldValue(
+	definition *entities.CustomFieldDefinition,
+	value interface{},
+) (interface{}, error) {
+
+	switch definition.FieldType {
+	case entities.CustomFieldTypeNumber:
+		return normalizeCustomFieldNumber(definition, value)
+	case entities.CustomFieldTypeBoolean:
+		if boolValue, ok := value.(bool); ok {
+			return boolValue, nil
+		}
+		return nil, invalidCustomFieldError(definition, "must be a boolean")
+	}
+
+	stringValue, ok := value.(string)
+	if !ok {
+		return nil, invalidCustomFieldError(definition, "must be a string")
+	}
+
+	stringValue = strings.TrimSpace(stringValue)
+	if utf8.RuneCountInString(stringValue) > maxCustomFieldValueLength {
+		return nil, invalidCustomFieldError(definition, "must be at most 500 characters")
+	}
+
+	switch definition.FieldType {
+	case entities.CustomFieldTypeDate:
+		date, err := time.Parse(customFieldDateLayout, stringValue)
+		if err != nil {
+			return nil, invalidCustomFieldError(definition, "must be a date formatted as YYYY-MM-DD")
+		}
+		return date.Format(customFieldDateLayout), nil
+
+	case entities.CustomFieldTypeEnum:
+		for _, enumValue := range definition.EnumValues {
+			if stringValue == enumValue {
+				return stringValue, nil
+			}
+		}
+		return nil, invalidCustomFieldError(definition, "must be one of ["+strings.Join(definition.EnumValues, ", ")+"]")
+	}
+
+	if definition.Pattern != "" {
+		matched, err := regexp.MatchString(definition.Pattern, stringValue)
+		if err != nil || !matched {
+			return nil, invalidCustomFieldError(definition, "must match pattern "+definition.Pattern)
+		}
+	}
+
+	return stringValue, nil
+}
+
+func normalizeCustomFieldNumber(
+	definition *entities.CustomFieldDefinition,
+	value interface{},
+) (interface{}, error) {
+
+	var number float64
+
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return nil, invalidCustomFieldError(definition, "must be a number")
+		}
+		number = parsed
+	default:
+		return nil, invalidCustomFieldError(definition, "must be a number")
+	}
+
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return nil, invalidCustomFieldError(definition, "must be a finite number")
+	}
+
+	return number, nil
+}
+
+func invalidCustomFieldError(
+	definition *entities.CustomFieldDefinition,
+	reason string,
+) error {
+
+	return NewErrorWithCode(
+		errors.New("invalid custom field"),
+		ErrorCodeInvalidCustomField,
+		"custom field = %v %v",
+		definition.Key,
+		reason,
+	)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/vonmutinda/organono/app/entities"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalizeCustomFieldValue(t *testing.T) {
+
+	Convey("NormalizeCustomFieldValue", t, func() {
+
+		Convey("accepts and normalizes values of each type", func() {
+
+			values := []struct {
+				definition *entities.CustomFieldDefinition
+				value      interface{}
+				expected   interface{}
+			}{
+				{&entities.CustomFieldDefinition{Key: "license", FieldType: entities.CustomFieldTypeString}, " L-100 ", "L-100"},
+				{&entities.CustomFieldDefinition{Key: "license", FieldType: entities.CustomFieldTypeString, Pattern: `^L-\d+$`}, "L-100", "L-100"},
+				{&entities.CustomFieldDefinition{Key: "employees", FieldType: entities.CustomFieldTypeNumber}, float64(42), float64(42)},
+				{&entities.CustomFieldDefinition{Key: "employees", FieldType: entities.CustomFieldTypeNumber}, 42, float64(42)},
+				{&entities.CustomFieldDefinition{Key: "listed", FieldType: entities.CustomFieldTypeBoolean}, true, true},
+				{&entities.CustomFieldDefinition{Key: "onboarded_on", FieldType: entities.CustomFieldTypeDate}, "2026-10-18", "2026-10-18"},
+				{&entities.CustomFieldDefinition{Key: "tier", FieldType: entities.CustomFieldTypeEnum, EnumValues: []string{"gold", "silver"}}, "gold", "gold"},
+			}
+
+			for _, tc := range values {
+				value, err := NormalizeCustomFieldValue(tc.definition, tc.value)
+				So(err, ShouldBeNil)
+				So(value, ShouldEqual, tc.expected)
+			}
+		})
+
+		Convey("rejects values that do not match the definition", func() {
+
+			values := []struct {
+				definition *entities.CustomFieldDefinition
+				value      interface{}
+			}{
+				{&entities.CustomFieldDefinition{Key: "license", FieldType: entities.CustomFieldTypeString}, 100},
+				{&entities.CustomFieldDefinition{Key: "license", FieldType: entities.CustomFieldTypeString, Pattern: `^L-\d+$`}, "X-100"},
+				{&entities.CustomFieldDefinition{Key: "employees", FieldType: entities.CustomFieldTypeNumber}, "42"},
+				{&entities.CustomFieldDefinition{Key: "listed", FieldType: entities.CustomFieldTypeBoolean}, "true"},
+				{&entities.CustomFieldDefinition{Key: "onboarded_on", FieldType: entities.CustomFieldTypeDate}, "18/10/2026"},
+				{&entities.CustomFieldDefinition{Key: "tier", FieldType: entities.CustomFieldTypeEnum, EnumValues: []string{"gold", "silver"}}, "bronze"},
+			}
+
+			for _, tc := range values {
+				_, err := NormalizeCustomFieldValue(tc.definition, tc.value)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidCustomField)
+			}
+		})
+
+		Convey("validates custom field keys", func() {
+
+			So(IsValidCustomFieldKey("account_tier"), ShouldBeTrue)
+			So(IsValidCustomFieldKey("Account_Tier"), ShouldBeFalse)
+			So(IsValidCustomFieldKey("1tier"), ShouldBeFalse)
+			So(IsValidCustomFieldKey(""), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/vonmutinda/organono/app/entities"
+)
+
+// DiffJSON compares the JSON representations of before and after and returns
+// the top level fields that differ. Either side may be nil.
+func DiffJSON(
+	before,
+	after interface{},
+	ignoredFields ...string,
+) (map[string]*entities.FieldChange, error) {
+
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return map[string]*entities.FieldChange{}, err
+	}
+
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return map[string]*entities.FieldChange{}, err
+	}
+
+	ignored := make(map[string]bool, len(ignoredFields))
+	for _, field := range ignoredFields {
+		ignored[field] = true
+	}
+
+	changes := make(map[string]*entities.FieldChange)
+
+	for field, beforeValue := range beforeFields {
+		if ignored[field] {
+			continue
+		}
+
+		afterValue := afterFields[field]
+		if !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[field] = &entities.FieldChange{From: beforeValue, To: afterValue}
+		}
+	}
+
+	for field, afterValue := range afterFields {
+		if ignored[field] {
+			continue
+		}
+
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = &entities.FieldChange{To: afterValue}
+		}
+	}
+
+	return changes, nil
+}
+
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+
+	fields := make(map[string]interface{})
+
+	rv := reflect.ValueOf(value)
+	if value == nil || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fields, NewError(
+			err,
+			"marshal diff value error",
+		)
+	}
+
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return fields, NewError(
+			err,
+			"unmarshal diff value error",
+		)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/vonmutinda/organono/app/entities"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDiffJSON(t *testing.T) {
+
+	Convey("DiffJSON", t, func() {
+
+		before := &entities.Company{Name: "KFC", Code: "KFC1", Website: "https://kfc.com"}
+		after := &entities.Company{Name: "Burger King", Code: "KFC1", Website: "https://kfc.com"}
+
+		Convey("returns only changed fields", func() {
+
+			changes, err := DiffJSON(before, after)
+			So(err, ShouldBeNil)
+			So(len(changes), ShouldEqual, 1)
+			So(changes["name"].From, ShouldEqual, "KFC")
+			So(changes["name"].To, ShouldEqual, "Burger King")
+		})
+
+		Convey("skips ignored fields", func() {
+
+			changes, err := DiffJSON(before, after, "name")
+			So(err, ShouldBeNil)
+			So(len(changes), ShouldEqual, 0)
+		})
+
+		Convey("treats a nil before as creation", func() {
+
+			var nilCompany *entities.Company
+
+			changes, err := DiffJSON(nilCompany, after, "id", "created_at", "updated_at")
+			So(err, ShouldBeNil)
+			So(changes["name"].From, ShouldBeNil)
+			So(changes["name"].To, ShouldEqual, "Burger King")
+			So(changes["code"].To, ShouldEqual, "KFC1")
+		})
+
+		Convey("treats a nil after as deletion", func() {
+
+			changes, err := DiffJSON(before, nil)
+			So(err, ShouldBeNil)
+			So(changes["code"].From, ShouldEqual, "KFC1")
+			So(changes["code"].To, ShouldBeNil)
+		})
+	})
+}
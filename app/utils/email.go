@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail validates a bare email address, without a display name, and
+// returns it trimmed.
+func NormalizeEmail(email string) (string, error) {
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", NewErrorWithCode(
+			errors.New("email is empty"),
+			ErrorCodeInvalidEmail,
+			"check email is provided",
+		)
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", NewErrorWithCode(
+			err,
+			ErrorCodeInvalidEmail,
+			"parse email = %v",
+			email,
+		)
+	}
+
+	if address.Address != email || !strings.Contains(email[strings.LastIndex(email, "@"):], ".") {
+		return "", NewErrorWithCode(
+			fmt.Errorf("email (%v) is not a bare address", email),
+			ErrorCodeInvalidEmail,
+			"check email format",
+		)
+	}
+
+	return email, nil
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+
+	Convey("NormalizeEmail", t, func() {
+
+		Convey("accepts bare addresses", func() {
+
+			email, err := NormalizeEmail("  jane.doe@example.com ")
+			So(err, ShouldBeNil)
+			So(email, ShouldEqual, "jane.doe@example.com")
+		})
+
+		Convey("rejects invalid addresses", func() {
+
+			emails := []string{
+				"",
+				"jane",
+				"jane@",
+				"jane@localhost",
+				"Jane Doe <jane@example.com>",
+				"jane@@example.com",
+			}
+
+			for _, email := range emails {
+				_, err := NormalizeEmail(email)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidEmail)
+			}
+		})
+	})
+}
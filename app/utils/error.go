@@ -8,41 +8,80 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/lib/pq"
 	"github.com/vonmutinda/organono/app/logger"
 )
 
+// uniqueViolationCode is the Postgres error code for unique_violation.
+const uniqueViolationCode = "23505"
+
 type ErrorCode string
 
 var (
-	ErrorCodeInvalidArgument    ErrorCode = "invalid_argument"
-	ErrorCodeInvalidCredentials ErrorCode = "invalid_credentials"
-	ErrorCodeInvalidForm        ErrorCode = "invalid_form"
-	ErrorCodeInvalidPhone       ErrorCode = "invalid_phone"
-	ErrorCodeInvalidUserStatus  ErrorCode = "invalid_user_status"
-	ErrorCodeNotFound           ErrorCode = "not_found"
-	ErrorCodeResourceExists     ErrorCode = "resource_exists"
-	ErrorCodeRequestFailed      ErrorCode = "request_failed"
-	ErrorCodeRoleForbidden      ErrorCode = "role_forbidden"
-	ErrorCodeSessionExpired     ErrorCode = "session_expired"
+	ErrorCodeAccessDenied         ErrorCode = "access_denied"
+	ErrorCodeDocumentTooLarge     ErrorCode = "document_too_large"
+	ErrorCodeIdempotencyKeyInUse  ErrorCode = "idempotency_key_in_use"
+	ErrorCodeIdempotencyKeyReused ErrorCode = "idempotency_key_reused"
+	ErrorCodeInvalidArgument      ErrorCode = "invalid_argument"
+	ErrorCodeInvalidCredentials   ErrorCode = "invalid_credentials"
+	ErrorCodeInvalidCustomField   ErrorCode = "invalid_custom_field"
+	ErrorCodeInvalidEmail         ErrorCode = "invalid_email"
+	ErrorCodeInvalidForm          ErrorCode = "invalid_form"
+	ErrorCodeInvalidIdentifier    ErrorCode = "invalid_identifier"
+	ErrorCodeInvalidLEI           ErrorCode = "invalid_lei"
+	ErrorCodeInvalidPhone         ErrorCode = "invalid_phone"
+	ErrorCodeInvalidPostalCode    ErrorCode = "invalid_postal_code"
+	ErrorCodeInvalidTransition    ErrorCode = "invalid_transition"
+	ErrorCodeInvalidUserStatus    ErrorCode = "invalid_user_status"
+	ErrorCodeInvalidVATNumber     ErrorCode = "invalid_vat_number"
+	ErrorCodeInvalidWebsite       ErrorCode = "invalid_website"
+	ErrorCodeNotFound             ErrorCode = "not_found"
+	ErrorCodePreconditionFailed   ErrorCode = "precondition_failed"
+	ErrorCodeResourceExists       ErrorCode = "resource_exists"
+	ErrorCodeRequestFailed        ErrorCode = "request_failed"
+	ErrorCodeRoleForbidden        ErrorCode = "role_forbidden"
+	ErrorCodeSessionExpired       ErrorCode = "session_expired"
+	ErrorCodeUnsupportedDocument  ErrorCode = "unsupported_document"
 
 	errorCodeMessageMap = map[ErrorCode]string{
-		ErrorCodeInvalidArgument:    "You have provided an invalid argument",
-		ErrorCodeInvalidCredentials: "You have provided invalid credentials",
-		ErrorCodeInvalidForm:        "You have submitted an invalid form",
-		ErrorCodeInvalidPhone:       "You have provided an invalid phone number",
-		ErrorCodeInvalidUserStatus:  "Your account is not active",
-		ErrorCodeNotFound:           "The requested resource was not found",
-		ErrorCodeResourceExists:     "Another resource with similar attributes already exists",
-		ErrorCodeRequestFailed:      "Request failed to complete. Please try again",
-		ErrorCodeRoleForbidden:      "You are not allowed to perform this request",
-		ErrorCodeSessionExpired:     "Your session has expired. Login again to proceed.",
+		ErrorCodeAccessDenied:         "This request is not allowed from your location",
+		ErrorCodeDocumentTooLarge:     "The document exceeds the maximum allowed size",
+		ErrorCodeIdempotencyKeyInUse:  "A request with this idempotency key is still being processed",
+		ErrorCodeIdempotencyKeyReused: "This idempotency key has already been used for a different request",
+		ErrorCodeInvalidArgument:      "You have provided an invalid argument",
+		ErrorCodeInvalidCredentials:   "You have provided invalid credentials",
+		ErrorCodeInvalidCustomField:   "You have provided an invalid custom field",
+		ErrorCodeInvalidEmail:         "You have provided an invalid email address",
+		ErrorCodeInvalidForm:          "You have submitted an invalid form",
+		ErrorCodeInvalidIdentifier:    "You have provided an invalid company identifier",
+		ErrorCodeInvalidLEI:           "You have provided an invalid LEI",
+		ErrorCodeInvalidPhone:         "You have provided an invalid phone number",
+		ErrorCodeInvalidPostalCode:    "You have provided an invalid postal code",
+		ErrorCodeInvalidTransition:    "The requested status change is not allowed",
+		ErrorCodeInvalidUserStatus:    "Your account is not active",
+		ErrorCodeInvalidVATNumber:     "You have provided an invalid VAT number",
+		ErrorCodeInvalidWebsite:       "You have provided an invalid website",
+		ErrorCodeNotFound:             "The requested resource was not found",
+		ErrorCodePreconditionFailed:   "The resource has been modified since you last fetched it",
+		ErrorCodeResourceExists:       "Another resource with similar attributes already exists",
+		ErrorCodeRequestFailed:        "Request failed to complete. Please try again",
+		ErrorCodeRoleForbidden:        "You are not allowed to perform this request",
+		ErrorCodeSessionExpired:       "Your session has expired. Login again to proceed.",
+		ErrorCodeUnsupportedDocument:  "Documents of this type are not supported",
 	}
 
 	httpStatusErrorCodeMap = map[ErrorCode]int{
-		ErrorCodeInvalidCredentials: http.StatusUnauthorized,
-		ErrorCodeInvalidUserStatus:  http.StatusNotAcceptable,
-		ErrorCodeRoleForbidden:      http.StatusForbidden,
-		ErrorCodeSessionExpired:     http.StatusUnauthorized,
+		ErrorCodeAccessDenied:         http.StatusForbidden,
+		ErrorCodeDocumentTooLarge:     http.StatusRequestEntityTooLarge,
+		ErrorCodeIdempotencyKeyInUse:  http.StatusConflict,
+		ErrorCodeIdempotencyKeyReused: http.StatusUnprocessableEntity,
+		ErrorCodeInvalidCredentials:   http.StatusUnauthorized,
+		ErrorCodeInvalidTransition:    http.StatusConflict,
+		ErrorCodeInvalidUserStatus:    http.StatusNotAcceptable,
+		ErrorCodePreconditionFailed:   http.StatusPreconditionFailed,
+		ErrorCodeRoleForbidden:        http.StatusForbidden,
+		ErrorCodeSessionExpired:       http.StatusUnauthorized,
+		ErrorCodeUnsupportedDocument:  http.StatusUnsupportedMediaType,
 	}
 )
 
@@ -133,6 +172,19 @@ func (code ErrorCode) String() string {
 	return string(code)
 }
 
+// IsUniqueViolation reports whether err was caused by a unique constraint or
+// index rejecting a row.
+func IsUniqueViolation(err error) bool {
+
+	if wrappedError, ok := err.(*Error); ok {
+		err = wrappedError.Err()
+	}
+
+	var pqError *pq.Error
+
+	return errors.As(err, &pqError) && pqError.Code == uniqueViolationCode
+}
+
 func IsErrNoRows(err error) bool {
 
 	wrappedError, ok := err.(*Error)
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"html"
+	"strings"
+)
+
+// HighlightStart and HighlightStop delimit matches in text highlighted by the
+// database. Private use characters are used so they cannot clash with, or be
+// injected through, user supplied values.
+const (
+	HighlightStart = "\ue000"
+	HighlightStop  = "\ue001"
+)
+
+var highlightReplacer = strings.NewReplacer(
+	HighlightStart, "<mark>",
+	HighlightStop, "</mark>",
+)
+
+// Highlights escapes highlighted fields as HTML and wraps matches in <mark>
+// tags, dropping fields without any match.
+func Highlights(fields map[string]string) map[string]string {
+
+	highlights := make(map[string]string)
+
+	for field, value := range fields {
+
+		if !strings.Contains(value, HighlightStart) {
+			continue
+		}
+
+		highlights[field] = highlightReplacer.Replace(html.EscapeString(value))
+	}
+
+	return highlights
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHighlights(t *testing.T) {
+
+	Convey("Highlights", t, func() {
+
+		Convey("wraps matches in mark tags", func() {
+
+			highlights := Highlights(map[string]string{
+				"name": HighlightStart + "Apple" + HighlightStop + " Inc",
+			})
+
+			So(highlights["name"], ShouldEqual, "<mark>Apple</mark> Inc")
+		})
+
+		Convey("escapes user supplied markup", func() {
+
+			highlights := Highlights(map[string]string{
+				"name": "<b>" + HighlightStart + "Apple" + HighlightStop + "</b>",
+			})
+
+			So(highlights["name"], ShouldEqual, "&lt;b&gt;<mark>Apple</mark>&lt;/b&gt;")
+		})
+
+		Convey("drops fields without matches", func() {
+
+			highlights := Highlights(map[string]string{
+				"code": "APPL",
+			})
+
+			So(highlights, ShouldNotContainKey, "code")
+		})
+	})
+}
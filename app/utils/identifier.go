@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vonmutinda/organono/app/entities"
+)
+
+const maxRegistrationNumberLength = 50
+
+// vatFormat describes the national part of an EU VAT number, i.e. without
+// its two letter prefix. check verifies the check digits where the member
+// state publishes an algorithm.
+type vatFormat struct {
+	pattern *regexp.Regexp
+	check   func(number string) bool
+}
+
+var (
+	identifierSeparatorRe  = regexp.MustCompile(`[\s.\-/]+`)
+	leiRe                  = regexp.MustCompile(`^[A-Z0-9]{18}\d{2}$`)
+	registrationNumberRe   = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 ./-]*$`)
+	registrationCountryRe  = regexp.MustCompile(`^[A-Z]{2}$`)
+	registrationSpaceRe    = regexp.MustCompile(`\s+`)
+	vatCountryCodeOverride = map[string]string{
+		"EL": "GR",
+		"XI": "GB",
+	}
+
+	vatFormats = map[string]vatFormat{
+		"AT": {pattern: regexp.MustCompile(`^U\d{8}$`), check: checkATVAT},
+		"BE": {pattern: regexp.MustCompile(`^[01]\d{9}$`), check: checkBEVAT},
+		"BG": {pattern: regexp.MustCompile(`^\d{9,10}$`)},
+		"CY": {pattern: regexp.MustCompile(`^[013459]\d{7}[A-Z]$`), check: checkCYVAT},
+		"CZ": {pattern: regexp.MustCompile(`^\d{8,10}$`)},
+		"DE": {pattern: regexp.MustCompile(`^\d{9}$`), check: checkDEVAT},
+		"DK": {pattern: regexp.MustCompile(`^\d{8}$`), check: checkDKVAT},
+		"EE": {pattern: regexp.MustCompile(`^10\d{7}$`)},
+		"EL": {pattern: regexp.MustCompile(`^\d{9}$`), check: checkELVAT},
+		"ES": {pattern: regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`)},
+		"FI": {pattern: regexp.MustCompile(`^\d{8}$`), check: checkFIVAT},
+		"FR": {pattern: regexp.MustCompile(`^[A-HJ-NP-Z0-9]{2}\d{9}$`), check: checkFRVAT},
+		"HR": {pattern: regexp.MustCompile(`^\d{11}$`), check: checkHRVAT},
+		"HU": {pattern: regexp.MustCompile(`^\d{8}$`)},
+		"IE": {pattern: regexp.MustCompile(`^(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`)},
+		"IT": {pattern: regexp.MustCompile(`^\d{11}$`), check: checkLuhn},
+		"LT": {pattern: regexp.MustCompile(`^(\d{9}|\d{12})$`)},
+		"LU": {pattern: regexp.MustCompile(`^\d{8}$`), check: checkLUVAT},
+		"LV": {pattern: regexp.MustCompile(`^\d{11}$`)},
+		"MT": {pattern: regexp.MustCompile(`^\d{8}$`)},
+		"NL": {pattern: regexp.MustCompile(`^\d{9}B\d{2}$`), check: checkNLVAT},
+		"PL": {pattern: regexp.MustCompile(`^\d{10}$`), check: checkPLVAT},
+		"PT": {pattern: regexp.MustCompile(`^\d{9}$`), check: checkPTVAT},
+		"RO": {pattern: regexp.MustCompile(`^[1-9]\d{1,9}$`)},
+		"SE": {pattern: regexp.MustCompile(`^\d{10}01$`), check: checkSEVAT},
+		"SI": {pattern: regexp.MustCompile(`^[1-9]\d{7}$`)},
+		"SK": {pattern: regexp.MustCompile(`^[1-9]\d{9}$`), check: checkSKVAT},
+		"XI": {pattern: regexp.MustCompile(`^(\d{9}|\d{12}|GD\d{3}|HA\d{3})$`)},
+	}
+)
+
+// NormalizeCompanyIdentifier validates an identifier of the given type and
+// returns it in canonical form along with its issuing country. countryCode is
+// only used for registration numbers; VAT numbers carry their own country
+// and LEIs are global.
+func NormalizeCompanyIdentifier(
+	identifierType entities.IdentifierType,
+	countryCode,
+	value string,
+) (string, string, error) {
+
+	switch identifierType {
+	case entities.IdentifierTypeLEI:
+		lei, err := NormalizeLEI(value)
+		return lei, "", err
+	case entities.IdentifierTypeVAT:
+		return NormalizeVATNumber(value)
+	case entities.IdentifierTypeRegistration:
+		registrationNumber, err := NormalizeRegistrationNumber(countryCode, value)
+		return registrationNumber, strings.ToUpper(strings.TrimSpace(countryCode)), err
+	}
+
+	return "", "", NewErrorWithCode(
+		fmt.Errorf("identifier type (%v) is not supported", identifierType),
+		ErrorCodeInvalidIdentifier,
+		"check identifier type",
+	)
+}
+
+// NormalizeLEI validates a Legal Entity Identifier using the ISO 17442 check
+// digits, which follow the ISO 7064 MOD 97-10 scheme.
+func NormalizeLEI(lei string) (string, error) {
+
+	lei = strings.ToUpper(identifierSeparatorRe.ReplaceAllString(lei, ""))
+
+	if !leiRe.MatchString(lei) {
+		return "", NewErrorWithCode(
+			fmt.Errorf("lei (%v) is not 20 alphanumeric characters ending in 2 digits", lei),
+			ErrorCodeInvalidLEI,
+			"check lei format",
+		)
+	}
+
+	if mod97(lei) != 1 {
+		return "", NewErrorWithCode(
+			fmt.Errorf("lei (%v) has invalid check digits", lei),
+			ErrorCodeInvalidLEI,
+			"check lei check digits",
+		)
+	}
+
+	return lei, nil
+}
+
+// NormalizeVATNumber validates an EU VAT number, including its country
+// prefix, and returns it without separators along with the ISO country code
+// of the issuing member state.
+func NormalizeVATNumber(vatNumber string) (string, string, error) {
+
+	vatNumber = strings.ToUpper(identifierSeparatorRe.ReplaceAllString(vatNumber, ""))
+
+	if len(vatNumber) < 4 {
+		return "", "", NewErrorWithCode(
+			fmt.Errorf("vat number (%v) is too short", vatNumber),
+			ErrorCodeInvalidVATNumber,
+			"check vat number length",
+		)
+	}
+
+	prefix, number := vatNumber[:2], vatNumber[2:]
+
+	format, ok := vatFormats[prefix]
+	if !ok {
+		return "", "", NewErrorWithCode(
+			fmt.Errorf("vat number (%v) has unknown country prefix %v", vatNumber, prefix),
+			ErrorCodeInvalidVATNumber,
+			"check vat number country prefix",
+		)
+	}
+
+	if !format.pattern.MatchString(number) {
+		return "", "", NewErrorWithCode(
+			fmt.Errorf("vat number (%v) does not match the %v format", vatNumber, prefix),
+			ErrorCodeInvalidVATNumber,
+			"check vat number format",
+		)
+	}
+
+	if format.check != nil && !format.check(number) {
+		return "", "", NewErrorWithCode(
+			fmt.Errorf("vat number (%v) has invalid check digits", vatNumber),
+			ErrorCodeInvalidVATNumber,
+			"check vat number check digits",
+		)
+	}
+
+	countryCode := prefix
+	if override, ok := vatCountryCodeOverride[prefix]; ok {
+		countryCode = override
+	}
+
+	return vatNumber, countryCode, nil
+}
+
+// NormalizeRegistrationNumber accepts a free-form national registration
+// number. Only its issuing country and character set are checked since
+// formats vary between registries.
+func NormalizeRegistrationNumber(countryCode, registrationNumber string) (string, error) {
+
+	countryCode = strings.ToUpper(strings.TrimSpace(countryCode))
+	if !registrationCountryRe.MatchString(countryCode) {
+		return "", NewErrorWithCode(
+			fmt.Errorf("country code (%v) is not a 2 letter code", countryCode),
+			ErrorCodeInvalidIdentifier,
+			"check registration number country",
+		)
+	}
+
+	registrationNumber = strings.ToUpper(strings.TrimSpace(registrationSpaceRe.ReplaceAllString(registrationNumber, " ")))
+	if registrationNumber == "" {
+		return "", NewErrorWithCode(
+			errors.New("registration number is empty"),
+			ErrorCodeInvalidIdentifier,
+			"check registration number is provided",
+		)
+	}
+
+	if len(registrationNumber) > maxRegistrationNumberLength || !registrationNumberRe.MatchString(registrationNumber) {
+		return "", NewErrorWithCode(
+			fmt.Errorf("registration number (%v) is not valid", registrationNumber),
+			ErrorCodeInvalidIdentifier,
+			"check registration number format",
+		)
+	}
+
+	return registrationNumber, nil
+}
+
+// mod97 returns the remainder of value modulo 97 after letters are replaced
+// by two digit numbers, A = 10 through Z = 35.
+func mod97(value string) int64 {
+
+	var digits strings.Builder
+
+	for _, r := range value {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+			continue
+		}
+		digits.WriteRune(r)
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return -1
+	}
+
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64()
+}
+
+func vatDigits(number string) []int {
+
+	digits := make([]int, 0, len(number))
+	for _, r := range number {
+		digits = append(digits, int(r-'0'))
+	}
+
+	return digits
+}
+
+func weightedSum(digits, weights []int) int {
+
+	sum := 0
+	for i, weight := range weights {
+		sum += digits[i] * weight
+	}
+
+	return sum
+}
+
+// iso7064Mod1110 returns the ISO 7064 MOD 11,10 check digit for digits.
+func iso7064Mod1110(digits []int) int {
+
+	product := 10
+	for _, digit := range digits {
+		sum := (digit + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+
+	check := 11 - product
+	if check == 10 {
+		check = 0
+	}
+
+	return check
+}
+
+func checkLuhn(number string) bool {
+
+	digits := vatDigits(number)
+	sum := 0
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
+
+func checkATVAT(number string) bool {
+
+	digits := vatDigits(number[1:])
+	sum := 0
+
+	for i := 0; i < 7; i++ {
+		digit := digits[i]
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit = digit/10 + digit%10
+			}
+		}
+		sum += digit
+	}
+
+	return (10-(sum+4)%10)%10 == digits[7]
+}
+
+func checkBEVAT(number string) bool {
+
+	base, _ := strconv.Atoi(number[:8])
+	check, _ := strconv.Atoi(number[8:])
+
+	return 97-base%97 == check
+}
+
+func checkCYVAT(number string) bool {
+
+	odd := []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21}
+	digits := vatDigits(number[:8])
+	sum := 0
+
+	for i, digit := range digits {
+		if i%2 == 0 {
+			sum += odd[digit]
+			continue
+		}
+		sum += digit
+	}
+
+	return rune(number[8]) == rune('A'+sum%26)
+}
+
+func checkDEVAT(number string) bool {
+	digits := vatDigits(number)
+	return iso7064Mod1110(digits[:8]) == digits[8]
+}
+
+func checkDKVAT(number string) bool {
+	return weightedSum(vatDigits(number), []int{2, 7, 6, 5, 4, 3, 2, 1})%11 == 0
+}
+
+func checkELVAT(number string) bool {
+	digits := vatDigits(number)
+	return weightedSum(digits, []int{256, 128, 64, 32, 16, 8, 4, 2})%11%10 == digits[8]
+}
+
+func checkFIVAT(number string) bool {
+
+	digits := vatDigits(number)
+	remainder := weightedSum(digits, []int{7, 9, 10, 5, 8, 4, 2}) % 11
+
+	switch remainder {
+	case 0:
+		return digits[7] == 0
+	case 1:
+		return false
+	}
+
+	return 11-remainder == digits[7]
+}
+
+// checkFRVAT verifies the numeric validation key against the SIREN. Newer
+// alphanumeric keys have no published algorithm and only the format is
+// checked.
+func checkFRVAT(number string) bool {
+
+	key, err := strconv.Atoi(number[:2])
+	if err != nil {
+		return true
+	}
+
+	siren, _ := strconv.Atoi(number[2:])
+
+	return (12+3*(siren%97))%97 == key
+}
+
+func checkHRVAT(number string) bool {
+	digits := vatDigits(number)
+	return iso7064Mod1110(digits[:10]) == digits[10]
+}
+
+func checkLUVAT(number string) bool {
+
+	base, _ := strconv.Atoi(number[:6])
+	check, _ := strconv.Atoi(number[6:])
+
+	return base%89 == check
+}
+
+// checkNLVAT accepts both the legacy eleven-test numbers and the MOD 97
+// numbers issued to sole proprietors since 2020.
+func checkNLVAT(number string) bool {
+
+	digits := vatDigits(number[:9])
+	remainder := weightedSum(digits, []int{9, 8, 7, 6, 5, 4, 3, 2}) % 11
+	if remainder != 10 && remainder == digits[8] {
+		return true
+	}
+
+	return mod97("NL"+number) == 1
+}
+
+func checkPLVAT(number string) bool {
+	digits := vatDigits(number)
+	return weightedSum(digits, []int{6, 5, 7, 2, 3, 4, 5, 6, 7})%11 == digits[9]
+}
+
+func checkPTVAT(number string) bool {
+
+	digits := vatDigits(number)
+	check := 11 - weightedSum(digits, []int{9, 8, 7, 6, 5, 4, 3, 2})%11
+	if check > 9 {
+		check = 0
+	}
+
+	return check == digits[8]
+}
+
+func checkSEVAT(number string) bool {
+	return checkLuhn(number[:10])
+}
+
+func checkSKVAT(number string) bool {
+	n, _ := strconv.ParseInt(number, 10, 64)
+	return n%11 == 0
+}
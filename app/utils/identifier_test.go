@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/vonmutinda/organono/app/entities"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalizeLEI(t *testing.T) {
+
+	Convey("NormalizeLEI", t, func() {
+
+		Convey("accepts leis with valid check digits", func() {
+
+			leis := []string{
+				"5493001KJTIIGC8Y1R12",
+				"529900T8BM49AURSDO55",
+				"hwupkr0mpou8fgxbt394",
+				"5493 001K JTII GC8Y 1R12",
+			}
+
+			for _, lei := range leis {
+				_, err := NormalizeLEI(lei)
+				So(err, ShouldBeNil)
+			}
+
+			lei, err := NormalizeLEI("hwupkr0mpou8fgxbt394")
+			So(err, ShouldBeNil)
+			So(lei, ShouldEqual, "HWUPKR0MPOU8FGXBT394")
+		})
+
+		Convey("rejects malformed leis and invalid check digits", func() {
+
+			leis := []string{
+				"5493001KJTIIGC8Y1R13",
+				"5493001KJTIIGC8Y1R1",
+				"5493001KJTIIGC8Y1RAB",
+				"",
+			}
+
+			for _, lei := range leis {
+				_, err := NormalizeLEI(lei)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidLEI)
+			}
+		})
+	})
+}
+
+func TestNormalizeVATNumber(t *testing.T) {
+
+	Convey("NormalizeVATNumber", t, func() {
+
+		Convey("accepts vat numbers with valid check digits", func() {
+
+			vatNumbers := []string{
+				"ATU13585627",
+				"BE0428759497",
+				"CY10259033P",
+				"DE136695976",
+				"DK13585628",
+				"EL094259216",
+				"FI20774740",
+				"FR40303265045",
+				"HR33392005961",
+				"IT00743110157",
+				"LU15027442",
+				"NL004495445B01",
+				"PL5260250995",
+				"PT501964843",
+				"SE556188840401",
+				"SK2022749619",
+				"ESA28015865",
+				"IE6388047V",
+			}
+
+			for _, vatNumber := range vatNumbers {
+				_, _, err := NormalizeVATNumber(vatNumber)
+				So(err, ShouldBeNil)
+			}
+		})
+
+		Convey("strips separators and maps the prefix to a country", func() {
+
+			vatNumber, countryCode, err := NormalizeVATNumber("el 094.259.216")
+			So(err, ShouldBeNil)
+			So(vatNumber, ShouldEqual, "EL094259216")
+			So(countryCode, ShouldEqual, "GR")
+		})
+
+		Convey("rejects unknown prefixes, bad formats and invalid check digits", func() {
+
+			vatNumbers := []string{
+				"DE136695977",
+				"CY10259033A",
+				"ATU1358562",
+				"US123456789",
+				"DE",
+			}
+
+			for _, vatNumber := range vatNumbers {
+				_, _, err := NormalizeVATNumber(vatNumber)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidVATNumber)
+			}
+		})
+	})
+}
+
+func TestNormalizeCompanyIdentifier(t *testing.T) {
+
+	Convey("NormalizeCompanyIdentifier", t, func() {
+
+		Convey("keeps registration numbers free-form within a country", func() {
+
+			value, countryCode, err := NormalizeCompanyIdentifier(entities.IdentifierTypeRegistration, "cy", " he  123456 ")
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, "HE 123456")
+			So(countryCode, ShouldEqual, "CY")
+		})
+
+		Convey("requires a country for registration numbers", func() {
+
+			_, _, err := NormalizeCompanyIdentifier(entities.IdentifierTypeRegistration, "", "HE123456")
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidIdentifier)
+		})
+
+		Convey("takes the country of a vat number from its prefix", func() {
+
+			value, countryCode, err := NormalizeCompanyIdentifier(entities.IdentifierTypeVAT, "FR", "CY10259033P")
+			So(err, ShouldBeNil)
+			So(value, ShouldEqual, "CY10259033P")
+			So(countryCode, ShouldEqual, "CY")
+		})
+
+		Convey("rejects unknown identifier types", func() {
+
+			_, _, err := NormalizeCompanyIdentifier(entities.IdentifierType("duns"), "", "123456789")
+			So(err, ShouldNotBeNil)
+
+			appError, ok := err.(*Error)
+			So(ok, ShouldBeTrue)
+			So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidIdentifier)
+		})
+	})
+}
@@ -13,15 +13,18 @@ var (
 		FirstName: "Trading",
 		LastName:  "Point",
 		Username:  "xm",
+		Role:      entities.RoleAdmin,
 		Status:    "active",
 	}
 
 	defaultPassword = "password"
 
+	// The seed user is the only account on a fresh database, so it is an
+	// admin able to create the other users and assign their roles.
 	saveUserSQL = `
 		INSERT INTO users 
-			(first_name, last_name, username, password_hash, status, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+			(first_name, last_name, username, password_hash, role, status, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (username) DO NOTHING
 		`
 )
@@ -42,6 +45,7 @@ func LoadTestData(dB db.DB) error {
 		user.LastName,
 		user.Username,
 		user.PasswordHash,
+		user.Role,
 		user.Status,
 		user.CreatedAt,
 		user.UpdatedAt,
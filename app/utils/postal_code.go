@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxPostalCodeLength = 20
+
+// postalCodeFormat describes a country's postal code after spaces are
+// removed. When spaceAt is set, the normalized code has a single space
+// before its last spaceAt characters, e.g. "SW1A 1AA" or "1012 AB".
+type postalCodeFormat struct {
+	pattern *regexp.Regexp
+	spaceAt int
+}
+
+var (
+	genericPostalCodeRe = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]*$`)
+	postalCodeSpaceRe   = regexp.MustCompile(`\s+`)
+
+	postalCodeFormats = map[string]postalCodeFormat{
+		"AT": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"AU": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"BE": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"CA": {pattern: regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[A-Z]\d[A-Z]\d$`), spaceAt: 3},
+		"CH": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"CY": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"DE": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"DK": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"ES": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"FI": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"FR": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"GB": {pattern: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]?\d[A-Z]{2}$`), spaceAt: 3},
+		"GR": {pattern: regexp.MustCompile(`^\d{5}$`), spaceAt: 2},
+		"IE": {pattern: regexp.MustCompile(`^[AC-FHKNPRTV-Y]\d[\dW][AC-FHKNPRTV-Y\d]{4}$`), spaceAt: 4},
+		"IN": {pattern: regexp.MustCompile(`^[1-9]\d{5}$`)},
+		"IT": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"JP": {pattern: regexp.MustCompile(`^\d{3}-\d{4}$`)},
+		"KE": {pattern: regexp.MustCompile(`^\d{5}$`)},
+		"NL": {pattern: regexp.MustCompile(`^[1-9]\d{3}[A-Z]{2}$`), spaceAt: 2},
+		"NO": {pattern: regexp.MustCompile(`^\d{4}$`)},
+		"PL": {pattern: regexp.MustCompile(`^\d{2}-\d{3}$`)},
+		"PT": {pattern: regexp.MustCompile(`^\d{4}-\d{3}$`)},
+		"SE": {pattern: regexp.MustCompile(`^\d{5}$`), spaceAt: 2},
+		"US": {pattern: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+	}
+)
+
+// NormalizePostalCode validates a postal code against the format of the
+// country it belongs to and returns it in its canonical form. Countries
+// without a known format accept any short alphanumeric code, or none.
+func NormalizePostalCode(countryCode, postalCode string) (string, error) {
+
+	countryCode = strings.ToUpper(strings.TrimSpace(countryCode))
+	postalCode = strings.ToUpper(strings.TrimSpace(postalCodeSpaceRe.ReplaceAllString(postalCode, " ")))
+
+	format, ok := postalCodeFormats[countryCode]
+	if !ok {
+		if postalCode == "" {
+			return "", nil
+		}
+
+		if len(postalCode) > maxPostalCodeLength || !genericPostalCodeRe.MatchString(postalCode) {
+			return "", NewErrorWithCode(
+				fmt.Errorf("postal code (%v) is not valid", postalCode),
+				ErrorCodeInvalidPostalCode,
+				"check postal code format for country = %v",
+				countryCode,
+			)
+		}
+
+		return postalCode, nil
+	}
+
+	if postalCode == "" {
+		return "", NewErrorWithCode(
+			errors.New("postal code is empty"),
+			ErrorCodeInvalidPostalCode,
+			"check postal code is provided for country = %v",
+			countryCode,
+		)
+	}
+
+	compactPostalCode := strings.ReplaceAll(postalCode, " ", "")
+
+	if !format.pattern.MatchString(compactPostalCode) {
+		return "", NewErrorWithCode(
+			fmt.Errorf("postal code (%v) does not match the %v format", postalCode, countryCode),
+			ErrorCodeInvalidPostalCode,
+			"check postal code format for country = %v",
+			countryCode,
+		)
+	}
+
+	if format.spaceAt > 0 {
+		split := len(compactPostalCode) - format.spaceAt
+		return compactPostalCode[:split] + " " + compactPostalCode[split:], nil
+	}
+
+	return compactPostalCode, nil
+}
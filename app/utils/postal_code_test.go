@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalizePostalCode(t *testing.T) {
+
+	Convey("NormalizePostalCode", t, func() {
+
+		Convey("accepts and normalizes known country formats", func() {
+
+			postalCodes := []struct {
+				countryCode string
+				postalCode  string
+				expected    string
+			}{
+				{"CY", " 1065 ", "1065"},
+				{"GB", "sw1a1aa", "SW1A 1AA"},
+				{"GB", "M1  1AE", "M1 1AE"},
+				{"GR", "10557", "105 57"},
+				{"NL", "1012ab", "1012 AB"},
+				{"CA", "k1a 0b1", "K1A 0B1"},
+				{"IE", "D02X285", "D02 X285"},
+				{"US", "94105-1804", "94105-1804"},
+				{"de", "10115", "10115"},
+				{"PL", "00-950", "00-950"},
+			}
+
+			for _, tc := range postalCodes {
+				postalCode, err := NormalizePostalCode(tc.countryCode, tc.postalCode)
+				So(err, ShouldBeNil)
+				So(postalCode, ShouldEqual, tc.expected)
+			}
+		})
+
+		Convey("rejects codes that do not match the country format", func() {
+
+			postalCodes := []struct {
+				countryCode string
+				postalCode  string
+			}{
+				{"CY", "106"},
+				{"CY", ""},
+				{"GB", "12345"},
+				{"US", "9410"},
+				{"NL", "0123 AB"},
+				{"PL", "00950"},
+			}
+
+			for _, tc := range postalCodes {
+				_, err := NormalizePostalCode(tc.countryCode, tc.postalCode)
+				So(err, ShouldNotBeNil)
+
+				appError, ok := err.(*Error)
+				So(ok, ShouldBeTrue)
+				So(appError.GetErrorCode(), ShouldEqual, ErrorCodeInvalidPostalCode)
+			}
+		})
+
+		Convey("accepts short codes for countries without a known format", func() {
+
+			postalCode, err := NormalizePostalCode("BR", "01310-100")
+			So(err, ShouldBeNil)
+			So(postalCode, ShouldEqual, "01310-100")
+
+			postalCode, err = NormalizePostalCode("AE", "")
+			So(err, ShouldBeNil)
+			So(postalCode, ShouldBeEmpty)
+
+			_, err = NormalizePostalCode("BR", "#01310")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
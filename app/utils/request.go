@@ -40,6 +40,9 @@ func DoRequestWithHeaders(
 		return w, err
 	}
 
+	// Requests are served as if they came from a client or proxy on the
+	// same host.
+	req.RemoteAddr = "127.0.0.1:42000"
 	req.Header.Set("Content-Type", "application/json")
 
 	if len(headers) > 0 {
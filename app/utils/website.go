@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var (
+	defaultPorts = map[string]string{
+		"http":  "80",
+		"https": "443",
+	}
+
+	websiteHostProfile = idna.New(
+		idna.MapForLookup(),
+		idna.BidiRule(),
+		idna.VerifyDNSLength(true),
+	)
+)
+
+// Website is a validated company website. URL keeps the address as it should
+// be displayed while Domain is the canonical ASCII host used to compare
+// websites for uniqueness.
+type Website struct {
+	URL    string
+	Domain string
+}
+
+// NormalizeWebsite validates a website address and derives its canonical
+// form. A missing scheme defaults to https, the host is lowercased, default
+// ports are dropped and internationalized hosts are converted to punycode for
+// the domain. The domain also drops any leading "www.".
+func NormalizeWebsite(website string) (*Website, error) {
+
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return &Website{}, NewErrorWithCode(
+			errors.New("website is empty"),
+			ErrorCodeInvalidWebsite,
+			"check website is provided",
+		)
+	}
+
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+
+	parsedURL, err := url.Parse(website)
+	if err != nil {
+		return &Website{}, NewErrorWithCode(
+			err,
+			ErrorCodeInvalidWebsite,
+			"parse website url",
+		)
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+
+	if _, ok := defaultPorts[parsedURL.Scheme]; !ok {
+		return &Website{}, NewErrorWithCode(
+			fmt.Errorf("unsupported website scheme (%v)", parsedURL.Scheme),
+			ErrorCodeInvalidWebsite,
+			"check website scheme",
+		)
+	}
+
+	if parsedURL.User != nil {
+		return &Website{}, NewErrorWithCode(
+			errors.New("website contains credentials"),
+			ErrorCodeInvalidWebsite,
+			"check website user info",
+		)
+	}
+
+	host := strings.TrimSuffix(parsedURL.Hostname(), ".")
+	port := parsedURL.Port()
+
+	if net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return &Website{}, NewErrorWithCode(
+			fmt.Errorf("website host (%v) is not a domain name", host),
+			ErrorCodeInvalidWebsite,
+			"check website host",
+		)
+	}
+
+	asciiHost, err := websiteHostProfile.ToASCII(host)
+	if err != nil {
+		return &Website{}, NewErrorWithCode(
+			err,
+			ErrorCodeInvalidWebsite,
+			"convert website host = %v to ascii",
+			host,
+		)
+	}
+
+	unicodeHost, err := idna.Display.ToUnicode(asciiHost)
+	if err != nil {
+		return &Website{}, NewErrorWithCode(
+			err,
+			ErrorCodeInvalidWebsite,
+			"convert website host = %v to unicode",
+			asciiHost,
+		)
+	}
+
+	if port == defaultPorts[parsedURL.Scheme] {
+		port = ""
+	}
+
+	displayHost := unicodeHost
+	if port != "" {
+		displayHost = net.JoinHostPort(unicodeHost, port)
+	}
+
+	if parsedURL.Path == "/" && parsedURL.RawQuery == "" && parsedURL.Fragment == "" {
+		parsedURL.Path = ""
+	}
+
+	// url.URL escapes non-ASCII hosts, so only the remainder of the address is
+	// rendered through it.
+	scheme := parsedURL.Scheme
+	parsedURL.Scheme = ""
+	parsedURL.Host = ""
+
+	normalizedWebsite := &Website{
+		URL:    scheme + "://" + displayHost + parsedURL.String(),
+		Domain: strings.TrimPrefix(asciiHost, "www."),
+	}
+
+	return normalizedWebsite, nil
+}
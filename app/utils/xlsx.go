@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+const (
+	xlsxContentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooterXML = `</sheetData></worksheet>`
+)
+
+// XLSXWriter writes a single sheet workbook of inline string cells. Rows are
+// written straight through to the underlying writer so that large exports do
+// not have to be held in memory.
+type XLSXWriter struct {
+	rows   int
+	sheet  io.Writer
+	zipper *zip.Writer
+}
+
+func NewXLSXWriter(w io.Writer) (*XLSXWriter, error) {
+
+	zipper := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypesXML},
+		{"_rels/.rels", xlsxRelsXML},
+		{"xl/workbook.xml", xlsxWorkbookXML},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRelsXML},
+	}
+
+	for _, part := range parts {
+
+		partWriter, err := zipper.Create(part.name)
+		if err != nil {
+			return &XLSXWriter{}, err
+		}
+
+		_, err = io.WriteString(partWriter, part.content)
+		if err != nil {
+			return &XLSXWriter{}, err
+		}
+	}
+
+	sheet, err := zipper.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return &XLSXWriter{}, err
+	}
+
+	_, err = io.WriteString(sheet, xlsxSheetHeaderXML)
+	if err != nil {
+		return &XLSXWriter{}, err
+	}
+
+	return &XLSXWriter{
+		sheet:  sheet,
+		zipper: zipper,
+	}, nil
+}
+
+func (w *XLSXWriter) Write(record []string) error {
+
+	w.rows++
+
+	_, err := io.WriteString(w.sheet, `<row r="`+strconv.Itoa(w.rows)+`">`)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range record {
+
+		_, err = io.WriteString(w.sheet, `<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err != nil {
+			return err
+		}
+
+		err = xml.EscapeText(w.sheet, []byte(value))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w.sheet, `</t></is></c>`)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w.sheet, `</row>`)
+	return err
+}
+
+func (w *XLSXWriter) Flush() error {
+	return w.zipper.Flush()
+}
+
+func (w *XLSXWriter) Close() error {
+
+	_, err := io.WriteString(w.sheet, xlsxSheetFooterXML)
+	if err != nil {
+		return err
+	}
+
+	return w.zipper.Close()
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestXLSXWriter(t *testing.T) {
+
+	Convey("XLSX Writer", t, func() {
+
+		var buf bytes.Buffer
+
+		writer, err := NewXLSXWriter(&buf)
+		So(err, ShouldBeNil)
+
+		So(writer.Write([]string{"name", "website"}), ShouldBeNil)
+		So(writer.Write([]string{"Procter & Gamble", "<https://pg.com>"}), ShouldBeNil)
+		So(writer.Close(), ShouldBeNil)
+
+		reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		So(err, ShouldBeNil)
+
+		files := make(map[string]*zip.File)
+		for _, file := range reader.File {
+			files[file.Name] = file
+		}
+
+		So(files, ShouldContainKey, "[Content_Types].xml")
+		So(files, ShouldContainKey, "xl/workbook.xml")
+		So(files, ShouldContainKey, "xl/worksheets/sheet1.xml")
+
+		sheet, err := files["xl/worksheets/sheet1.xml"].Open()
+		So(err, ShouldBeNil)
+
+		content, err := ioutil.ReadAll(sheet)
+		So(err, ShouldBeNil)
+
+		So(string(content), ShouldContainSubstring, `<row r="2">`)
+		So(string(content), ShouldContainSubstring, "Procter &amp; Gamble")
+		So(string(content), ShouldContainSubstring, "&lt;https://pg.com&gt;")
+		So(string(content), ShouldEndWith, "</sheetData></worksheet>")
+	})
+}
@@ -0,0 +1,20 @@
+package addresses
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyAddressService services.CompanyAddressService,
+) {
+	r.POST("/companies/:id/countries/:country_id/addresses", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), addCompanyAddress(dB, companyAddressService))
+	r.GET("/companies/:id/addresses", listCompanyAddresses(dB, companyAddressService))
+	r.PUT("/companies/:id/addresses/:address_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), updateCompanyAddress(dB, companyAddressService))
+	r.DELETE("/companies/:id/addresses/:address_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), removeCompanyAddress(dB, companyAddressService))
+}
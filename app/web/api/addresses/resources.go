@@ -0,0 +1,217 @@
+package addresses
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func addCompanyAddress(
+	dB db.DB,
+	companyAddressService services.CompanyAddressService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, countryID, err := idsFromContext(c, "country_id")
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company country path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CreateCompanyAddressForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create company address form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		address, err := companyAddressService.AddCompanyAddress(ctx, dB, companyID, countryID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to add address to company id = %v country id = %v form = [%+v]",
+				companyID,
+				countryID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, address)
+	}
+}
+
+func listCompanyAddresses(
+	dB db.DB,
+	companyAddressService services.CompanyAddressService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		addresses, err := companyAddressService.ListCompanyAddresses(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list addresses for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, addresses)
+	}
+}
+
+func removeCompanyAddress(
+	dB db.DB,
+	companyAddressService services.CompanyAddressService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, addressID, err := idsFromContext(c, "address_id")
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company address path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		address, err := companyAddressService.RemoveCompanyAddress(ctx, dB, companyID, addressID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to remove address id = %v from company id = %v",
+				addressID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, address)
+	}
+}
+
+func updateCompanyAddress(
+	dB db.DB,
+	companyAddressService services.CompanyAddressService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, addressID, err := idsFromContext(c, "address_id")
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company address path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.UpdateCompanyAddressForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind update company address form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		address, err := companyAddressService.UpdateCompanyAddress(ctx, dB, companyID, addressID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to update address id = %v for company id = %v form = [%+v]",
+				addressID,
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, address)
+	}
+}
+
+func idsFromContext(
+	c *gin.Context,
+	param string,
+) (int64, int64, error) {
+
+	companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse company id = %v",
+			c.Param("id"),
+		)
+	}
+
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse %v = %v",
+			param,
+			c.Param(param),
+		)
+	}
+
+	return companyID, id, nil
+}
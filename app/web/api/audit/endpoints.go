@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	auditService services.AuditService,
+) {
+	r.GET("/audit", auth.AllowOnlyWithPermission(entities.PermissionAuditRead), listAuditLogs(dB, auditService))
+	r.GET("/companies/:id/audit", auth.AllowOnlyWithPermission(entities.PermissionAuditRead), listCompanyAuditLogs(dB, auditService))
+}
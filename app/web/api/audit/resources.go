@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func listAuditLogs(
+	dB db.DB,
+	auditService services.AuditService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		filter, err := webutils.AuditFilterFromContext(c)
+		if err != nil {
+			appError := utils.NewError(
+				err,
+				"Failed to parse audit filter params from context",
+			)
+
+			webutils.HandleError(c, appError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		auditLogList, err := auditService.ListAuditLogs(ctx, dB, filter)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to fetch audit logs",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, auditLogList)
+	}
+}
+
+func listCompanyAuditLogs(
+	dB db.DB,
+	auditService services.AuditService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		filter, err := webutils.AuditFilterFromContext(c)
+		if err != nil {
+			appError := utils.NewError(
+				err,
+				"Failed to parse audit filter params from context",
+			)
+
+			webutils.HandleError(c, appError)
+			return
+		}
+
+		filter.ResourceID = companyID
+		filter.ResourceType = entities.AuditResourceCompany
+
+		ctx := c.Request.Context()
+
+		auditLogList, err := auditService.ListAuditLogs(ctx, dB, filter)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to fetch audit logs for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, auditLogList)
+	}
+}
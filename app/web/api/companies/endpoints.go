@@ -3,7 +3,9 @@ package companies
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
 )
 
 func AddEndpoints(
@@ -11,9 +13,14 @@ func AddEndpoints(
 	dB db.DB,
 	companyService services.CompanyService,
 ) {
-	r.POST("/companies", createCompany(dB, companyService))
+	r.POST("/companies", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), createCompany(dB, companyService))
 	r.GET("/companies", listCompanies(dB, companyService))
+	r.GET("/companies/export", exportCompanies(dB, companyService))
+	r.POST("/companies/import", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), importCompanies(dB, companyService))
+	r.GET("/companies/search", searchCompanies(dB, companyService))
 	r.GET("/companies/:id", getCompany(dB, companyService))
-	r.PUT("/companies/:id", updateCompany(dB, companyService))
-	r.DELETE("/companies/:id", deleteCompany(dB, companyService))
+	r.PUT("/companies/:id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), updateCompany(dB, companyService))
+	r.DELETE("/companies/:id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesDelete), deleteCompany(dB, companyService))
+	r.POST("/companies/:id/restore", auth.AllowOnlyWithPermission(entities.PermissionCompaniesDelete), restoreCompany(dB, companyService))
+	r.GET("/companies/:id/subsidiaries", listSubsidiaries(dB, companyService))
 }
@@ -3,11 +3,14 @@ package companies
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +29,12 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+type failingIPAPI struct{}
+
+func (p *failingIPAPI) CountryForIP(ctx context.Context, ipAddress string) (*providers.CountryWithIP, error) {
+	return &providers.CountryWithIP{}, errors.New("rate limited")
+}
+
 func TestCompanyEndpointsRepository(t *testing.T) {
 
 	testDB := db.InitDB()
@@ -40,7 +49,6 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 	companyService := services.NewTestCompanyService()
 
 	sessionAuthenticator := auth.NewSessionAuthenticator(
-		providers.NewIPAPI(),
 		sessionRepository,
 		userRepository,
 	)
@@ -48,10 +56,38 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 
 	Convey("Company Endpoints", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
 
+		geoIPDatabasePath := filepath.Join(t.TempDir(), "countries.mmdb")
+
+		err := providers.CreateGeoIPDatabase(geoIPDatabasePath, map[string]*providers.CountryWithIP{
+			"176.56.168.0/21":  {CountryCode: "CY", CountryName: "Cyprus"},
+			"197.156.112.0/20": {CountryCode: "KE", CountryName: "Kenya"},
+		})
+		So(err, ShouldBeNil)
+
+		geoIPDatabase, err := providers.NewGeoIPDatabase(geoIPDatabasePath)
+		So(err, ShouldBeNil)
+
+		accessPolicyService := services.NewAccessPolicyServiceWithConfig(
+			repos.NewAccessPolicyRuleRepository(),
+			geoIPDatabase,
+			entities.AccessPolicyModeEnforce,
+			[]*entities.AccessPolicyRule{
+				{
+					Name:      "cyprus_unauthenticated_company_creation",
+					RuleType:  entities.AccessPolicyRuleTypeSkipAuthentication,
+					Countries: []string{"CY"},
+					Methods:   []string{http.MethodPost},
+					Routes:    []string{"/v1/companies"},
+					Enabled:   true,
+				},
+			},
+		)
+
 		testRouter := gin.Default()
 		testRouter.Use(middleware.DefaultMiddlewares(sessionAuthenticator)...)
 
 		routerGroup := testRouter.Group("/v1")
+		routerGroup.Use(middleware.AccessPolicy(dB, accessPolicyService))
 		routerGroup.Use(auth.AllowOnlyActiveUser(
 			dB,
 			sessionAuthenticator,
@@ -134,6 +170,37 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 				So(w.Code, ShouldEqual, http.StatusOK)
 			})
 
+			Convey("returns the company version as an etag", func() {
+
+				company, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
+				So(err, ShouldBeNil)
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, fmt.Sprintf("/v1/companies/%d", company.ID), nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldEqual, fmt.Sprintf(`"%d"`, company.Version))
+			})
+
+			Convey("cannot update a company with a stale if-match header", func() {
+
+				company, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
+				So(err, ShouldBeNil)
+
+				form := &forms.UpdateCompanyForm{
+					Name: null.StringFrom("Microsoft"),
+				}
+
+				headers := map[string]string{
+					"If-Match": fmt.Sprintf(`"%d"`, company.Version+1),
+				}
+
+				w, err := utils.DoRequestWithHeaders(testRouter, http.MethodPut, fmt.Sprintf("/v1/companies/%v", company.ID), form, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusPreconditionFailed)
+			})
+
 			Convey("can delete a company", func() {
 
 				company, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
@@ -149,6 +216,107 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 				So(utils.IsErrNoRows(err), ShouldBeTrue)
 			})
 
+			Convey("editors cannot delete a company", func() {
+
+				user.Role = entities.RoleEditor
+
+				token, err := auth.NewJWTHandler().CreateUserToken(user, session)
+				So(err, ShouldBeNil)
+
+				company, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
+				So(err, ShouldBeNil)
+
+				w, err := utils.DoRequest(testRouter, http.MethodDelete, fmt.Sprintf("/v1/companies/%v", company.ID), nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+
+				_, err = companyRepository.CompanyByID(ctx, dB, company.ID)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("viewers can list but cannot create companies", func() {
+
+				user.Role = entities.RoleViewer
+
+				token, err := auth.NewJWTHandler().CreateUserToken(user, session)
+				So(err, ShouldBeNil)
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, "/v1/companies", nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				form := &forms.CreateCompanyForm{
+					Name:    "Microsoft",
+					Code:    "SOFT",
+					Country: "Cyprus",
+					Website: "https://microsoft.com",
+					Phone:   "+35790034567",
+				}
+
+				w, err = utils.DoRequest(testRouter, http.MethodPost, "/v1/companies", form, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+			})
+
+			Convey("can import companies from csv", func() {
+
+				body := "name,code,country,website,phone\n" +
+					"Microsoft,SOFT,Cyprus,https://microsoft.com,+35790034567\n"
+
+				req, err := http.NewRequest(http.MethodPost, "/v1/companies/import?mode=best_effort", bytes.NewBufferString(body))
+				So(err, ShouldBeNil)
+
+				req.Header.Set("Content-Type", "text/csv")
+				req.Header.Set("X-ORGANONO-TOKEN", token)
+
+				w := httptest.NewRecorder()
+				testRouter.ServeHTTP(w, req)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var report entities.CompanyImportReport
+
+				err = json.Unmarshal(w.Body.Bytes(), &report)
+				So(err, ShouldBeNil)
+
+				So(report.Total, ShouldEqual, 1)
+				So(report.Created, ShouldEqual, 1)
+			})
+
+			Convey("can export filtered companies as csv", func() {
+
+				tradingPointCyprus, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
+				So(err, ShouldBeNil)
+
+				_, _, err = repos.CreateCompany(ctx, dB, "XM LTD", country)
+				So(err, ShouldBeNil)
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, "/v1/companies/export?format=csv&term=point", nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldStartWith, "text/csv")
+				So(w.Header().Get("Content-Disposition"), ShouldStartWith, `attachment; filename="companies-`)
+
+				records, err := csv.NewReader(w.Body).ReadAll()
+				So(err, ShouldBeNil)
+
+				So(len(records), ShouldEqual, 2)
+				So(records[0][0], ShouldEqual, "id")
+				So(records[1][1], ShouldEqual, tradingPointCyprus.Name)
+			})
+
+			Convey("cannot export companies in an unsupported format", func() {
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, "/v1/companies/export?format=pdf", nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
 			Convey("can list companies", func() {
 
 				_, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
@@ -173,6 +341,22 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 				So(len(companyList.Companies), ShouldEqual, 2)
 			})
 
+			Convey("cannot list companies sorted by an unknown field", func() {
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, "/v1/companies?sort=name,-password", nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("cannot search companies with a short query", func() {
+
+				w, err := utils.DoRequest(testRouter, http.MethodGet, "/v1/companies/search?q=a", nil, token)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
 			Convey("can list companies based on search", func() {
 
 				tradingPointCyprus, _, err := repos.CreateCompany(ctx, dB, "Trading Point LLC", country)
@@ -199,6 +383,143 @@ func TestCompanyEndpointsRepository(t *testing.T) {
 			})
 		})
 
+		Convey("access policy", func() {
+
+			accessPolicyService := services.NewAccessPolicyServiceWithConfig(
+				repos.NewAccessPolicyRuleRepository(),
+				geoIPDatabase,
+				entities.AccessPolicyModeEnforce,
+				[]*entities.AccessPolicyRule{
+					{
+						Name:      "cyprus_company_writes",
+						RuleType:  entities.AccessPolicyRuleTypeRestrict,
+						Countries: []string{"CY"},
+						Methods:   []string{http.MethodPost, http.MethodDelete},
+						Routes:    []string{"/v1/companies", "/v1/companies/:id"},
+						Enabled:   true,
+					},
+				},
+			)
+
+			policyRouter := gin.Default()
+			policyRouter.Use(middleware.DefaultMiddlewares(sessionAuthenticator)...)
+
+			policyRouterGroup := policyRouter.Group("/v1")
+			policyRouterGroup.Use(middleware.AccessPolicy(dB, accessPolicyService))
+			policyRouterGroup.Use(auth.AllowOnlyActiveUser(
+				dB,
+				sessionAuthenticator,
+				sessionService,
+			))
+
+			AddEndpoints(policyRouterGroup, dB, companyService)
+
+			user, err := repos.CreateUser(ctx, dB)
+			So(err, ShouldBeNil)
+
+			session, err := repos.CreateSession(ctx, dB, user.ID)
+			So(err, ShouldBeNil)
+
+			token, err := auth.NewJWTHandler().CreateUserToken(user, session)
+			So(err, ShouldBeNil)
+
+			form := &forms.CreateCompanyForm{
+				Name:    "Microsoft",
+				Code:    "SOFT",
+				Country: "Cyprus",
+				Website: "https://microsoft.com",
+				Phone:   "+35790034567",
+			}
+
+			Convey("can create a company from cyprus", func() {
+
+				headers := map[string]string{"X-Forwarded-For": "176.56.168.100"} // cyprus ip address
+
+				w, err := utils.DoRequestWithHeaders(policyRouter, http.MethodPost, "/v1/companies", form, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusCreated)
+			})
+
+			Convey("cannot create a company from kenya", func() {
+
+				headers := map[string]string{"X-Forwarded-For": "197.156.118.251"} // kenya ip address
+
+				w, err := utils.DoRequestWithHeaders(policyRouter, http.MethodPost, "/v1/companies", form, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+			})
+
+			Convey("ignores X-Forwarded-For from untrusted proxies", func() {
+
+				err := policyRouter.SetTrustedProxies(nil)
+				So(err, ShouldBeNil)
+
+				headers := map[string]string{"X-Forwarded-For": "176.56.168.100"} // cyprus ip address
+
+				w, err := utils.DoRequestWithHeaders(policyRouter, http.MethodPost, "/v1/companies", form, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+			})
+
+			Convey("can create a company while country lookups fail open", func() {
+
+				cachedIPAPI := providers.NewCachedIPAPIWithOptions(
+					&failingIPAPI{},
+					nil,
+					providers.CachedIPAPIOptions{FailureMode: providers.IPAPIFailureModeOpen},
+				)
+
+				accessPolicyService := services.NewAccessPolicyServiceWithConfig(
+					repos.NewAccessPolicyRuleRepository(),
+					cachedIPAPI,
+					entities.AccessPolicyModeEnforce,
+					[]*entities.AccessPolicyRule{
+						{
+							Name:      "cyprus_company_writes",
+							RuleType:  entities.AccessPolicyRuleTypeRestrict,
+							Countries: []string{"CY"},
+							Methods:   []string{http.MethodPost, http.MethodDelete},
+							Routes:    []string{"/v1/companies", "/v1/companies/:id"},
+							Enabled:   true,
+						},
+					},
+				)
+
+				failOpenRouter := gin.Default()
+				failOpenRouter.Use(middleware.DefaultMiddlewares(sessionAuthenticator)...)
+
+				failOpenRouterGroup := failOpenRouter.Group("/v1")
+				failOpenRouterGroup.Use(middleware.AccessPolicy(dB, accessPolicyService))
+				failOpenRouterGroup.Use(auth.AllowOnlyActiveUser(
+					dB,
+					sessionAuthenticator,
+					sessionService,
+				))
+
+				AddEndpoints(failOpenRouterGroup, dB, companyService)
+
+				headers := map[string]string{"X-Forwarded-For": "197.156.118.251"} // kenya ip address
+
+				w, err := utils.DoRequestWithHeaders(failOpenRouter, http.MethodPost, "/v1/companies", form, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusCreated)
+			})
+
+			Convey("can list companies from kenya", func() {
+
+				headers := map[string]string{"X-Forwarded-For": "197.156.118.251"} // kenya ip address
+
+				w, err := utils.DoRequestWithHeaders(policyRouter, http.MethodGet, "/v1/companies", nil, token, headers)
+				So(err, ShouldBeNil)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
 		Convey("unauthenticated endpoints", func() {
 
 			Convey("can create a company from cyprus unauthenticated", func() {
@@ -0,0 +1,177 @@
+package companies
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatXLSX   = "xlsx"
+
+	exportFlushInterval = 100
+)
+
+var (
+	exportContentTypes = map[string]string{
+		exportFormatCSV:    "text/csv; charset=utf-8",
+		exportFormatNDJSON: "application/x-ndjson",
+		exportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}
+
+	exportColumns = []string{"id", "name", "code", "country", "website", "phone", "operation_status", "created_at", "updated_at"}
+
+	// exportFreeTextColumns are the user supplied columns that are escaped
+	// in csv exports so spreadsheets do not evaluate them as formulas.
+	exportFreeTextColumns = []int{1, 2, 4}
+)
+
+type companyExporter interface {
+	Write(company *entities.Company) error
+	Flush() error
+	Close() error
+}
+
+func newCompanyExporter(
+	format string,
+	w io.Writer,
+) (companyExporter, error) {
+
+	switch format {
+	case exportFormatCSV:
+		return newCSVCompanyExporter(w)
+	case exportFormatNDJSON:
+		return &ndjsonCompanyExporter{encoder: json.NewEncoder(w)}, nil
+	case exportFormatXLSX:
+		return newXLSXCompanyExporter(w)
+	}
+
+	return nil, utils.NewErrorWithCode(
+		errors.New("unsupported export format"),
+		utils.ErrorCodeInvalidArgument,
+		"provided unsupported export format = [%v]",
+		format,
+	)
+}
+
+func exportFileName(
+	format string,
+	now time.Time,
+) string {
+	return fmt.Sprintf("companies-%v.%v", now.UTC().Format("20060102T150405Z"), format)
+}
+
+func exportRecord(
+	company *entities.Company,
+) []string {
+	return []string{
+		strconv.FormatInt(company.ID, 10),
+		company.Name,
+		company.Code,
+		company.Country,
+		company.Website,
+		company.Phone,
+		company.OperationStatus.String(),
+		company.CreatedAt.UTC().Format(time.RFC3339),
+		company.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+type csvCompanyExporter struct {
+	writer *csv.Writer
+}
+
+func newCSVCompanyExporter(
+	w io.Writer,
+) (*csvCompanyExporter, error) {
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write(exportColumns)
+	if err != nil {
+		return &csvCompanyExporter{}, err
+	}
+
+	return &csvCompanyExporter{writer: writer}, nil
+}
+
+func (e *csvCompanyExporter) Write(company *entities.Company) error {
+
+	record := exportRecord(company)
+
+	for _, i := range exportFreeTextColumns {
+		if strings.IndexAny(record[i], "=+-@") == 0 {
+			record[i] = "'" + record[i]
+		}
+	}
+
+	return e.writer.Write(record)
+}
+
+func (e *csvCompanyExporter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvCompanyExporter) Close() error {
+	return e.Flush()
+}
+
+type ndjsonCompanyExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonCompanyExporter) Write(company *entities.Company) error {
+	return e.encoder.Encode(company)
+}
+
+func (e *ndjsonCompanyExporter) Flush() error {
+	return nil
+}
+
+func (e *ndjsonCompanyExporter) Close() error {
+	return nil
+}
+
+type xlsxCompanyExporter struct {
+	writer *utils.XLSXWriter
+}
+
+func newXLSXCompanyExporter(
+	w io.Writer,
+) (*xlsxCompanyExporter, error) {
+
+	writer, err := utils.NewXLSXWriter(w)
+	if err != nil {
+		return &xlsxCompanyExporter{}, err
+	}
+
+	err = writer.Write(exportColumns)
+	if err != nil {
+		return &xlsxCompanyExporter{}, err
+	}
+
+	return &xlsxCompanyExporter{writer: writer}, nil
+}
+
+func (e *xlsxCompanyExporter) Write(company *entities.Company) error {
+	return e.writer.Write(exportRecord(company))
+}
+
+func (e *xlsxCompanyExporter) Flush() error {
+	return e.writer.Flush()
+}
+
+func (e *xlsxCompanyExporter) Close() error {
+	return e.writer.Close()
+}
@@ -1,11 +1,16 @@
 package companies
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/services"
 	"github.com/vonmutinda/organono/app/utils"
@@ -69,9 +74,20 @@ func deleteCompany(
 			return
 		}
 
+		expectedVersion, err := webutils.VersionFromIfMatch(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company version from headers",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
 		ctx := c.Request.Context()
 
-		company, err := companyService.DeleteCompany(ctx, dB, companyID)
+		company, err := companyService.DeleteCompany(ctx, dB, companyID, expectedVersion)
 		if err != nil {
 			wrappedError := utils.NewError(
 				err,
@@ -87,6 +103,121 @@ func deleteCompany(
 	}
 }
 
+func exportCompanies(
+	dB db.DB,
+	companyService services.CompanyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", exportFormatCSV)))
+
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			wrappedError := utils.NewErrorWithCode(
+				errors.New("unsupported export format"),
+				utils.ErrorCodeInvalidArgument,
+				"provided unsupported export format = [%v]",
+				format,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		filter, err := webutils.FilterFromContext(c)
+		if err != nil {
+			appError := utils.NewError(
+				err,
+				"Failed to parse filter params from context",
+			)
+
+			webutils.HandleError(c, appError)
+			return
+		}
+
+		var exporter companyExporter
+
+		// Headers are only written once the first row is ready so that query
+		// errors can still be reported as a regular JSON error response.
+		startExport := func() error {
+
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v"`, exportFileName(format, time.Now())))
+			c.Status(http.StatusOK)
+
+			newExporter, err := newCompanyExporter(format, c.Writer)
+			if err != nil {
+				return err
+			}
+
+			exporter = newExporter
+
+			return nil
+		}
+
+		ctx := c.Request.Context()
+
+		exported := 0
+
+		err = companyService.ExportCompanies(ctx, dB, filter, func(company *entities.Company) error {
+
+			if exporter == nil {
+				err := startExport()
+				if err != nil {
+					return err
+				}
+			}
+
+			err := exporter.Write(company)
+			if err != nil {
+				return err
+			}
+
+			exported++
+
+			if exported%exportFlushInterval == 0 {
+				err = exporter.Flush()
+				if err != nil {
+					return err
+				}
+
+				c.Writer.Flush()
+			}
+
+			return nil
+		})
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to export companies format = %v after %v rows",
+				format,
+				exported,
+			)
+
+			if exporter == nil {
+				webutils.HandleError(c, wrappedError)
+				return
+			}
+
+			wrappedError.LogErrorMessages()
+			return
+		}
+
+		if exporter == nil {
+			err = startExport()
+			if err != nil {
+				utils.NewError(err, "Failed to start company export format = %v", format).LogErrorMessages()
+				return
+			}
+		}
+
+		err = exporter.Close()
+		if err != nil {
+			utils.NewError(err, "Failed to finish company export format = %v", format).LogErrorMessages()
+		}
+	}
+}
+
 func getCompany(
 	dB db.DB,
 	companyService services.CompanyService,
@@ -120,10 +251,55 @@ func getCompany(
 			return
 		}
 
+		if company.ID != companyID {
+			location := strings.TrimSuffix(c.Request.URL.Path, c.Param("id")) + strconv.FormatInt(company.ID, 10)
+			c.Redirect(http.StatusMovedPermanently, location)
+			return
+		}
+
+		webutils.SetETag(c, company.Version)
+
 		c.JSON(http.StatusOK, company)
 	}
 }
 
+func importCompanies(
+	dB db.DB,
+	companyService services.CompanyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		form, err := webutils.CompanyImportFormFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company import from request",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		report, err := companyService.ImportCompanies(ctx, dB, form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to import %v companies dry run = %v mode = %v",
+				len(form.Companies),
+				form.DryRun,
+				form.Mode,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
 func listCompanies(
 	dB db.DB,
 	companyService services.CompanyService,
@@ -158,6 +334,126 @@ func listCompanies(
 	}
 }
 
+func listSubsidiaries(
+	dB db.DB,
+	companyService services.CompanyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		filter, err := webutils.FilterFromContext(c)
+		if err != nil {
+			appError := utils.NewError(
+				err,
+				"Failed to parse filter params from context",
+			)
+
+			webutils.HandleError(c, appError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		companyList, err := companyService.ListSubsidiaries(ctx, dB, companyID, filter)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to fetch subsidiaries of company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, companyList)
+	}
+}
+
+func restoreCompany(
+	dB db.DB,
+	companyService services.CompanyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		company, err := companyService.RestoreCompany(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to restore company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, company)
+	}
+}
+
+func searchCompanies(
+	dB db.DB,
+	companyService services.CompanyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		filter, err := webutils.SearchFilterFromContext(c)
+		if err != nil {
+			appError := utils.NewError(
+				err,
+				"Failed to parse search params from context",
+			)
+
+			webutils.HandleError(c, appError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		resultList, err := companyService.SearchCompanies(ctx, dB, filter)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to search companies q = [%v]",
+				filter.Query,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, resultList)
+	}
+}
+
 func updateCompany(
 	dB db.DB,
 	companyService services.CompanyService,
@@ -191,6 +487,21 @@ func updateCompany(
 			return
 		}
 
+		expectedVersion, err := webutils.VersionFromIfMatch(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company version from headers",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		if expectedVersion.Valid {
+			form.Version = expectedVersion
+		}
+
 		ctx := c.Request.Context()
 
 		company, err := companyService.UpdateCompany(ctx, dB, companyID, &form)
@@ -206,6 +517,8 @@ func updateCompany(
 			return
 		}
 
+		webutils.SetETag(c, company.Version)
+
 		c.JSON(http.StatusOK, company)
 	}
 }
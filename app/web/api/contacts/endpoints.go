@@ -0,0 +1,21 @@
+package contacts
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) {
+	r.POST("/companies/:id/contacts", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), addCompanyContact(dB, companyContactService))
+	r.GET("/companies/:id/contacts", listCompanyContacts(dB, companyContactService))
+	r.GET("/companies/:id/contacts/:contact_id", getCompanyContact(dB, companyContactService))
+	r.PUT("/companies/:id/contacts/:contact_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), updateCompanyContact(dB, companyContactService))
+	r.DELETE("/companies/:id/contacts/:contact_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), removeCompanyContact(dB, companyContactService))
+}
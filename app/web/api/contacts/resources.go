@@ -0,0 +1,252 @@
+package contacts
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func addCompanyContact(
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CreateCompanyContactForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create company contact form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		contact, err := companyContactService.AddCompanyContact(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to add contact to company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, contact)
+	}
+}
+
+func getCompanyContact(
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, contactID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company contact path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		contact, err := companyContactService.GetCompanyContact(ctx, dB, companyID, contactID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to get contact id = %v for company id = %v",
+				contactID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, contact)
+	}
+}
+
+func listCompanyContacts(
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		contacts, err := companyContactService.ListCompanyContacts(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list contacts for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, contacts)
+	}
+}
+
+func removeCompanyContact(
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, contactID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company contact path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		contact, err := companyContactService.RemoveCompanyContact(ctx, dB, companyID, contactID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to remove contact id = %v from company id = %v",
+				contactID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, contact)
+	}
+}
+
+func updateCompanyContact(
+	dB db.DB,
+	companyContactService services.CompanyContactService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, contactID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company contact path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.UpdateCompanyContactForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind update company contact form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		contact, err := companyContactService.UpdateCompanyContact(ctx, dB, companyID, contactID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to update contact id = %v for company id = %v form = [%+v]",
+				contactID,
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, contact)
+	}
+}
+
+func idsFromContext(
+	c *gin.Context,
+) (int64, int64, error) {
+
+	companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse company id = %v",
+			c.Param("id"),
+		)
+	}
+
+	contactID, err := strconv.ParseInt(c.Param("contact_id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse contact id = %v",
+			c.Param("contact_id"),
+		)
+	}
+
+	return companyID, contactID, nil
+}
@@ -0,0 +1,20 @@
+package countries
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyCountryService services.CompanyCountryService,
+) {
+	r.POST("/companies/:id/countries", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), addCompanyCountry(dB, companyCountryService))
+	r.GET("/companies/:id/countries", listCompanyCountries(dB, companyCountryService))
+	r.PUT("/companies/:id/countries/:country_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), updateCompanyCountry(dB, companyCountryService))
+	r.DELETE("/companies/:id/countries/:country_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), removeCompanyCountry(dB, companyCountryService))
+}
@@ -0,0 +1,216 @@
+package countries
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func addCompanyCountry(
+	dB db.DB,
+	companyCountryService services.CompanyCountryService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CreateCompanyCountryForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create company country form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		companyCountry, err := companyCountryService.AddCompanyCountry(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to add country to company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, companyCountry)
+	}
+}
+
+func listCompanyCountries(
+	dB db.DB,
+	companyCountryService services.CompanyCountryService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		companyCountries, err := companyCountryService.ListCompanyCountries(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list countries for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, companyCountries)
+	}
+}
+
+func removeCompanyCountry(
+	dB db.DB,
+	companyCountryService services.CompanyCountryService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, countryID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company country path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		companyCountry, err := companyCountryService.RemoveCompanyCountry(ctx, dB, companyID, countryID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to remove country id = %v from company id = %v",
+				countryID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, companyCountry)
+	}
+}
+
+func updateCompanyCountry(
+	dB db.DB,
+	companyCountryService services.CompanyCountryService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, countryID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company country path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.UpdateCompanyCountryForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind update company country form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		companyCountry, err := companyCountryService.UpdateCompanyCountry(ctx, dB, companyID, countryID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to update country id = %v for company id = %v form = [%+v]",
+				countryID,
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, companyCountry)
+	}
+}
+
+func idsFromContext(
+	c *gin.Context,
+) (int64, int64, error) {
+
+	companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse company id = %v",
+			c.Param("id"),
+		)
+	}
+
+	countryID, err := strconv.ParseInt(c.Param("country_id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse country id = %v",
+			c.Param("country_id"),
+		)
+	}
+
+	return companyID, countryID, nil
+}
@@ -0,0 +1,21 @@
+package customfields
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) {
+	r.POST("/custom_fields", auth.AllowOnlyWithPermission(entities.PermissionCustomFieldsManage), createCustomFieldDefinition(dB, customFieldService))
+	r.GET("/custom_fields", listCustomFieldDefinitions(dB, customFieldService))
+	r.GET("/custom_fields/schema", customFieldSchema(dB, customFieldService))
+	r.PUT("/custom_fields/:id", auth.AllowOnlyWithPermission(entities.PermissionCustomFieldsManage), updateCustomFieldDefinition(dB, customFieldService))
+	r.DELETE("/custom_fields/:id", auth.AllowOnlyWithPermission(entities.PermissionCustomFieldsManage), deleteCustomFieldDefinition(dB, customFieldService))
+}
@@ -0,0 +1,186 @@
+package customfields
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func createCustomFieldDefinition(
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var form forms.CreateCustomFieldDefinitionForm
+
+		err := c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create custom field form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		definition, err := customFieldService.CreateCustomFieldDefinition(ctx, dB, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to create custom field form = [%+v]",
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, definition)
+	}
+}
+
+func customFieldSchema(
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		schema, err := customFieldService.CustomFieldSchema(ctx, dB)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to build custom field schema",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, schema)
+	}
+}
+
+func deleteCustomFieldDefinition(
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		definitionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse custom field id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		definition, err := customFieldService.DeleteCustomFieldDefinition(ctx, dB, definitionID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to delete custom field id = %v",
+				definitionID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, definition)
+	}
+}
+
+func listCustomFieldDefinitions(
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		definitions, err := customFieldService.ListCustomFieldDefinitions(ctx, dB)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list custom fields",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, definitions)
+	}
+}
+
+func updateCustomFieldDefinition(
+	dB db.DB,
+	customFieldService services.CustomFieldService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		definitionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse custom field id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.UpdateCustomFieldDefinitionForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind update custom field form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		definition, err := customFieldService.UpdateCustomFieldDefinition(ctx, dB, definitionID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to update custom field id = %v form = [%+v]",
+				definitionID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, definition)
+	}
+}
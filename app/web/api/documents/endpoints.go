@@ -0,0 +1,20 @@
+package documents
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyDocumentService services.CompanyDocumentService,
+) {
+	r.POST("/companies/:id/documents", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), uploadDocument(dB, companyDocumentService))
+	r.GET("/companies/:id/documents", listDocuments(dB, companyDocumentService))
+	r.GET("/companies/:id/documents/:document_id", downloadDocument(dB, companyDocumentService))
+	r.DELETE("/companies/:id/documents/:document_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), deleteDocument(dB, companyDocumentService))
+}
@@ -0,0 +1,257 @@
+package documents
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+const (
+	documentFormField = "file"
+	// maxMultipartOverhead leaves room for the multipart boundaries and
+	// headers around the uploaded file.
+	maxMultipartOverhead = 1 << 20
+)
+
+func deleteDocument(
+	dB db.DB,
+	companyDocumentService services.CompanyDocumentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, documentID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company document path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		document, err := companyDocumentService.DeleteDocument(ctx, dB, companyID, documentID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to delete document id = %v from company id = %v",
+				documentID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, document)
+	}
+}
+
+func downloadDocument(
+	dB db.DB,
+	companyDocumentService services.CompanyDocumentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, documentID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company document path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		document, contents, err := companyDocumentService.OpenDocument(ctx, dB, companyID, documentID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to open document id = %v for company id = %v",
+				documentID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		defer contents.Close()
+
+		headers := map[string]string{
+			"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}),
+			"ETag":                strconv.Quote(document.SHA256),
+		}
+
+		checksum, err := hex.DecodeString(document.SHA256)
+		if err == nil {
+			headers["Digest"] = "sha-256=" + base64.StdEncoding.EncodeToString(checksum)
+		}
+
+		c.DataFromReader(http.StatusOK, document.SizeBytes, document.ContentType, contents, headers)
+	}
+}
+
+func listDocuments(
+	dB db.DB,
+	companyDocumentService services.CompanyDocumentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		documents, err := companyDocumentService.ListDocuments(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list documents for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, documents)
+	}
+}
+
+func uploadDocument(
+	dB db.DB,
+	companyDocumentService services.CompanyDocumentService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxDocumentSize+maxMultipartOverhead)
+
+		fileHeader, err := c.FormFile(documentFormField)
+		if err != nil {
+			errorCode := utils.ErrorCodeInvalidForm
+			if strings.Contains(err.Error(), "request body too large") {
+				errorCode = utils.ErrorCodeDocumentTooLarge
+			}
+
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				errorCode,
+				"Failed to read multipart field = [%v]",
+				documentFormField,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		if fileHeader.Size > services.MaxDocumentSize {
+			wrappedError := utils.NewErrorWithCode(
+				errors.New("document too large"),
+				utils.ErrorCodeDocumentTooLarge,
+				"Uploaded document of %v bytes exceeds %v bytes",
+				fileHeader.Size,
+				services.MaxDocumentSize,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to open uploaded document",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		defer file.Close()
+
+		ctx := c.Request.Context()
+
+		document, err := companyDocumentService.UploadDocument(ctx, dB, companyID, fileHeader.Filename, file)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to upload document for company id = %v file name = [%v]",
+				companyID,
+				fileHeader.Filename,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, document)
+	}
+}
+
+func idsFromContext(
+	c *gin.Context,
+) (int64, int64, error) {
+
+	companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse company id = %v",
+			c.Param("id"),
+		)
+	}
+
+	documentID, err := strconv.ParseInt(c.Param("document_id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse document id = %v",
+			c.Param("document_id"),
+		)
+	}
+
+	return companyID, documentID, nil
+}
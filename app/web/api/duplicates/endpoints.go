@@ -0,0 +1,18 @@
+package duplicates
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyMergeService services.CompanyMergeService,
+) {
+	r.GET("/companies/:id/duplicates", findDuplicateCompanies(dB, companyMergeService))
+	r.POST("/companies/:id/merge", auth.AllowOnlyWithPermission(entities.PermissionCompaniesDelete), mergeCompanies(dB, companyMergeService))
+}
@@ -0,0 +1,104 @@
+package duplicates
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func findDuplicateCompanies(
+	dB db.DB,
+	companyMergeService services.CompanyMergeService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		duplicates, err := companyMergeService.FindDuplicateCompanies(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to find duplicates of company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"duplicates": duplicates})
+	}
+}
+
+func mergeCompanies(
+	dB db.DB,
+	companyMergeService services.CompanyMergeService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.MergeCompanyForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind merge company form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		company, err := companyMergeService.MergeCompanies(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to merge company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		webutils.SetETag(c, company.Version)
+
+		c.JSON(http.StatusOK, company)
+	}
+}
@@ -0,0 +1,18 @@
+package hierarchy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyHierarchyService services.CompanyHierarchyService,
+) {
+	r.PUT("/companies/:id/parent", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), setParentCompany(dB, companyHierarchyService))
+	r.GET("/companies/:id/tree", getCompanyTree(dB, companyHierarchyService))
+}
@@ -0,0 +1,115 @@
+package hierarchy
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func getCompanyTree(
+	dB db.DB,
+	companyHierarchyService services.CompanyHierarchyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tree, err := companyHierarchyService.GetCompanyTree(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to get tree of company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tree)
+	}
+}
+
+func setParentCompany(
+	dB db.DB,
+	companyHierarchyService services.CompanyHierarchyService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CompanyParentForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind company parent form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		expectedVersion, err := webutils.VersionFromIfMatch(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company version from headers",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		company, err := companyHierarchyService.SetParentCompany(ctx, dB, companyID, &form, expectedVersion)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to set parent of company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		webutils.SetETag(c, company.Version)
+
+		c.JSON(http.StatusOK, company)
+	}
+}
@@ -0,0 +1,19 @@
+package identifiers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyIdentifierService services.CompanyIdentifierService,
+) {
+	r.POST("/companies/:id/identifiers", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), addCompanyIdentifier(dB, companyIdentifierService))
+	r.GET("/companies/:id/identifiers", listCompanyIdentifiers(dB, companyIdentifierService))
+	r.DELETE("/companies/:id/identifiers/:identifier_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), removeCompanyIdentifier(dB, companyIdentifierService))
+}
@@ -0,0 +1,165 @@
+package identifiers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func addCompanyIdentifier(
+	dB db.DB,
+	companyIdentifierService services.CompanyIdentifierService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CreateCompanyIdentifierForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create company identifier form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		identifier, err := companyIdentifierService.AddCompanyIdentifier(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to add identifier to company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, identifier)
+	}
+}
+
+func listCompanyIdentifiers(
+	dB db.DB,
+	companyIdentifierService services.CompanyIdentifierService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		identifiers, err := companyIdentifierService.ListCompanyIdentifiers(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list identifiers for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, identifiers)
+	}
+}
+
+func removeCompanyIdentifier(
+	dB db.DB,
+	companyIdentifierService services.CompanyIdentifierService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, identifierID, err := idsFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse company identifier path params",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		identifier, err := companyIdentifierService.RemoveCompanyIdentifier(ctx, dB, companyID, identifierID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to remove identifier id = %v from company id = %v",
+				identifierID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, identifier)
+	}
+}
+
+func idsFromContext(
+	c *gin.Context,
+) (int64, int64, error) {
+
+	companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse company id = %v",
+			c.Param("id"),
+		)
+	}
+
+	identifierID, err := strconv.ParseInt(c.Param("identifier_id"), 10, 64)
+	if err != nil {
+		return 0, 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"Failed to parse identifier id = %v",
+			c.Param("identifier_id"),
+		)
+	}
+
+	return companyID, identifierID, nil
+}
@@ -0,0 +1,19 @@
+package lifecycle
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	companyLifecycleService services.CompanyLifecycleService,
+) {
+	r.POST("/companies/:id/activate", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), activateCompany(dB, companyLifecycleService))
+	r.POST("/companies/:id/close", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), closeCompany(dB, companyLifecycleService))
+	r.POST("/companies/:id/reopen", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), reopenCompany(dB, companyLifecycleService))
+}
@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+type transitionFunc func(ctx context.Context, dB db.DB, companyID int64, form *forms.CompanyStatusForm) (*entities.Company, error)
+
+func activateCompany(
+	dB db.DB,
+	companyLifecycleService services.CompanyLifecycleService,
+) func(c *gin.Context) {
+	return transitionCompany(dB, "activate", companyLifecycleService.ActivateCompany)
+}
+
+func closeCompany(
+	dB db.DB,
+	companyLifecycleService services.CompanyLifecycleService,
+) func(c *gin.Context) {
+	return transitionCompany(dB, "close", companyLifecycleService.CloseCompany)
+}
+
+func reopenCompany(
+	dB db.DB,
+	companyLifecycleService services.CompanyLifecycleService,
+) func(c *gin.Context) {
+	return transitionCompany(dB, "reopen", companyLifecycleService.ReopenCompany)
+}
+
+func transitionCompany(
+	dB db.DB,
+	action string,
+	transition transitionFunc,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CompanyStatusForm
+
+		if c.Request.ContentLength != 0 {
+			err = c.BindJSON(&form)
+			if err != nil {
+				wrappedError := utils.NewErrorWithCode(
+					err,
+					utils.ErrorCodeInvalidForm,
+					"Failed to bind company status form",
+				)
+
+				webutils.HandleError(c, wrappedError)
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+
+		company, err := transition(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to %v company id = %v form = [%+v]",
+				action,
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, company)
+	}
+}
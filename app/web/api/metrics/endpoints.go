@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	ipAPIProvider *providers.AppCachedIPAPI,
+) {
+	r.GET("/metrics/ipapi", auth.AllowOnlyWithPermission(entities.PermissionMetricsRead), getIPAPIStats(ipAPIProvider))
+}
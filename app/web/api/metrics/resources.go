@@ -0,0 +1,16 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/providers"
+)
+
+func getIPAPIStats(
+	ipAPIProvider *providers.AppCachedIPAPI,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, ipAPIProvider.Stats())
+	}
+}
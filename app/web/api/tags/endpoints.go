@@ -0,0 +1,23 @@
+package tags
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	tagService services.TagService,
+) {
+	r.POST("/tags", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), createTag(dB, tagService))
+	r.GET("/tags", listTags(dB, tagService))
+	r.DELETE("/tags/:id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), deleteTag(dB, tagService))
+
+	r.POST("/companies/:id/tags", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), attachCompanyTag(dB, tagService))
+	r.GET("/companies/:id/tags", listCompanyTags(dB, tagService))
+	r.DELETE("/companies/:id/tags/:tag_id", auth.AllowOnlyWithPermission(entities.PermissionCompaniesWrite), detachCompanyTag(dB, tagService))
+}
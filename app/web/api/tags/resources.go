@@ -0,0 +1,251 @@
+package tags
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func attachCompanyTag(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.CompanyTagForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind company tag form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tags, err := tagService.AttachCompanyTag(ctx, dB, companyID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to attach tag to company id = %v form = [%+v]",
+				companyID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tags)
+	}
+}
+
+func createTag(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var form forms.CreateTagForm
+
+		err := c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create tag form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tag, err := tagService.CreateTag(ctx, dB, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to create tag form = [%+v]",
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, tag)
+	}
+}
+
+func deleteTag(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse tag id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tag, err := tagService.DeleteTag(ctx, dB, tagID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to delete tag id = %v",
+				tagID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tag)
+	}
+}
+
+func detachCompanyTag(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		tagID, err := strconv.ParseInt(c.Param("tag_id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse tag id = %v",
+				c.Param("tag_id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tags, err := tagService.DetachCompanyTag(ctx, dB, companyID, tagID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to detach tag id = %v from company id = %v",
+				tagID,
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tags)
+	}
+}
+
+func listCompanyTags(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		companyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse company id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		tags, err := tagService.ListCompanyTags(ctx, dB, companyID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list tags for company id = %v",
+				companyID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tags)
+	}
+}
+
+func listTags(
+	dB db.DB,
+	tagService services.TagService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		tags, err := tagService.ListTags(ctx, dB)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list tags",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, tags)
+	}
+}
@@ -0,0 +1,23 @@
+package users
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/auth"
+)
+
+func AddEndpoints(
+	r *gin.RouterGroup,
+	dB db.DB,
+	userService services.UserService,
+) {
+	r.POST("/users", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), createUser(dB, userService))
+	r.GET("/users", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), listUsers(dB, userService))
+	r.GET("/users/:id", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), getUser(dB, userService))
+	r.PUT("/users/:id", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), updateUser(dB, userService))
+	r.DELETE("/users/:id", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), deactivateUser(dB, userService))
+	r.PUT("/users/:id/role", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), assignRole(dB, userService))
+	r.GET("/roles", auth.AllowOnlyWithPermission(entities.PermissionUsersManage), listRoles())
+}
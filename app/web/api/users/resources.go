@@ -0,0 +1,270 @@
+package users
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/webutils"
+)
+
+func assignRole(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse user id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.AssignRoleForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind assign role form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, err := userService.AssignRole(ctx, dB, userID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to assign role = [%v] to user id = %v",
+				form.Role,
+				userID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func createUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		var form forms.CreateUserForm
+
+		err := c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind create user form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, err := userService.CreateUser(ctx, dB, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to create user username = [%v]",
+				form.Username,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+func deactivateUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse user id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, err := userService.DeactivateUser(ctx, dB, userID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to deactivate user id = %v",
+				userID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func getUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse user id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, err := userService.GetUser(ctx, dB, userID)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to get user id = %v",
+				userID,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func listUsers(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		filter, err := webutils.UserFilterFromContext(c)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to parse user filter params from context",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		userList, err := userService.ListUsers(ctx, dB, filter)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to list users",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, userList)
+	}
+}
+
+func listRoles() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, entities.Roles())
+	}
+}
+
+func updateUser(
+	dB db.DB,
+	userService services.UserService,
+) func(c *gin.Context) {
+	return func(c *gin.Context) {
+
+		userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"Failed to parse user id = %v",
+				c.Param("id"),
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		var form forms.UpdateUserForm
+
+		err = c.BindJSON(&form)
+		if err != nil {
+			wrappedError := utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidForm,
+				"Failed to bind update user form",
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		user, err := userService.UpdateUser(ctx, dB, userID, &form)
+		if err != nil {
+			wrappedError := utils.NewError(
+				err,
+				"Failed to update user id = %v form = [%+v]",
+				userID,
+				form,
+			)
+
+			webutils.HandleError(c, wrappedError)
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
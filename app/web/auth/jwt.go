@@ -49,6 +49,7 @@ func (h *AppJWTHandler) CreateUserToken(
 
 	claims["exp"] = time.Now().AddDate(1, 0, 0).Unix()
 	claims["refresh"] = time.Now().Add(time.Hour).Unix()
+	claims["role"] = user.Role.String()
 	claims["session_id"] = session.ID
 	claims["status"] = user.Status.String()
 	claims["user_id"] = user.ID
@@ -72,6 +73,7 @@ func (h *AppJWTHandler) TokenInfo(tokenValue string) (*entities.TokenInfo, error
 
 	exp := h.getInt64(mapClaims, "exp")
 	refresh := h.getInt64(mapClaims, "refresh")
+	role := h.getString(mapClaims, "role")
 	sessionID := h.getInt64(mapClaims, "session_id")
 	status := h.getString(mapClaims, "status")
 	userID := h.getInt64(mapClaims, "user_id")
@@ -79,6 +81,7 @@ func (h *AppJWTHandler) TokenInfo(tokenValue string) (*entities.TokenInfo, error
 	tokenInfo := &entities.TokenInfo{
 		Exp:       time.Unix(exp, 0),
 		Refresh:   time.Unix(refresh, 0),
+		Role:      entities.Role(role),
 		SessionID: sessionID,
 		Status:    status,
 		UserID:    userID,
@@ -1,13 +1,12 @@
 package auth
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/services"
 	"github.com/vonmutinda/organono/app/utils"
 	"github.com/vonmutinda/organono/app/web/ctxhelper"
@@ -23,21 +22,11 @@ func AllowOnlyActiveUser(
 
 		ctx := c.Request.Context()
 
-		err := validateCyprusIPAddress(c, sessionAuthenticator)
-		if err != nil {
-			wrappedError := utils.NewError(
-				err,
-				"Failed to check is cyprus ip address",
-			).Notify()
-
-			wrappedError.WithContext(c.Request.Context())
-			wrappedError.LogErrorMessages()
-			c.JSON(wrappedError.HttpStatus(), wrappedError.JsonResponse())
-			c.Abort()
+		if skipsAuthentication(ctx) {
 			return
 		}
 
-		err = validateSession(c, dB, sessionAuthenticator, sessionService)
+		err := validateSession(c, dB, sessionAuthenticator, sessionService)
 		if err != nil {
 			wrappedError := utils.NewError(
 				err,
@@ -71,39 +60,43 @@ func AllowOnlyActiveUser(
 	}
 }
 
-func validateCyprusIPAddress(
-	c *gin.Context,
-	sessionAuthenticator SessionAuthenticator,
-) error {
-
-	isCyprus, err := sessionAuthenticator.IsCyrpusIPAddress(c.Request.Context())
-	if err != nil {
-		return err
-	}
+// AllowOnlyWithPermission lets the request through only when the role in the
+// session token grants permission. It must run after AllowOnlyActiveUser.
+func AllowOnlyWithPermission(
+	permission entities.Permission,
+) func(c *gin.Context) {
 
-	if !isCyprus {
-		return nil
-	}
+	return func(c *gin.Context) {
 
-	exemptedMethodURLMap := map[string]string{
-		http.MethodPost:   "/v1/companies",
-		http.MethodDelete: "/v1/company/%v",
-	}
+		if skipsAuthentication(c.Request.Context()) {
+			return
+		}
 
-	url, ok := exemptedMethodURLMap[c.Request.Method]
-	if !ok {
-		return nil
-	}
+		tokenInfo := ctxhelper.TokenInfo(c.Request.Context())
 
-	if strings.Contains(url, "%v") {
-		url = fmt.Sprintf(url, c.Param("id"))
-	}
+		if !tokenInfo.HasPermission(permission) {
+			wrappedError := utils.NewErrorWithCode(
+				errors.New("permission denied"),
+				utils.ErrorCodeRoleForbidden,
+				"Failed to check permission = [%v] for user = [%v] with role = [%v]",
+				permission,
+				tokenInfo.UserID,
+				tokenInfo.Role,
+			)
 
-	if url == c.Request.URL.Path {
-		c.Next()
+			wrappedError.WithContext(c.Request.Context())
+			wrappedError.LogErrorMessages()
+			c.JSON(wrappedError.HttpStatus(), wrappedError.JsonResponse())
+			c.Abort()
+			return
+		}
 	}
+}
 
-	return nil
+// skipsAuthentication reports whether the access policy lets a request
+// without a session token through. Requests with a token are still validated.
+func skipsAuthentication(ctx context.Context) bool {
+	return ctxhelper.SkipAuthentication(ctx) && ctxhelper.TokenInfo(ctx).SessionID == 0
 }
 
 func validateSession(
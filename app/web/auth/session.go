@@ -4,15 +4,12 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/vonmutinda/organono/app/db"
 	"github.com/vonmutinda/organono/app/entities"
-	"github.com/vonmutinda/organono/app/providers"
 	"github.com/vonmutinda/organono/app/repos"
 	"github.com/vonmutinda/organono/app/utils"
-	"github.com/vonmutinda/organono/app/web/ctxhelper"
 )
 
 const (
@@ -22,7 +19,6 @@ const (
 var ErrTokenNotProvided = errors.New("token not provided")
 
 type SessionAuthenticator interface {
-	IsCyrpusIPAddress(ctx context.Context) (bool, error)
 	RefreshTokenFromRequest(ctx context.Context, dB db.DB, tokenInfo *entities.TokenInfo, w http.ResponseWriter) (string, error)
 	SetUserSessionInResponse(w http.ResponseWriter, user *entities.User, session *entities.Session) (string, error)
 	TokenInfoFromRequest(req *http.Request) (*entities.TokenInfo, error)
@@ -30,19 +26,16 @@ type SessionAuthenticator interface {
 }
 
 type AppSessionAuthenticator struct {
-	ipAPIProvider     providers.IPAPI
 	jwtHandler        JWTHandler
 	sessionRepository repos.SessionRepository
 	userRepository    repos.UserRepository
 }
 
 func NewSessionAuthenticator(
-	ipAPIProvider providers.IPAPI,
 	sessionRepository repos.SessionRepository,
 	userRepository repos.UserRepository,
 ) SessionAuthenticator {
 	return NewSessionAuthenticatorWithJWTHandler(
-		ipAPIProvider,
 		NewJWTHandler(),
 		sessionRepository,
 		userRepository,
@@ -50,41 +43,17 @@ func NewSessionAuthenticator(
 }
 
 func NewSessionAuthenticatorWithJWTHandler(
-	ipAPIProvider providers.IPAPI,
 	jwtHandler JWTHandler,
 	sessionRepository repos.SessionRepository,
 	userRepository repos.UserRepository,
 ) SessionAuthenticator {
 	return &AppSessionAuthenticator{
-		ipAPIProvider:     ipAPIProvider,
 		jwtHandler:        jwtHandler,
 		sessionRepository: sessionRepository,
 		userRepository:    userRepository,
 	}
 }
 
-func (a *AppSessionAuthenticator) IsCyrpusIPAddress(
-	ctx context.Context,
-) (bool, error) {
-
-	lookupIPAddress := ctxhelper.IPAddress(ctx)
-
-	if lookupIPAddress == "" {
-		return false, nil
-	}
-
-	countryWithIp, err := a.ipAPIProvider.CountryForIP(lookupIPAddress)
-	if err != nil {
-		return false, err
-	}
-
-	if strings.EqualFold(countryWithIp.CountryName, "Cyprus") {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func (a *AppSessionAuthenticator) RefreshTokenFromRequest(
 	ctx context.Context,
 	dB db.DB,
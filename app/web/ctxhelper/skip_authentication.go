@@ -0,0 +1,20 @@
+package ctxhelper
+
+import (
+	"context"
+
+	"github.com/vonmutinda/organono/app/entities"
+)
+
+func SkipAuthentication(ctx context.Context) bool {
+	existing := ctx.Value(entities.ContextKeySkipAuthentication)
+	if existing == nil {
+		return false
+	}
+
+	return existing.(bool)
+}
+
+func WithSkipAuthentication(ctx context.Context) context.Context {
+	return context.WithValue(ctx, entities.ContextKeySkipAuthentication, true)
+}
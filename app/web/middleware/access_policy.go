@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+)
+
+// AccessPolicy rejects requests denied by the access policy, matching rules
+// against the route template rather than the request path. In dry run mode
+// denied requests are only logged. Allowed requests that may skip
+// authentication are marked in the request context.
+func AccessPolicy(
+	dB db.DB,
+	accessPolicyService services.AccessPolicyService,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		ctx := c.Request.Context()
+
+		decision, err := accessPolicyService.Evaluate(ctx, dB, c.Request.Method, c.FullPath())
+		if err != nil && accessPolicyService.Mode() == entities.AccessPolicyModeDryRun {
+			logger.Warnf("Failed to evaluate access policy for %v %v err = %v", c.Request.Method, c.FullPath(), err)
+			c.Next()
+			return
+		}
+
+		if err != nil {
+			abortWithError(c, utils.NewError(
+				err,
+				"Failed to evaluate access policy for %v %v",
+				c.Request.Method,
+				c.FullPath(),
+			))
+			return
+		}
+
+		if decision.Allowed {
+			if decision.SkipAuthentication {
+				c.Request = c.Request.WithContext(ctxhelper.WithSkipAuthentication(ctx))
+			}
+
+			c.Next()
+			return
+		}
+
+		if accessPolicyService.Mode() == entities.AccessPolicyModeDryRun {
+			logger.Warnf(
+				"Access policy rule = [%v] would deny %v %v from country = [%v]",
+				decision.Rule.Name,
+				c.Request.Method,
+				c.FullPath(),
+				decision.Country,
+			)
+
+			c.Next()
+			return
+		}
+
+		abortWithError(c, utils.NewErrorWithCode(
+			errors.New("denied by access policy"),
+			utils.ErrorCodeAccessDenied,
+			"Access policy rule = [%v] denied %v %v from country = [%v]",
+			decision.Rule.Name,
+			c.Request.Method,
+			c.FullPath(),
+			decision.Country,
+		))
+	}
+}
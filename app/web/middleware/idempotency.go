@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+	"github.com/vonmutinda/organono/app/web/ctxhelper"
+	"github.com/vonmutinda/organono/app/web/webutils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	idempotencyKeyHeaderKey     = "Idempotency-Key"
+	idempotentReplayedHeaderKey = "Idempotent-Replayed"
+	idempotencyKeyWriteTimeout  = 5 * time.Second
+	maxIdempotencyKeyLength     = 255
+)
+
+var idempotentMethods = map[string]bool{
+	http.MethodDelete: true,
+	http.MethodPatch:  true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+}
+
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response of a mutating request that is
+// retried with the same Idempotency-Key header within ttl. Keys are scoped
+// per user and rejected when reused for a different request.
+func Idempotency(
+	dB db.DB,
+	idempotencyKeyRepository repos.IdempotencyKeyRepository,
+	ttl time.Duration,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		key := strings.TrimSpace(c.GetHeader(idempotencyKeyHeaderKey))
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, utils.NewErrorWithCode(
+				errors.New("idempotency key too long"),
+				utils.ErrorCodeInvalidArgument,
+				"idempotency key exceeds %v characters",
+				maxIdempotencyKeyLength,
+			))
+			return
+		}
+
+		requestHash, err := requestFingerprint(c)
+		if err != nil {
+			abortWithError(c, utils.NewError(
+				err,
+				"Failed to fingerprint request for idempotency key = [%v]",
+				key,
+			))
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		idempotencyKey := &entities.IdempotencyKey{
+			ExpiresAt:   time.Now().Add(ttl),
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: requestHash,
+			UserID:      ctxhelper.UserID(ctx),
+		}
+
+		claimed, err := idempotencyKeyRepository.ClaimIdempotencyKey(ctx, dB, idempotencyKey)
+		if err != nil {
+			abortWithError(c, utils.NewError(
+				err,
+				"Failed to claim idempotency key = [%v]",
+				key,
+			))
+			return
+		}
+
+		if !claimed {
+			replayIdempotentResponse(c, dB, idempotencyKeyRepository, idempotencyKey)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = writer
+
+		// The key is released unless a response is saved, including when the
+		// handler panics, so that the client can safely retry.
+		completed := false
+		defer func() {
+			if !completed {
+				releaseIdempotencyKey(dB, idempotencyKeyRepository, idempotencyKey)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not remembered so that the client can safely retry.
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		idempotencyKey.ResponseStatus = null.IntFrom(int64(writer.Status()))
+		idempotencyKey.ResponseContentType = writer.Header().Get("Content-Type")
+		idempotencyKey.ResponseBody = writer.body.Bytes()
+
+		saveCtx, cancel := context.WithTimeout(context.Background(), idempotencyKeyWriteTimeout)
+		defer cancel()
+
+		err = idempotencyKeyRepository.SaveResponse(saveCtx, dB, idempotencyKey)
+		if err != nil {
+			logger.Errorf("Failed to save response for idempotency key = [%v] err = %v", key, err)
+			return
+		}
+
+		completed = true
+	}
+}
+
+// releaseIdempotencyKey deletes a claimed key. It does not use the request
+// context, which is cancelled once the client disconnects.
+func releaseIdempotencyKey(
+	dB db.DB,
+	idempotencyKeyRepository repos.IdempotencyKeyRepository,
+	idempotencyKey *entities.IdempotencyKey,
+) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyKeyWriteTimeout)
+	defer cancel()
+
+	err := idempotencyKeyRepository.DeleteIdempotencyKey(ctx, dB, idempotencyKey.ID)
+	if err != nil {
+		logger.Errorf("Failed to release idempotency key = [%v] err = %v", idempotencyKey.Key, err)
+	}
+}
+
+func replayIdempotentResponse(
+	c *gin.Context,
+	dB db.DB,
+	idempotencyKeyRepository repos.IdempotencyKeyRepository,
+	idempotencyKey *entities.IdempotencyKey,
+) {
+
+	existingKey, err := idempotencyKeyRepository.IdempotencyKeyByUserAndKey(
+		c.Request.Context(),
+		dB,
+		idempotencyKey.UserID,
+		idempotencyKey.Key,
+	)
+	if err != nil {
+		abortWithError(c, utils.NewError(
+			err,
+			"Failed to get idempotency key = [%v]",
+			idempotencyKey.Key,
+		))
+		return
+	}
+
+	if existingKey.RequestHash != idempotencyKey.RequestHash {
+		abortWithError(c, utils.NewErrorWithCode(
+			errors.New("idempotency key reused"),
+			utils.ErrorCodeIdempotencyKeyReused,
+			"idempotency key = [%v] was used for a different request",
+			idempotencyKey.Key,
+		))
+		return
+	}
+
+	if !existingKey.IsCompleted() {
+		abortWithError(c, utils.NewErrorWithCode(
+			errors.New("idempotency key in use"),
+			utils.ErrorCodeIdempotencyKeyInUse,
+			"idempotency key = [%v] is still being processed",
+			idempotencyKey.Key,
+		))
+		return
+	}
+
+	c.Header(idempotentReplayedHeaderKey, "true")
+	c.Data(int(existingKey.ResponseStatus.Int64), existingKey.ResponseContentType, existingKey.ResponseBody)
+	c.Abort()
+}
+
+// requestFingerprint hashes the request method, path and body. Multipart
+// uploads are fingerprinted with their length instead of their body, which
+// would otherwise be buffered in full before the handler gets to enforce its
+// size limit.
+func requestFingerprint(
+	c *gin.Context,
+) (string, error) {
+
+	var body []byte
+
+	if c.Request.Body != nil && c.ContentType() != gin.MIMEMultipartPOSTForm {
+
+		var err error
+
+		body, err = ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	hash.Write(body)
+
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		hash.Write([]byte(strconv.FormatInt(c.Request.ContentLength, 10)))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func abortWithError(
+	c *gin.Context,
+	wrappedError *utils.Error,
+) {
+	webutils.HandleError(c, wrappedError)
+	c.Abort()
+}
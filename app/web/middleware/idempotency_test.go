@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/utils"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+
+	testDB := db.InitDB()
+	defer testDB.Close()
+
+	ctx := context.Background()
+
+	idempotencyKeyRepository := repos.NewIdempotencyKeyRepository()
+
+	Convey("Idempotency Middleware", t, utils.WithTestDB(ctx, testDB, func(ctx context.Context, dB db.DB) {
+
+		calls := 0
+
+		testRouter := gin.Default()
+		testRouter.Use(Idempotency(dB, idempotencyKeyRepository, DefaultIdempotencyKeyTTL))
+		testRouter.POST("/companies", func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{"calls": calls})
+		})
+		testRouter.POST("/failures", func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusInternalServerError, gin.H{"calls": calls})
+		})
+		testRouter.POST("/documents", func(c *gin.Context) {
+			calls++
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"calls": calls})
+				return
+			}
+
+			c.JSON(http.StatusCreated, gin.H{"calls": calls, "size": fileHeader.Size})
+		})
+		testRouter.POST("/panics", func(c *gin.Context) {
+			calls++
+			panic("handler failed")
+		})
+
+		form := map[string]string{"name": "Microsoft"}
+		headers := map[string]string{idempotencyKeyHeaderKey: "create-microsoft"}
+
+		Convey("replays the original response for a repeated request", func() {
+
+			w, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/companies", form, "", headers)
+			So(err, ShouldBeNil)
+			So(w.Code, ShouldEqual, http.StatusCreated)
+
+			replayed, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/companies", form, "", headers)
+			So(err, ShouldBeNil)
+			So(replayed.Code, ShouldEqual, http.StatusCreated)
+			So(replayed.Body.String(), ShouldEqual, w.Body.String())
+			So(replayed.Header().Get(idempotentReplayedHeaderKey), ShouldEqual, "true")
+
+			So(calls, ShouldEqual, 1)
+		})
+
+		Convey("rejects a reused key with a different body", func() {
+
+			_, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/companies", form, "", headers)
+			So(err, ShouldBeNil)
+
+			w, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/companies", map[string]string{"name": "Apple"}, "", headers)
+			So(err, ShouldBeNil)
+			So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+
+			So(calls, ShouldEqual, 1)
+		})
+
+		Convey("does not remember server errors", func() {
+
+			_, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/failures", form, "", headers)
+			So(err, ShouldBeNil)
+
+			_, err = utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/failures", form, "", headers)
+			So(err, ShouldBeNil)
+
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("releases the key when the handler panics", func() {
+
+			w, err := utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/panics", form, "", headers)
+			So(err, ShouldBeNil)
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+			w, err = utils.DoRequestWithHeaders(testRouter, http.MethodPost, "/panics", form, "", headers)
+			So(err, ShouldBeNil)
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("leaves multipart uploads for the handler to read", func() {
+
+			upload := func(key string, contents []byte) *httptest.ResponseRecorder {
+
+				body := &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+
+				part, err := writer.CreateFormFile("file", "document.pdf")
+				So(err, ShouldBeNil)
+
+				_, err = part.Write(contents)
+				So(err, ShouldBeNil)
+				So(writer.Close(), ShouldBeNil)
+
+				req := httptest.NewRequest(http.MethodPost, "/documents", body)
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				req.Header.Set(idempotencyKeyHeaderKey, key)
+
+				w := httptest.NewRecorder()
+				testRouter.ServeHTTP(w, req)
+
+				return w
+			}
+
+			w := upload("upload-large-document", make([]byte, 2048))
+			So(w.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+
+			w = upload("upload-document", make([]byte, 512))
+			So(w.Code, ShouldEqual, http.StatusCreated)
+
+			replayed := upload("upload-document", make([]byte, 512))
+			So(replayed.Code, ShouldEqual, http.StatusCreated)
+			So(replayed.Header().Get(idempotentReplayedHeaderKey), ShouldEqual, "true")
+
+			So(calls, ShouldEqual, 2)
+
+			Convey("and rejects a reused key with a different file", func() {
+
+				w := upload("upload-document", make([]byte, 640))
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+
+				So(calls, ShouldEqual, 2)
+			})
+		})
+
+		Convey("ignores requests without a key", func() {
+
+			_, err := utils.DoRequest(testRouter, http.MethodPost, "/companies", form, "")
+			So(err, ShouldBeNil)
+
+			_, err = utils.DoRequest(testRouter, http.MethodPost, "/companies", form, "")
+			So(err, ShouldBeNil)
+
+			So(calls, ShouldEqual, 2)
+		})
+	}))
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"runtime/debug"
@@ -40,8 +39,8 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-ORGANONO-Token, X-SET-ORGANONO-ID, X-ORGANONO-ID")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-CSRF-Token, Authorization, X-Requested-With, X-ORGANONO-Token, X-SET-ORGANONO-ID, X-ORGANONO-ID")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-ORGANONO-Token, X-SET-ORGANONO-ID, X-ORGANONO-ID, If-Match, Idempotency-Key")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-CSRF-Token, Authorization, X-Requested-With, X-ORGANONO-Token, X-SET-ORGANONO-ID, X-ORGANONO-ID, ETag, Idempotent-Replayed")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == http.MethodOptions {
@@ -133,9 +132,11 @@ func setupAppContext(
 
 		ctx := c.Request.Context()
 
-		ipAddress, err := getIP(c.Request)
-		if err != nil {
-			logger.Warnf("Unable to parse ipAddress %v from remote address: %v", c.Request.RemoteAddr, err)
+		// X-Forwarded-For is only used when the request comes through a
+		// trusted proxy, see router.SetTrustedProxies.
+		ipAddress := c.ClientIP()
+		if ipAddress == "" {
+			logger.Warnf("Unable to parse ipAddress from remote address: %v", c.Request.RemoteAddr)
 		} else {
 			ctx = ctxhelper.WithIpAddress(ctx, ipAddress)
 		}
@@ -162,15 +163,3 @@ func setupAppContext(
 		c.Next()
 	}
 }
-
-func getIP(r *http.Request) (string, error) {
-	ips := r.Header.Get("X-FORWARDED-FOR")
-	forwarded := strings.Split(ips, ",")
-
-	if ips != "" && len(forwarded) > 0 {
-		return forwarded[0], nil
-	}
-
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	return host, err
-}
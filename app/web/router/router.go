@@ -3,14 +3,29 @@ package router
 import (
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/logger"
 	"github.com/vonmutinda/organono/app/providers"
 	"github.com/vonmutinda/organono/app/repos"
 	"github.com/vonmutinda/organono/app/services"
+	"github.com/vonmutinda/organono/app/web/api/addresses"
+	"github.com/vonmutinda/organono/app/web/api/audit"
 	"github.com/vonmutinda/organono/app/web/api/companies"
+	"github.com/vonmutinda/organono/app/web/api/contacts"
+	"github.com/vonmutinda/organono/app/web/api/countries"
+	"github.com/vonmutinda/organono/app/web/api/customfields"
+	"github.com/vonmutinda/organono/app/web/api/documents"
+	"github.com/vonmutinda/organono/app/web/api/duplicates"
+	"github.com/vonmutinda/organono/app/web/api/hierarchy"
+	"github.com/vonmutinda/organono/app/web/api/identifiers"
+	"github.com/vonmutinda/organono/app/web/api/lifecycle"
+	"github.com/vonmutinda/organono/app/web/api/metrics"
 	"github.com/vonmutinda/organono/app/web/api/sessions"
+	"github.com/vonmutinda/organono/app/web/api/tags"
+	"github.com/vonmutinda/organono/app/web/api/users"
 	"github.com/vonmutinda/organono/app/web/auth"
 	"github.com/vonmutinda/organono/app/web/middleware"
 )
@@ -29,28 +44,115 @@ func BuildRouter(
 
 	router := gin.Default()
 
+	err := router.SetTrustedProxies(trustedProxies())
+	if err != nil {
+		logger.Fatalf("invalid trusted proxies err = %v", err)
+	}
+
 	sessionRepository := repos.NewSessionRepository()
 	userRepository := repos.NewUserRepository()
-	sessionAuthenticator := auth.NewSessionAuthenticator(providers.NewIPAPI(), sessionRepository, userRepository)
+	sessionAuthenticator := auth.NewSessionAuthenticator(sessionRepository, userRepository)
 
 	defaultMiddlewares := middleware.DefaultMiddlewares(sessionAuthenticator)
 	router.Use(defaultMiddlewares...)
 
 	// Repositories
+	accessPolicyRuleRepository := repos.NewAccessPolicyRuleRepository()
+	auditLogRepository := repos.NewAuditLogRepository()
+	companyAddressRepository := repos.NewCompanyAddressRepository()
+	companyContactRepository := repos.NewCompanyContactRepository()
 	companyCountryRepository := repos.NewCompanyCountryRepository()
+	companyDocumentRepository := repos.NewCompanyDocumentRepository()
+	companyIdentifierRepository := repos.NewCompanyIdentifierRepository()
+	companyRedirectRepository := repos.NewCompanyRedirectRepository()
 	companyRepository := repos.NewCompanyRepository()
+	companyStatusTransitionRepository := repos.NewCompanyStatusTransitionRepository()
+	companyTagRepository := repos.NewCompanyTagRepository()
 	countryRepository := repos.NewCountryRepository()
+	customFieldDefinitionRepository := repos.NewCustomFieldDefinitionRepository()
+	idempotencyKeyRepository := repos.NewIdempotencyKeyRepository()
+	tagRepository := repos.NewTagRepository()
+
+	// Providers
+	blobStore := providers.NewBlobStore()
+	ipAPIProvider := providers.NewCachedIPAPI(dB, providers.NewIPAPI())
 
 	// Services
+	accessPolicyService := services.NewAccessPolicyService(accessPolicyRuleRepository, ipAPIProvider)
+	auditService := services.NewAuditService(auditLogRepository)
 	companyService := services.NewCompanyService(
+		auditLogRepository,
+		blobStore,
+		companyAddressRepository,
 		companyCountryRepository,
+		companyDocumentRepository,
+		companyRedirectRepository,
 		companyRepository,
+		companyStatusTransitionRepository,
+		companyTagRepository,
 		countryRepository,
+		customFieldDefinitionRepository,
+	)
+	companyAddressService := services.NewCompanyAddressService(
+		companyAddressRepository,
+		companyCountryRepository,
+		companyRepository,
+	)
+	companyContactService := services.NewCompanyContactService(
+		companyContactRepository,
+		companyRepository,
+	)
+	companyCountryService := services.NewCompanyCountryService(
+		companyCountryRepository,
+		companyRepository,
+		countryRepository,
+	)
+	companyDocumentService := services.NewCompanyDocumentService(
+		blobStore,
+		companyDocumentRepository,
+		companyRepository,
+	)
+	companyHierarchyService := services.NewCompanyHierarchyService(
+		auditLogRepository,
+		companyCountryRepository,
+		companyRepository,
+	)
+	companyIdentifierService := services.NewCompanyIdentifierService(
+		companyIdentifierRepository,
+		companyRepository,
+	)
+	companyMergeService := services.NewCompanyMergeService(
+		auditLogRepository,
+		companyAddressRepository,
+		companyContactRepository,
+		companyCountryRepository,
+		companyDocumentRepository,
+		companyIdentifierRepository,
+		companyRedirectRepository,
+		companyRepository,
+		companyStatusTransitionRepository,
+		companyTagRepository,
+	)
+	companyLifecycleService := services.NewCompanyLifecycleService(
+		auditLogRepository,
+		companyRepository,
+		companyStatusTransitionRepository,
+	)
+	customFieldService := services.NewCustomFieldService(
+		companyRepository,
+		customFieldDefinitionRepository,
 	)
 	sessionService := services.NewSessionService(sessionRepository, userRepository)
+	tagService := services.NewTagService(
+		companyRepository,
+		companyTagRepository,
+		tagRepository,
+	)
+	userService := services.NewUserService(sessionRepository, userRepository)
 
 	// router versions
 	appV1Router := router.Group("/v1")
+	appV1Router.Use(middleware.AccessPolicy(dB, accessPolicyService))
 
 	// Open endpoints
 	unauthenticatedUsers := appV1Router.Group("")
@@ -59,9 +161,23 @@ func BuildRouter(
 	// User endpoints
 	activeUsers := appV1Router.Group("")
 	activeUsers.Use(auth.AllowOnlyActiveUser(dB, sessionAuthenticator, sessionService))
+	activeUsers.Use(middleware.Idempotency(dB, idempotencyKeyRepository, middleware.DefaultIdempotencyKeyTTL))
 
 	sessions.AddEndpoints(activeUsers, dB, sessionService)
+	addresses.AddEndpoints(activeUsers, dB, companyAddressService)
+	audit.AddEndpoints(activeUsers, dB, auditService)
 	companies.AddEndpoints(activeUsers, dB, companyService)
+	contacts.AddEndpoints(activeUsers, dB, companyContactService)
+	countries.AddEndpoints(activeUsers, dB, companyCountryService)
+	customfields.AddEndpoints(activeUsers, dB, customFieldService)
+	documents.AddEndpoints(activeUsers, dB, companyDocumentService)
+	duplicates.AddEndpoints(activeUsers, dB, companyMergeService)
+	hierarchy.AddEndpoints(activeUsers, dB, companyHierarchyService)
+	identifiers.AddEndpoints(activeUsers, dB, companyIdentifierService)
+	lifecycle.AddEndpoints(activeUsers, dB, companyLifecycleService)
+	metrics.AddEndpoints(activeUsers, ipAPIProvider)
+	tags.AddEndpoints(activeUsers, dB, tagService)
+	users.AddEndpoints(activeUsers, dB, userService)
 
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error_message": "Endpoint not found"})
@@ -69,3 +185,19 @@ func BuildRouter(
 
 	return &AppRouter{router}
 }
+
+// trustedProxies reads the comma separated IP addresses or CIDR ranges of the
+// proxies allowed to set X-Forwarded-For from TRUSTED_PROXIES. Client IP
+// addresses are taken from the connection when no proxy is trusted.
+func trustedProxies() []string {
+
+	proxies := []string{}
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
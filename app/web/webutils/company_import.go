@@ -0,0 +1,245 @@
+package webutils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/entities"
+	"github.com/vonmutinda/organono/app/forms"
+	"github.com/vonmutinda/organono/app/utils"
+)
+
+const (
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+
+	maxCompanyImportBytes = 5 << 20
+)
+
+var companyImportColumns = map[string]bool{
+	"code":    true,
+	"country": true,
+	"name":    true,
+	"phone":   true,
+	"website": true,
+}
+
+var requiredCompanyImportColumns = []string{"name", "code", "country", "phone"}
+
+// CompanyImportFormFromContext parses a CSV or NDJSON request body into
+// company rows. The format is taken from the format query string and falls
+// back to the request Content-Type.
+func CompanyImportFormFromContext(
+	c *gin.Context,
+) (*forms.CompanyImportForm, error) {
+
+	dryRun, err := boolFromContext(c, "dry_run")
+	if err != nil {
+		return &forms.CompanyImportForm{}, err
+	}
+
+	mode := entities.CompanyImportModeAllOrNothing
+
+	modeQueryString := strings.TrimSpace(c.Query("mode"))
+	if modeQueryString != "" {
+		mode = entities.CompanyImportMode(modeQueryString)
+	}
+
+	if !mode.IsValid() {
+		return &forms.CompanyImportForm{}, utils.NewErrorWithCode(
+			errors.New("invalid import mode"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid mode query string = [%v]",
+			modeQueryString,
+		)
+	}
+
+	format, err := companyImportFormat(c)
+	if err != nil {
+		return &forms.CompanyImportForm{}, err
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCompanyImportBytes)
+
+	var companies []*forms.CreateCompanyForm
+
+	switch format {
+	case importFormatCSV:
+		companies, err = companiesFromCSV(body)
+	default:
+		companies, err = companiesFromNDJSON(body)
+	}
+	if err != nil {
+		return &forms.CompanyImportForm{}, err
+	}
+
+	form := &forms.CompanyImportForm{
+		Companies: companies,
+		DryRun:    dryRun,
+		Mode:      mode,
+	}
+
+	return form, nil
+}
+
+func companyImportFormat(
+	c *gin.Context,
+) (string, error) {
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+		switch mediaType {
+		case "text/csv", "application/csv":
+			format = importFormatCSV
+		case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/json":
+			format = importFormatNDJSON
+		}
+	}
+
+	if format != importFormatCSV && format != importFormatNDJSON {
+		return "", utils.NewErrorWithCode(
+			errors.New("unsupported import format"),
+			utils.ErrorCodeInvalidArgument,
+			"provided unsupported import format = [%v] content type = [%v]",
+			format,
+			c.GetHeader("Content-Type"),
+		)
+	}
+
+	return format, nil
+}
+
+func companiesFromCSV(
+	body io.Reader,
+) ([]*forms.CreateCompanyForm, error) {
+
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"failed to read csv header",
+		)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, column := range header {
+
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !companyImportColumns[column] {
+			return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+				errors.New("unknown csv column"),
+				utils.ErrorCodeInvalidArgument,
+				"unknown csv column = [%v]",
+				column,
+			)
+		}
+
+		columns[column] = i
+	}
+
+	for _, column := range requiredCompanyImportColumns {
+		if _, ok := columns[column]; !ok {
+			return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+				errors.New("missing csv column"),
+				utils.ErrorCodeInvalidArgument,
+				"missing csv column = [%v]",
+				column,
+			)
+		}
+	}
+
+	value := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	companies := make([]*forms.CreateCompanyForm, 0)
+
+	for {
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"failed to read csv row = %v",
+				len(companies)+1,
+			)
+		}
+
+		companies = append(companies, &forms.CreateCompanyForm{
+			Name:    value(record, "name"),
+			Code:    value(record, "code"),
+			Country: value(record, "country"),
+			Website: value(record, "website"),
+			Phone:   value(record, "phone"),
+		})
+	}
+
+	return companies, nil
+}
+
+func companiesFromNDJSON(
+	body io.Reader,
+) ([]*forms.CreateCompanyForm, error) {
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCompanyImportBytes)
+
+	companies := make([]*forms.CreateCompanyForm, 0)
+
+	for scanner.Scan() {
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var company forms.CreateCompanyForm
+
+		err := json.Unmarshal(line, &company)
+		if err != nil {
+			return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"failed to parse ndjson row = %v",
+				len(companies)+1,
+			)
+		}
+
+		companies = append(companies, &company)
+	}
+
+	if scanner.Err() != nil {
+		return []*forms.CreateCompanyForm{}, utils.NewErrorWithCode(
+			scanner.Err(),
+			utils.ErrorCodeInvalidArgument,
+			"failed to read ndjson body",
+		)
+	}
+
+	return companies, nil
+}
@@ -0,0 +1,56 @@
+package webutils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
+func SetETag(
+	c *gin.Context,
+	version int64,
+) {
+	c.Header(headerETag, fmt.Sprintf("%q", strconv.FormatInt(version, 10)))
+}
+
+// VersionFromIfMatch returns the resource version the client expects to
+// modify. A missing header or a wildcard skips the version check.
+func VersionFromIfMatch(
+	c *gin.Context,
+) (null.Int, error) {
+
+	ifMatch := strings.TrimSpace(c.GetHeader(headerIfMatch))
+	if ifMatch == "" || ifMatch == "*" {
+		return null.Int{}, nil
+	}
+
+	tag := strings.TrimPrefix(ifMatch, "W/")
+	tag = strings.Trim(tag, `"`)
+
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		if err == nil {
+			err = errors.New("version must be positive")
+		}
+
+		return null.Int{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid %v header = [%v]",
+			headerIfMatch,
+			ifMatch,
+		)
+	}
+
+	return null.IntFrom(version), nil
+}
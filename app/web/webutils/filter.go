@@ -1,12 +1,24 @@
 package webutils
 
 import (
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/vonmutinda/organono/app/entities"
 	"github.com/vonmutinda/organono/app/forms"
 	"github.com/vonmutinda/organono/app/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+
+	maxSearchPer         = 100
+	minSearchQueryLength = 2
 )
 
 func FilterFromContext(
@@ -18,16 +30,487 @@ func FilterFromContext(
 		return &forms.Filter{}, err
 	}
 
+	includeDeleted, err := boolFromContext(c, "include_deleted")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	skipCount, err := boolFromContext(c, "skip_count")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	createdAfter, err := timeFromContext(c, "created_after")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	createdBefore, err := timeFromContext(c, "created_before")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	updatedAfter, err := timeFromContext(c, "updated_after")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	phoneCountryCode, err := phoneCountryCodeFromContext(c)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	parentID, err := int64FromContext(c, "parent_id")
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	identifierType, identifier, err := identifierFromContext(c)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	tags, matchAllTags, err := tagsFromContext(c)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	customFields, err := customFieldsFromContext(c)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	sort, err := sortFromContext(c)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	status := strings.TrimSpace(c.Query("status"))
+	if status != "" && !entities.OperationStatusType(status).IsValid() {
+		return &forms.Filter{}, utils.NewErrorWithCode(
+			errors.New("invalid status"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid status query string = [%v]",
+			status,
+		)
+	}
+
 	filter := &forms.Filter{
+		Page:             page,
+		Per:              per,
+		Term:             strings.TrimSpace(c.Query("term")),
+		Status:           status,
+		IncludeDeleted:   includeDeleted,
+		Code:             strings.TrimSpace(c.Query("code")),
+		Country:          strings.TrimSpace(c.Query("country")),
+		CreatedAfter:     createdAfter,
+		CreatedBefore:    createdBefore,
+		UpdatedAfter:     updatedAfter,
+		PhoneCountryCode: phoneCountryCode,
+		ParentID:         parentID,
+		IdentifierType:   identifierType,
+		Identifier:       identifier,
+		Tags:             tags,
+		MatchAllTags:     matchAllTags,
+		CustomFields:     customFields,
+		Sort:             sort,
+		SkipCount:        skipCount,
+	}
+
+	err = cursorFromContext(c, filter)
+	if err != nil {
+		return &forms.Filter{}, err
+	}
+
+	return filter, nil
+}
+
+// cursorFromContext switches the filter to keyset pagination when either the
+// cursor or limit query string is provided. An empty cursor starts from the
+// first page.
+func cursorFromContext(
+	c *gin.Context,
+	filter *forms.Filter,
+) error {
+
+	cursorQueryString, hasCursor := c.GetQuery("cursor")
+	limitQueryString, hasLimit := c.GetQuery("limit")
+
+	if !hasCursor && !hasLimit {
+		return nil
+	}
+
+	filter.Cursor = true
+	filter.Page = 0
+	filter.Per = defaultCursorLimit
+
+	limitQueryString = strings.TrimSpace(limitQueryString)
+	if limitQueryString != "" {
+		limit, err := strconv.Atoi(limitQueryString)
+		if err != nil || limit < 1 || limit > maxCursorLimit {
+			if err == nil {
+				err = errors.New("limit out of range")
+			}
+
+			return utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"provided invalid limit query string = [%v], must be between 1 and %v",
+				limitQueryString,
+				maxCursorLimit,
+			)
+		}
+
+		filter.Per = limit
+	}
+
+	cursorQueryString = strings.TrimSpace(cursorQueryString)
+	if cursorQueryString != "" {
+		after, err := entities.DecodeCompanyCursor(cursorQueryString)
+		if err != nil {
+			return utils.NewErrorWithCode(
+				err,
+				utils.ErrorCodeInvalidArgument,
+				"provided invalid cursor query string = [%v]",
+				cursorQueryString,
+			)
+		}
+
+		if after.Sort != filter.SortKey() || len(after.Values) != len(filter.SortFields()) {
+			return utils.NewErrorWithCode(
+				errors.New("cursor sort mismatch"),
+				utils.ErrorCodeInvalidArgument,
+				"cursor was issued for sort = [%v], not [%v]",
+				after.Sort,
+				filter.SortKey(),
+			)
+		}
+
+		filter.After = after
+	}
+
+	return nil
+}
+
+func sortFromContext(
+	c *gin.Context,
+) ([]forms.SortField, error) {
+
+	queryString := strings.TrimSpace(c.Query("sort"))
+	if queryString == "" {
+		return []forms.SortField{}, nil
+	}
+
+	sort := make([]forms.SortField, 0)
+	seen := make(map[string]bool)
+
+	for _, key := range strings.Split(queryString, ",") {
+
+		key = strings.TrimSpace(key)
+
+		sortField := forms.SortField{
+			Field:      strings.TrimPrefix(key, "-"),
+			Descending: strings.HasPrefix(key, "-"),
+		}
+
+		if !forms.CompanySortFields[sortField.Field] || seen[sortField.Field] {
+			return []forms.SortField{}, utils.NewErrorWithCode(
+				errors.New("invalid sort field"),
+				utils.ErrorCodeInvalidArgument,
+				"provided unknown or repeated sort field = [%v]",
+				key,
+			)
+		}
+
+		seen[sortField.Field] = true
+		sort = append(sort, sortField)
+	}
+
+	return sort, nil
+}
+
+func phoneCountryCodeFromContext(
+	c *gin.Context,
+) (string, error) {
+
+	queryString := strings.TrimSpace(c.Query("phone_country_code"))
+	if queryString == "" {
+		return "", nil
+	}
+
+	digits := strings.TrimPrefix(queryString, "+")
+
+	_, err := strconv.ParseUint(digits, 10, 16)
+	if err != nil || len(digits) > 3 {
+		if err == nil {
+			err = errors.New("country code too long")
+		}
+
+		return "", utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid phone_country_code query string = [%v]",
+			queryString,
+		)
+	}
+
+	return "+" + digits, nil
+}
+
+// identifierFromContext parses the identifier query string, e.g.
+// LEI:5493001KJTIIGC8Y1R12 or VAT:CY10259033P, normalizing the value the same
+// way identifiers are stored. Registration numbers match in any country.
+func identifierFromContext(
+	c *gin.Context,
+) (entities.IdentifierType, string, error) {
+
+	queryString := strings.TrimSpace(c.Query("identifier"))
+	if queryString == "" {
+		return "", "", nil
+	}
+
+	parts := strings.SplitN(queryString, ":", 2)
+	identifierType := entities.IdentifierType(strings.ToLower(strings.TrimSpace(parts[0])))
+
+	if len(parts) != 2 || !identifierType.IsValid() {
+		return "", "", utils.NewErrorWithCode(
+			errors.New("invalid identifier"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid identifier query string = [%v]",
+			queryString,
+		)
+	}
+
+	if identifierType == entities.IdentifierTypeRegistration {
+		return identifierType, strings.ToUpper(strings.Join(strings.Fields(parts[1]), " ")), nil
+	}
+
+	identifier, _, err := utils.NormalizeCompanyIdentifier(identifierType, "", parts[1])
+	if err != nil {
+		return "", "", err
+	}
+
+	return identifierType, identifier, nil
+}
+
+// tagsFromContext parses a comma separated tags query string. Companies must
+// carry all the tags unless tag_match=any is given.
+func tagsFromContext(
+	c *gin.Context,
+) ([]string, bool, error) {
+
+	queryString := strings.TrimSpace(c.Query("tags"))
+	if queryString == "" {
+		return []string{}, false, nil
+	}
+
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, tag := range strings.Split(queryString, ",") {
+
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	tagMatch := strings.ToLower(strings.TrimSpace(c.Query("tag_match")))
+
+	switch tagMatch {
+	case "", "all":
+		return tags, true, nil
+	case "any":
+		return tags, false, nil
+	}
+
+	return []string{}, false, utils.NewErrorWithCode(
+		errors.New("invalid tag match"),
+		utils.ErrorCodeInvalidArgument,
+		"provided invalid tag_match query string = [%v]",
+		tagMatch,
+	)
+}
+
+// customFieldsFromContext parses custom field filters given as
+// custom_fields[key]=value.
+func customFieldsFromContext(
+	c *gin.Context,
+) (map[string]string, error) {
+
+	customFields := make(map[string]string)
+
+	for key, value := range c.QueryMap("custom_fields") {
+
+		if !utils.IsValidCustomFieldKey(key) {
+			return map[string]string{}, utils.NewErrorWithCode(
+				errors.New("invalid custom field key"),
+				utils.ErrorCodeInvalidArgument,
+				"provided invalid custom field key = [%v]",
+				key,
+			)
+		}
+
+		customFields[key] = strings.TrimSpace(value)
+	}
+
+	return customFields, nil
+}
+
+func SearchFilterFromContext(
+	c *gin.Context,
+) (*forms.SearchFilter, error) {
+
+	page, per, err := paginationFromContext(c)
+	if err != nil {
+		return &forms.SearchFilter{}, err
+	}
+
+	if page < 1 || per < 1 || per > maxSearchPer {
+		return &forms.SearchFilter{}, utils.NewErrorWithCode(
+			errors.New("pagination out of range"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid page = %v per = %v, per must be between 1 and %v",
+			page,
+			per,
+			maxSearchPer,
+		)
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < minSearchQueryLength {
+		return &forms.SearchFilter{}, utils.NewErrorWithCode(
+			errors.New("search query too short"),
+			utils.ErrorCodeInvalidArgument,
+			"provided q query string = [%v] shorter than %v characters",
+			query,
+			minSearchQueryLength,
+		)
+	}
+
+	filter := &forms.SearchFilter{
+		Page:  page,
+		Per:   per,
+		Query: query,
+	}
+
+	return filter, nil
+}
+
+func AuditFilterFromContext(
+	c *gin.Context,
+) (*forms.AuditFilter, error) {
+
+	page, per, err := paginationFromContext(c)
+	if err != nil {
+		return &forms.AuditFilter{}, err
+	}
+
+	actorID, err := int64FromContext(c, "actor_id")
+	if err != nil {
+		return &forms.AuditFilter{}, err
+	}
+
+	from, err := timeFromContext(c, "from")
+	if err != nil {
+		return &forms.AuditFilter{}, err
+	}
+
+	to, err := timeFromContext(c, "to")
+	if err != nil {
+		return &forms.AuditFilter{}, err
+	}
+
+	action := strings.TrimSpace(c.Query("action"))
+	if action != "" && !entities.AuditAction(action).IsValid() {
+		return &forms.AuditFilter{}, utils.NewErrorWithCode(
+			errors.New("invalid audit action"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid action query string = [%v]",
+			action,
+		)
+	}
+
+	filter := &forms.AuditFilter{
+		Page:         page,
+		Per:          per,
+		Action:       action,
+		ActorID:      actorID,
+		ResourceType: strings.TrimSpace(c.Query("resource_type")),
+		From:         from,
+		To:           to,
+	}
+
+	return filter, nil
+}
+
+func UserFilterFromContext(
+	c *gin.Context,
+) (*forms.UserFilter, error) {
+
+	page, per, err := paginationFromContext(c)
+	if err != nil {
+		return &forms.UserFilter{}, err
+	}
+
+	role := entities.Role(strings.TrimSpace(c.Query("role")))
+	if role != "" && !role.IsValid() {
+		return &forms.UserFilter{}, utils.NewErrorWithCode(
+			errors.New("invalid user role"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid role query string = [%v]",
+			role,
+		)
+	}
+
+	status := entities.UserStatus(strings.TrimSpace(c.Query("status")))
+	if status != "" && !status.IsValid() {
+		return &forms.UserFilter{}, utils.NewErrorWithCode(
+			errors.New("invalid user status"),
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid status query string = [%v]",
+			status,
+		)
+	}
+
+	filter := &forms.UserFilter{
 		Page:   page,
 		Per:    per,
+		Role:   role,
 		Term:   strings.TrimSpace(c.Query("term")),
-		Status: strings.TrimSpace(c.Query("status")),
+		Status: status,
 	}
 
 	return filter, nil
 }
 
+func boolFromContext(
+	c *gin.Context,
+	key string,
+) (bool, error) {
+
+	queryString := strings.TrimSpace(c.Query(key))
+	if queryString == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(queryString)
+	if err != nil {
+		return false, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid %v query string = [%v]",
+			key,
+			queryString,
+		)
+	}
+
+	return value, nil
+}
+
 func paginationFromContext(
 	c *gin.Context,
 ) (int, int, error) {
@@ -65,3 +548,51 @@ func paginationFromContext(
 
 	return page, per, nil
 }
+
+func int64FromContext(
+	c *gin.Context,
+	key string,
+) (int64, error) {
+
+	queryString := strings.TrimSpace(c.Query(key))
+	if queryString == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseInt(queryString, 10, 64)
+	if err != nil {
+		return 0, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid %v query string = [%v]",
+			key,
+			queryString,
+		)
+	}
+
+	return value, nil
+}
+
+func timeFromContext(
+	c *gin.Context,
+	key string,
+) (null.Time, error) {
+
+	queryString := strings.TrimSpace(c.Query(key))
+	if queryString == "" {
+		return null.Time{}, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, queryString)
+	if err != nil {
+		return null.Time{}, utils.NewErrorWithCode(
+			err,
+			utils.ErrorCodeInvalidArgument,
+			"provided invalid %v query string = [%v]",
+			key,
+			queryString,
+		)
+	}
+
+	return null.TimeFrom(value), nil
+}
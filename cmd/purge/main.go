@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/services"
+)
+
+const defaultRetention = 30 * 24 * time.Hour
+
+func main() {
+
+	logger.Initialize()
+	defer logger.Flush()
+
+	var envFilePath string
+	var retention time.Duration
+
+	flag.StringVar(&envFilePath, "e", "", "Path to .env file")
+	flag.DurationVar(&retention, "retention", defaultRetention, "Purge companies soft deleted longer than this ago")
+	flag.Parse()
+
+	if envFilePath != "" {
+		err := godotenv.Load(envFilePath)
+		if err != nil {
+			logger.Fatalf("Failed to load env file err = %v", err)
+		}
+	}
+
+	dB := db.InitDB()
+	defer dB.Close()
+
+	companyService := services.NewCompanyService(
+		repos.NewAuditLogRepository(),
+		providers.NewBlobStore(),
+		repos.NewCompanyAddressRepository(),
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyDocumentRepository(),
+		repos.NewCompanyRedirectRepository(),
+		repos.NewCompanyRepository(),
+		repos.NewCompanyStatusTransitionRepository(),
+		repos.NewCompanyTagRepository(),
+		repos.NewCountryRepository(),
+		repos.NewCustomFieldDefinitionRepository(),
+	)
+
+	purged, err := companyService.PurgeDeletedCompanies(context.Background(), dB, retention)
+	if err != nil {
+		logger.Fatalf("Failed to purge deleted companies err = %v", err)
+	}
+
+	logger.Infof("Purged %v companies deleted more than %v ago", purged, retention)
+
+	expired, err := repos.NewIdempotencyKeyRepository().DeleteExpiredIdempotencyKeys(context.Background(), dB, time.Now())
+	if err != nil {
+		logger.Fatalf("Failed to purge expired idempotency keys err = %v", err)
+	}
+
+	logger.Infof("Purged %v expired idempotency keys", expired)
+
+	expired, err = repos.NewIPCountryRepository().DeleteExpiredIPCountries(context.Background(), dB, time.Now())
+	if err != nil {
+		logger.Fatalf("Failed to purge expired ip countries err = %v", err)
+	}
+
+	logger.Infof("Purged %v expired ip countries", expired)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/joho/godotenv"
+	"github.com/vonmutinda/organono/app/db"
+	"github.com/vonmutinda/organono/app/logger"
+	"github.com/vonmutinda/organono/app/providers"
+	"github.com/vonmutinda/organono/app/repos"
+	"github.com/vonmutinda/organono/app/services"
+)
+
+func main() {
+
+	logger.Initialize()
+	defer logger.Flush()
+
+	var envFilePath string
+
+	flag.StringVar(&envFilePath, "e", "", "Path to .env file")
+	flag.Parse()
+
+	if envFilePath != "" {
+		err := godotenv.Load(envFilePath)
+		if err != nil {
+			logger.Fatalf("Failed to load env file err = %v", err)
+		}
+	}
+
+	dB := db.InitDB()
+	defer dB.Close()
+
+	companyService := services.NewCompanyService(
+		repos.NewAuditLogRepository(),
+		providers.NewBlobStore(),
+		repos.NewCompanyAddressRepository(),
+		repos.NewCompanyCountryRepository(),
+		repos.NewCompanyDocumentRepository(),
+		repos.NewCompanyRedirectRepository(),
+		repos.NewCompanyRepository(),
+		repos.NewCompanyStatusTransitionRepository(),
+		repos.NewCompanyTagRepository(),
+		repos.NewCountryRepository(),
+		repos.NewCustomFieldDefinitionRepository(),
+	)
+
+	backfilled, conflicts, err := companyService.BackfillWebsiteDomains(context.Background(), dB)
+	if err != nil {
+		logger.Fatalf("Failed to backfill website domains err = %v", err)
+	}
+
+	logger.Infof("Backfilled %v website domains, recorded %v conflicts", backfilled, conflicts)
+}